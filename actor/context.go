@@ -17,6 +17,10 @@ type Context struct {
 	engine   *Engine
 	receiver Receiver
 	message  any
+	header   Header
+	deadline time.Time
+	// send is the sender middleware chain the outgoing messages go through.
+	send SenderFunc
 	// function to get the current number of messages in the inbox
 	getInboxCount func() int
 	// the context of the parent if we are a child.
@@ -33,6 +37,7 @@ func newContext(ctx context.Context, e *Engine, pid *PID) *Context {
 		engine:   e,
 		pid:      pid,
 		children: safemap.New[string, *PID](),
+		send:     sendEnvelope,
 		getInboxCount: func() int {
 			return -1
 		},
@@ -45,15 +50,35 @@ func (c *Context) Context() context.Context {
 	return c.context
 }
 
+// SetContext replaces the context.Context returned by Context. Middleware can
+// use this to attach values, such as a tracing span, to the message that is
+// being received. The caller is responsible for restoring the previous
+// context.Context once the message is processed.
+func (c *Context) SetContext(ctx context.Context) {
+	c.context = ctx
+}
+
 // Receiver returns the underlying receiver of this Context.
 func (c *Context) Receiver() Receiver {
 	return c.receiver
 }
 
-// See Engine.Request for information. This is just a helper function doing that
-// calls Request on the underlying Engine. c.Engine().Request().
+// See Engine.Request for information. Unlike c.Engine().Request(), the request
+// goes through the sender middleware of the current process.
+//
+// If the current message is a request itself, the deadline of the new request
+// will not exceed the deadline of the current message, and neither does the
+// time we wait for the response.
 func (c *Context) Request(pid *PID, msg any, timeout time.Duration) *Response {
-	return c.engine.Request(pid, msg, timeout)
+	deadline := time.Now().Add(timeout)
+	if !c.deadline.IsZero() && c.deadline.Before(deadline) {
+		deadline = c.deadline
+		timeout = min(timeout, time.Until(deadline))
+	}
+	resp := NewResponse(c.engine, timeout)
+	c.engine.Registry.registerResponse(resp)
+	c.send(c, pid, Envelope{Msg: msg, Sender: resp.PID(), Deadline: deadline})
+	return resp
 }
 
 // Respond will sent the given message to the sender of the current received message.
@@ -62,7 +87,7 @@ func (c *Context) Respond(msg any) {
 		slog.Warn("context got no sender", "func", "Respond", "pid", c.PID())
 		return
 	}
-	c.engine.Send(c.sender, msg)
+	c.send(c, c.sender, Envelope{Msg: msg})
 }
 
 // SpawnChild will spawn the given Producer as a child of the current Context.
@@ -99,7 +124,7 @@ func (c *Context) SpawnChildFunc(f func(*Context), name string, opts ...OptFunc)
 // of the message can call Context.Sender() to know
 // the PID of the process that sent this message.
 func (c *Context) Send(pid *PID, msg any) {
-	c.engine.SendWithSender(pid, msg, c.pid)
+	c.send(c, pid, Envelope{Msg: msg, Sender: c.pid})
 }
 
 // SendRepeat will send the given message to the given PID each given interval.
@@ -117,10 +142,16 @@ func (c *Context) SendRepeat(pid *PID, msg any, interval time.Duration) SendRepe
 	return sr
 }
 
-// Forward will forward the current received message to the given PID.
-// This will also set the "forwarder" as the sender of the message.
+// SendWithHeaders behaves like Send, but attaches the given headers to the
+// message. The receiver can read them with Context.Header.
+func (c *Context) SendWithHeaders(pid *PID, msg any, headers Header) {
+	c.send(c, pid, Envelope{Msg: msg, Sender: c.pid, Header: headers.copy()})
+}
+
+// Forward will forward the current received message, including its header and
+// deadline, to the given PID. This will also set the "forwarder" as the sender of the message.
 func (c *Context) Forward(pid *PID) {
-	c.engine.SendWithSender(pid, c.message, c.pid)
+	c.send(c, pid, Envelope{Msg: c.message, Sender: c.pid, Header: c.header.copy(), Deadline: c.deadline})
 }
 
 // GetPID returns the PID of the process found by the given id.
@@ -174,6 +205,44 @@ func (c *Context) Message() any {
 	return c.message
 }
 
+// Envelope returns the envelope of the message that is currently being
+// received, with its sender, header and deadline.
+func (c *Context) Envelope() Envelope {
+	return Envelope{
+		Msg:      c.message,
+		Sender:   c.sender,
+		Header:   c.header.copy(),
+		Deadline: c.deadline,
+	}
+}
+
+// Replay passes the given envelope to receive as the message that is
+// currently being received, and restores the current message afterwards.
+// Middleware that holds back messages uses it to process them later, before
+// the messages that are still in the inbox.
+func (c *Context) Replay(env Envelope, receive ReceiveFunc) {
+	msg, sender, header, deadline := c.message, c.sender, c.header, c.deadline
+	c.message, c.sender, c.header, c.deadline = env.Msg, env.Sender, env.Header, env.Deadline
+	defer func() {
+		c.message, c.sender, c.header, c.deadline = msg, sender, header, deadline
+	}()
+	receive(c)
+}
+
+// Deadline returns the time after which the sender of the current message
+// is no longer waiting for a response. ok is false when the message is not
+// a request and has no deadline.
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	return c.deadline, !c.deadline.IsZero()
+}
+
+// Header returns the value of the given key in the header of the message
+// that is currently being received. An empty string is returned if the key
+// is not set.
+func (c *Context) Header(key string) string {
+	return c.header.Get(key)
+}
+
 // GetInboxCount returns the number of messages in the inbox of the current process.
 func (c *Context) GetInboxCount() int {
 	return c.getInboxCount()
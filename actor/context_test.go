@@ -170,3 +170,129 @@ func TestSpawnChild(t *testing.T) {
 	assert.Nil(t, e.Registry.get(NewPID("local", "child")))
 	assert.Nil(t, e.Registry.get(pid))
 }
+
+func TestSenderMiddlewareHeader(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	receiver := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			assert.Equal(t, "1", c.Header("id"))
+			wg.Done()
+		}
+	}, "receiver")
+	// forwarding should keep the header of the original message.
+	forwarder := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			assert.Equal(t, "1", c.Header("id"))
+			c.Forward(receiver)
+		}
+	}, "forwarder")
+	setHeader := func(next SenderFunc) SenderFunc {
+		return func(c *Context, pid *PID, env Envelope) {
+			env.Header = Header{"id": "1"}
+			next(c, pid, env)
+		}
+	}
+	e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(Started); ok {
+			c.Send(forwarder, "foo")
+			c.Send(receiver, "bar")
+		}
+	}, "sender", WithSenderMiddleware(setHeader))
+	wg.Wait()
+}
+
+func TestSendWithHeaders(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	receiver := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case string:
+			assert.Equal(t, "tenant-1", c.Header("tenant"))
+			assert.Equal(t, "", c.Header("missing"))
+			wg.Done()
+		case int:
+			assert.Equal(t, "", c.Header("tenant"))
+			wg.Done()
+		}
+	}, "receiver")
+	e.SendWithHeaders(receiver, "foo", Header{"tenant": "tenant-1"})
+	e.Send(receiver, 1)
+	wg.Wait()
+}
+
+func TestSendWithHeadersCopiesHeader(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	received := make(chan string, 2)
+	block := make(chan struct{})
+	receiver := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			<-block
+			received <- c.Header("id")
+		}
+	}, "receiver")
+
+	// Changing the header after sending doesn't change the sent message.
+	header := Header{"id": "1"}
+	e.SendWithHeaders(receiver, "foo", header)
+	header["id"] = "2"
+	close(block)
+	assert.Equal(t, "1", <-received)
+}
+
+func TestSendEnvelope(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	headers := make(chan string, 1)
+	receiver := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			headers <- c.Header("id")
+			_, ok := c.Deadline()
+			c.Respond(ok)
+		}
+	}, "receiver")
+	// the router passes the envelope on as it was sent to it.
+	router := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			c.Engine().SendEnvelope(receiver, c.Envelope())
+		}
+	}, "router")
+
+	e.SendWithHeaders(router, "foo", Header{"id": "1"})
+	assert.Equal(t, "1", <-headers)
+	resp, err := e.Request(router, "foo", time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, true, resp)
+	assert.Equal(t, "", <-headers)
+}
+
+func TestContextReplay(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	type result struct {
+		msg    any
+		header string
+	}
+	results := make(chan result, 3)
+	receive := func(c *Context) {
+		results <- result{msg: c.Message(), header: c.Header("id")}
+	}
+	pid := e.SpawnFunc(func(c *Context) {
+		if msg, ok := c.Message().(string); ok && msg == "replay" {
+			c.Replay(Envelope{Msg: "held", Header: Header{"id": "1"}}, receive)
+			// the current message is restored.
+			receive(c)
+		}
+	}, "replayer")
+
+	e.SendWithHeaders(pid, "replay", Header{"id": "2"})
+	assert.Equal(t, result{msg: "held", header: "1"}, <-results)
+	assert.Equal(t, result{msg: "replay", header: "2"}, <-results)
+}
@@ -32,11 +32,15 @@ type Engine struct {
 	address     string
 	remote      Remoter
 	eventStream *PID
+	// metrics is nil when instrumentation is disabled, so the hot path only
+	// pays for a nil check.
+	metrics Metrics
 }
 
 // EngineConfig holds the configuration of the engine.
 type EngineConfig struct {
-	remote Remoter
+	remote  Remoter
+	metrics Metrics
 }
 
 // NewEngineConfig returns a new default EngineConfig.
@@ -51,9 +55,20 @@ func (config EngineConfig) WithRemote(remote Remoter) EngineConfig {
 	return config
 }
 
+// WithMetrics sets the Metrics the engine reports message, mailbox, lifecycle
+// and remote instrumentation to.
+//
+// Defaults to no instrumentation.
+func (config EngineConfig) WithMetrics(m Metrics) EngineConfig {
+	config.metrics = m
+	return config
+}
+
 // NewEngine returns a new actor Engine given an EngineConfig.
 func NewEngine(config EngineConfig) (*Engine, error) {
-	e := &Engine{}
+	e := &Engine{
+		metrics: config.metrics,
+	}
 	e.Registry = newRegistry(e) // need to init the registry in case we want a custom deadletter
 	e.address = LocalLookupAddr
 	if config.remote != nil {
@@ -97,6 +112,63 @@ func (e *Engine) SpawnProc(p Processer) *PID {
 	return p.PID()
 }
 
+// Metrics returns the Metrics the engine reports to. NoopMetrics is returned
+// if the engine was configured without metrics.
+func (e *Engine) Metrics() Metrics {
+	if e.metrics == nil {
+		return NoopMetrics{}
+	}
+	return e.metrics
+}
+
+// EngineStats is a snapshot of the load of an engine.
+type EngineStats struct {
+	// The number of processes registered on the engine.
+	Actors int
+	// The number of messages waiting in the inboxes of the processes.
+	Mailbox int
+}
+
+// Stats returns the number of actors the engine hosts and the number of
+// messages that are waiting to be processed by them.
+func (e *Engine) Stats() EngineStats {
+	var (
+		snap  = e.Registry.lookup.Load()
+		stats = EngineStats{Actors: len(*snap)}
+	)
+	for _, proc := range *snap {
+		if counter, ok := proc.(interface{ Count() int }); ok {
+			stats.Mailbox += counter.Count()
+		}
+	}
+	return stats
+}
+
+// ProcessInfo is a snapshot of the state of a single process.
+type ProcessInfo struct {
+	// The number of messages waiting in the inbox of the process.
+	Mailbox int
+	// The number of times the process was restarted after a panic.
+	Restarts int
+}
+
+// ProcessInfo returns the state of the local process with the given PID.
+// False is returned if the process doesn't exist on the engine.
+func (e *Engine) ProcessInfo(pid *PID) (ProcessInfo, bool) {
+	proc := e.Registry.get(pid)
+	if proc == nil {
+		return ProcessInfo{}, false
+	}
+	var info ProcessInfo
+	if counter, ok := proc.(interface{ Count() int }); ok {
+		info.Mailbox = counter.Count()
+	}
+	if p, ok := proc.(*process); ok {
+		info.Restarts = int(p.restarts.Load())
+	}
+	return info, true
+}
+
 // Address returns the address of the actor engine. When there is
 // no remote configured, the "local" address will be used, otherwise
 // the listen address of the remote.
@@ -111,7 +183,11 @@ func (e *Engine) Request(pid *PID, msg any, timeout time.Duration) *Response {
 	resp := NewResponse(e, timeout)
 	e.Registry.registerResponse(resp)
 
-	e.SendWithSender(pid, msg, resp.PID())
+	e.sendEnvelope(pid, Envelope{
+		Msg:      msg,
+		Sender:   resp.PID(),
+		Deadline: time.Now().Add(timeout),
+	})
 
 	return resp
 }
@@ -130,6 +206,21 @@ func (e *Engine) Send(pid *PID, msg any) {
 	e.send(pid, msg, nil)
 }
 
+// SendWithHeaders sends the given message to the given PID with the given
+// headers attached. Headers are carried transparently to local and remote
+// receivers, which can read them by calling Context.Header().
+func (e *Engine) SendWithHeaders(pid *PID, msg any, headers Header) {
+	e.sendEnvelope(pid, Envelope{Msg: msg, Header: headers.copy()})
+}
+
+// SendEnvelope sends the given envelope to the given PID, its sender, header
+// and deadline included. Actors that route messages to other actors use it
+// together with Context.Envelope, so the message arrives as it was sent.
+func (e *Engine) SendEnvelope(pid *PID, env Envelope) {
+	env.Header = env.Header.copy()
+	e.sendEnvelope(pid, env)
+}
+
 // BroadcastEvent will broadcast the given message over the eventstream, notifying all
 // actors that are subscribed.
 func (e *Engine) BroadcastEvent(msg any) {
@@ -139,6 +230,10 @@ func (e *Engine) BroadcastEvent(msg any) {
 }
 
 func (e *Engine) send(pid *PID, msg any, sender *PID) {
+	e.sendEnvelope(pid, Envelope{Msg: msg, Sender: sender})
+}
+
+func (e *Engine) sendEnvelope(pid *PID, env Envelope) {
 	// TODO: We might want to log something here. Not yet decided
 	// what could make sense. Send to dead letter or as event?
 	// Dead letter would make sense cause the destination is not
@@ -146,15 +241,22 @@ func (e *Engine) send(pid *PID, msg any, sender *PID) {
 	if pid == nil {
 		return
 	}
+	if e.metrics != nil {
+		e.metrics.MessageSent(KindOf(pid))
+	}
 	if e.isLocalMessage(pid) {
-		e.SendLocal(pid, msg, sender)
+		e.SendLocalEnvelope(pid, env)
 		return
 	}
 	if e.remote == nil {
-		e.BroadcastEvent(EngineRemoteMissingEvent{Target: pid, Sender: sender, Message: msg})
+		e.BroadcastEvent(EngineRemoteMissingEvent{Target: pid, Sender: env.Sender, Message: env.Msg})
+		return
+	}
+	if r, ok := e.remote.(EnvelopeSender); ok {
+		r.SendEnvelope(pid, env)
 		return
 	}
-	e.remote.Send(pid, msg, sender)
+	e.remote.Send(pid, env.Msg, env.Sender)
 }
 
 // SendRepeater is a struct that can be used to send a repeating message to a given PID.
@@ -235,6 +337,9 @@ func (e *Engine) sendPoisonPill(ctx context.Context, graceful bool, pid *PID) co
 	}
 	// deadletter - if we didn't find a process, we will broadcast a DeadletterEvent
 	if e.Registry.get(pid) == nil {
+		if e.metrics != nil {
+			e.metrics.DeadLetter(KindOf(pid))
+		}
 		e.BroadcastEvent(DeadLetterEvent{
 			Target:  pid,
 			Message: pill,
@@ -251,17 +356,30 @@ func (e *Engine) sendPoisonPill(ctx context.Context, graceful bool, pid *PID) co
 // registry, the message will be sent to the DeadLetter process instead. If there is no deadletter
 // process registered, the function will panic.
 func (e *Engine) SendLocal(pid *PID, msg any, sender *PID) {
+	e.SendLocalEnvelope(pid, Envelope{Msg: msg, Sender: sender})
+}
+
+// SendLocalEnvelope behaves like SendLocal, but also delivers the header of the
+// given Envelope if the recipient implements EnvelopeSender.
+func (e *Engine) SendLocalEnvelope(pid *PID, env Envelope) {
 	proc := e.Registry.get(pid)
 	if proc == nil {
+		if e.metrics != nil {
+			e.metrics.DeadLetter(KindOf(pid))
+		}
 		// broadcast a deadLetter message
 		e.BroadcastEvent(DeadLetterEvent{
 			Target:  pid,
-			Message: msg,
-			Sender:  sender,
+			Message: env.Msg,
+			Sender:  env.Sender,
 		})
 		return
 	}
-	proc.Send(pid, msg, sender)
+	if p, ok := proc.(EnvelopeSender); ok {
+		p.SendEnvelope(pid, env)
+		return
+	}
+	proc.Send(pid, env.Msg, env.Sender)
 }
 
 // Subscribe will subscribe the given PID to the event stream.
@@ -65,6 +65,65 @@ func TestRegistryGetPID(t *testing.T) {
 	assert.True(t, pid.Equals(expectedPID2))
 }
 
+func TestEngineStats(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	base := e.Stats()
+
+	var (
+		block   = make(chan struct{})
+		blocked = make(chan struct{})
+	)
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			select {
+			case blocked <- struct{}{}:
+			default:
+			}
+			<-block
+		}
+	}, "foo")
+	e.SpawnFunc(func(c *Context) {}, "bar")
+
+	e.Send(pid, "block")
+	<-blocked
+	for range 5 {
+		e.Send(pid, "queued")
+	}
+	stats := e.Stats()
+	assert.Equal(t, base.Actors+2, stats.Actors)
+	assert.GreaterOrEqual(t, stats.Mailbox, base.Mailbox+5)
+
+	close(block)
+	assert.Eventually(t, func() bool {
+		return e.Stats().Mailbox == base.Mailbox
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestEngineProcessInfo(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	pid := e.SpawnFunc(func(c *Context) {
+		if msg, ok := c.Message().(string); ok && msg == "crash" {
+			panic("crash")
+		}
+	}, "foo", WithRestartDelay(time.Millisecond))
+
+	info, ok := e.ProcessInfo(pid)
+	require.True(t, ok)
+	assert.Equal(t, 0, info.Restarts)
+
+	e.Send(pid, "crash")
+	e.Send(pid, "crash")
+	assert.Eventually(t, func() bool {
+		info, _ := e.ProcessInfo(pid)
+		return info.Restarts == 2 && info.Mailbox == 0
+	}, time.Second, time.Millisecond*10)
+
+	_, ok = e.ProcessInfo(NewPID(e.Address(), "unknown"))
+	assert.False(t, ok)
+}
+
 func TestSendToNilPID(t *testing.T) {
 	e, _ := NewEngine(NewEngineConfig())
 	e.Send(nil, "foo")
@@ -392,6 +451,130 @@ func TestRequestResponse(t *testing.T) {
 	})
 }
 
+func TestRequestDeadline(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	type result struct {
+		deadline time.Time
+		ok       bool
+	}
+	// The actors report the deadlines they see, the test checks them.
+	var (
+		innerResults = make(chan result, 1)
+		outerResults = make(chan result, 2)
+		errs         = make(chan error, 1)
+	)
+	inner := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			deadline, ok := c.Deadline()
+			innerResults <- result{deadline, ok}
+			c.Respond(deadline)
+		}
+	}, "inner")
+	outer := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case string:
+			deadline, ok := c.Deadline()
+			outerResults <- result{deadline, ok}
+			// the nested request can't outlive the request we are handling.
+			resp, err := c.Request(inner, "foo", time.Hour).Result()
+			errs <- err
+			c.Respond(resp)
+		case int:
+			deadline, ok := c.Deadline()
+			outerResults <- result{deadline, ok}
+		}
+	}, "outer")
+
+	before := time.Now()
+	resp, err := e.Request(outer, "foo", time.Second).Result()
+	require.NoError(t, err)
+	require.NoError(t, <-errs)
+	outerResult, innerResult := <-outerResults, <-innerResults
+	require.True(t, outerResult.ok)
+	require.True(t, innerResult.ok)
+	assert.Equal(t, outerResult.deadline, innerResult.deadline)
+	assert.Equal(t, outerResult.deadline, resp)
+	assert.True(t, outerResult.deadline.After(before))
+	assert.True(t, outerResult.deadline.Before(before.Add(time.Second*2)))
+
+	// plain sends have no deadline.
+	e.Send(outer, 1)
+	select {
+	case result := <-outerResults:
+		assert.False(t, result.ok)
+	case <-time.After(time.Second):
+		t.Fatal("send was not received")
+	}
+}
+
+func TestRequestTimeoutWithinDeadline(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	// inner never responds.
+	inner := e.SpawnFunc(func(*Context) {}, "inner")
+	waited := make(chan time.Duration, 1)
+	outer := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			start := time.Now()
+			_, _ = c.Request(inner, "foo", time.Hour).Result()
+			waited <- time.Since(start)
+		}
+	}, "outer")
+
+	_, err = e.Request(outer, "foo", time.Millisecond*100).Result()
+	assert.Error(t, err)
+	select {
+	case d := <-waited:
+		// the nested request gives up with the request we are handling.
+		assert.Less(t, d, time.Second)
+	case <-time.After(time.Second * 2):
+		t.Fatal("nested request outlived the deadline")
+	}
+}
+
+func TestDropExpired(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		handled []string
+	)
+	wg.Add(1)
+	eventPID := e.SpawnFunc(func(c *Context) {
+		if msg, ok := c.Message().(ExpiredRequestsDroppedEvent); ok {
+			assert.Equal(t, 1, msg.Dropped)
+			wg.Done()
+		}
+	}, "event")
+	e.Subscribe(eventPID)
+
+	block := make(chan struct{})
+	pid := e.SpawnFunc(func(c *Context) {
+		if msg, ok := c.Message().(string); ok {
+			if msg == "block" {
+				<-block
+			}
+			mu.Lock()
+			handled = append(handled, msg)
+			mu.Unlock()
+		}
+	}, "worker", WithDropExpired())
+
+	e.Send(pid, "block")
+	_, err = e.Request(pid, "expired", time.Millisecond).Result()
+	assert.Error(t, err)
+	e.Send(pid, "fire-and-forget")
+	close(block)
+	wg.Wait()
+	<-e.Poison(pid).Done()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"block", "fire-and-forget"}, handled)
+}
+
 func TestPoisonPillPrivate(t *testing.T) {
 	e, err := NewEngine(NewEngineConfig())
 	require.NoError(t, err)
@@ -102,6 +102,19 @@ type RemoteUnreachableEvent struct {
 	ListenAddr string
 }
 
+// ExpiredRequestsDroppedEvent is broadcasted when an actor spawned with
+// WithDropExpired dropped requests whose deadline passed before they could
+// be processed.
+type ExpiredRequestsDroppedEvent struct {
+	PID       *PID
+	Dropped   int
+	Timestamp time.Time
+}
+
+func (e ExpiredRequestsDroppedEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelWarn, "Dropped expired requests", []any{"pid", e.PID.GetID(), "dropped", e.Dropped}
+}
+
 // DeadLetterEvent is delivered to the deadletter actor when a message can't be delivered to it's recipient
 type DeadLetterEvent struct {
 	Target  *PID
@@ -0,0 +1,58 @@
+package actor
+
+import (
+	"strings"
+	"time"
+)
+
+// Metrics is the interface the engine reports its instrumentation to. All
+// kind labels are derived from the first segment of the PID ID, so the
+// children of an actor are reported under the kind of their root.
+//
+// Implementations must be safe for concurrent use. See the metrics package for
+// a Prometheus implementation.
+type Metrics interface {
+	// MessageSent is called for every message sent to a process of the given kind.
+	MessageSent(kind string)
+	// MessageProcessed is called after a process of the given kind processed a
+	// message, with the time it took to process it.
+	MessageProcessed(kind string, latency time.Duration)
+	// MailboxDepth is called each time a process of the given kind picks up a
+	// batch of messages, with the number of messages that were waiting.
+	MailboxDepth(kind string, depth int)
+	// ActorSpawned is called when a process of the given kind is registered.
+	ActorSpawned(kind string)
+	// ActorStopped is called when a process of the given kind is removed.
+	ActorStopped(kind string)
+	// ActorRestarted is called when a process of the given kind restarts.
+	ActorRestarted(kind string)
+	// DeadLetter is called when a message for the given kind could not be delivered.
+	DeadLetter(kind string)
+	// RemoteBatchSent is called when a batch is written to the given peer.
+	RemoteBatchSent(peer string, messages, bytes int)
+	// RemoteBatchReceived is called when a batch is read from the network.
+	RemoteBatchReceived(messages, bytes int)
+}
+
+// NoopMetrics is a Metrics implementation that discards everything.
+type NoopMetrics struct{}
+
+func (NoopMetrics) MessageSent(string)                     {}
+func (NoopMetrics) MessageProcessed(string, time.Duration) {}
+func (NoopMetrics) MailboxDepth(string, int)               {}
+func (NoopMetrics) ActorSpawned(string)                    {}
+func (NoopMetrics) ActorStopped(string)                    {}
+func (NoopMetrics) ActorRestarted(string)                  {}
+func (NoopMetrics) DeadLetter(string)                      {}
+func (NoopMetrics) RemoteBatchSent(string, int, int)       {}
+func (NoopMetrics) RemoteBatchReceived(int, int)           {}
+
+// KindOf returns the kind label of the given PID, which is the part of its
+// ID before the first separator.
+func KindOf(pid *PID) string {
+	if pid == nil {
+		return ""
+	}
+	kind, _, _ := strings.Cut(pid.ID, pidSeparator)
+	return kind
+}
@@ -16,6 +16,15 @@ type ReceiveFunc = func(*Context)
 
 type MiddlewareFunc = func(ReceiveFunc) ReceiveFunc
 
+// SenderFunc delivers the given Envelope to the given PID on behalf of the
+// Context it is called with.
+type SenderFunc = func(*Context, *PID, Envelope)
+
+// SenderMiddlewareFunc wraps the outgoing messages of an actor. It is invoked
+// for every message sent through Context.Send, Request, Forward and Respond,
+// which makes it the place to add headers to outgoing messages.
+type SenderMiddlewareFunc = func(SenderFunc) SenderFunc
+
 type Opts struct {
 	Producer     Producer
 	Kind         string
@@ -24,7 +33,11 @@ type Opts struct {
 	RestartDelay time.Duration
 	InboxSize    int
 	Middleware   []MiddlewareFunc
-	Context      context.Context
+	// SenderMiddleware wraps the messages the actor sends through its Context.
+	SenderMiddleware []SenderMiddlewareFunc
+	// DropExpired drops requests whose deadline passed before they reach Receive.
+	DropExpired bool
+	Context     context.Context
 }
 
 type OptFunc func(*Opts)
@@ -53,6 +66,23 @@ func WithMiddleware(mw ...MiddlewareFunc) OptFunc {
 	}
 }
 
+// WithSenderMiddleware adds middleware that is invoked for every message
+// the actor sends through its Context.
+func WithSenderMiddleware(mw ...SenderMiddlewareFunc) OptFunc {
+	return func(opts *Opts) {
+		opts.SenderMiddleware = append(opts.SenderMiddleware, mw...)
+	}
+}
+
+// WithDropExpired drops requests whose caller stopped waiting for a response
+// before they reach the Receive of the actor. An ExpiredRequestsDroppedEvent
+// is broadcasted with the number of dropped requests.
+func WithDropExpired() OptFunc {
+	return func(opts *Opts) {
+		opts.DropExpired = true
+	}
+}
+
 func WithRestartDelay(d time.Duration) OptFunc {
 	return func(opts *Opts) {
 		opts.RestartDelay = d
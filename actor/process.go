@@ -15,6 +15,49 @@ import (
 type Envelope struct {
 	Msg    any
 	Sender *PID
+	// Header holds the optional metadata of the message. It is nil when
+	// the message was sent without any.
+	Header Header
+	// Deadline is the time after which the sender of a request is no longer
+	// waiting for a response. It is zero for messages that are not requests.
+	Deadline time.Time
+}
+
+// Header holds metadata, such as correlation IDs, tenant IDs or trace
+// context, that travels along with a message, locally as well as over the
+// network.
+type Header map[string]string
+
+// Get returns the value of the given key, or an empty string if the key is
+// not set. Get is safe to call on a nil Header.
+func (h Header) Get(key string) string {
+	return h[key]
+}
+
+// Clone returns a copy of the Header that can be modified without
+// affecting the original.
+func (h Header) Clone() Header {
+	clone := make(Header, len(h))
+	for k, v := range h {
+		clone[k] = v
+	}
+	return clone
+}
+
+// copy returns a copy of the Header, or nil if it's nil. Headers are copied
+// when a message is sent, so the sender and the receivers never share them.
+func (h Header) copy() Header {
+	if h == nil {
+		return nil
+	}
+	return h.Clone()
+}
+
+// EnvelopeSender is an optional interface that Processers and Remoters can
+// implement to receive the full Envelope, including its Header. Processers
+// that don't implement it will only receive the message and its sender.
+type EnvelopeSender interface {
+	SendEnvelope(*PID, Envelope)
 }
 
 // Processer is an interface the abstracts the way a process behaves.
@@ -32,9 +75,11 @@ type process struct {
 	inbox    Inboxer
 	context  *Context
 	pid      *PID
-	restarts int32
+	restarts atomic.Int32
 	mbuffer  []Envelope
 	mcount   int32
+	// number of expired requests dropped in the current batch.
+	expired int
 	// receive is the fully-composed receiver (base Receiver wrapped by the
 	// configured Middleware chain). It is resolved ONCE during Start instead
 	// of on every single message, avoiding per-message closure allocations.
@@ -52,6 +97,7 @@ func newProcess(e *Engine, opts Opts) *process {
 		mbuffer: nil,
 	}
 	ctx.getInboxCount = p.Count
+	ctx.send = applySenderMiddleware(sendEnvelope, opts.SenderMiddleware...)
 	return p
 }
 
@@ -62,6 +108,18 @@ func applyMiddleware(rcv ReceiveFunc, middleware ...MiddlewareFunc) ReceiveFunc
 	return rcv
 }
 
+func applySenderMiddleware(send SenderFunc, middleware ...SenderMiddlewareFunc) SenderFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		send = middleware[i](send)
+	}
+	return send
+}
+
+// sendEnvelope is the SenderFunc at the end of each sender middleware chain.
+func sendEnvelope(c *Context, pid *PID, env Envelope) {
+	c.engine.sendEnvelope(pid, env)
+}
+
 func (p *process) Invoke(msgs []Envelope) {
 	var (
 		// numbers of msgs that need to be processed.
@@ -74,6 +132,9 @@ func (p *process) Invoke(msgs []Envelope) {
 		processed = 0
 	)
 	atomic.StoreInt32(&p.mcount, int32(nmsg))
+	if m := p.context.engine.metrics; m != nil {
+		m.MailboxDepth(KindOf(p.pid), nmsg+p.inbox.Count())
+	}
 	defer func() {
 		// If we recovered, we buffer up all the messages that we could not process
 		// so we can retry them on the next restart.
@@ -100,12 +161,28 @@ func (p *process) Invoke(msgs []Envelope) {
 					p.invokeMsg(m)
 				}
 			}
+			p.reportExpired()
 			p.cleanup(pill.cancel)
 			return
 		}
 		p.invokeMsg(msg)
 		processed++
 	}
+	p.reportExpired()
+}
+
+// reportExpired broadcasts the number of expired requests that were dropped
+// while processing the current batch.
+func (p *process) reportExpired() {
+	if p.expired == 0 {
+		return
+	}
+	p.context.engine.BroadcastEvent(ExpiredRequestsDroppedEvent{
+		PID:       p.pid,
+		Dropped:   p.expired,
+		Timestamp: time.Now(),
+	})
+	p.expired = 0
 }
 
 func (p *process) invokeMsg(msg Envelope) {
@@ -113,11 +190,32 @@ func (p *process) invokeMsg(msg Envelope) {
 	if _, ok := msg.Msg.(poisonPill); ok {
 		return
 	}
+	if p.DropExpired && !msg.Deadline.IsZero() && time.Now().After(msg.Deadline) {
+		p.expired++
+		return
+	}
 	p.context.message = msg.Msg
 	p.context.sender = msg.Sender
+	p.context.header = msg.Header
+	p.context.deadline = msg.Deadline
+	if m := p.context.engine.metrics; m != nil {
+		start := time.Now()
+		p.receive(p.context)
+		m.MessageProcessed(KindOf(p.pid), time.Since(start))
+		return
+	}
 	p.receive(p.context)
 }
 
+// setLifecycleMessage sets the given lifecycle message as the current message
+// of the context. The header and deadline of the last received message don't
+// belong to it.
+func (p *process) setLifecycleMessage(msg any) {
+	p.context.message = msg
+	p.context.header = nil
+	p.context.deadline = time.Time{}
+}
+
 func (p *process) Start() {
 	recv := p.Producer()
 	p.context.receiver = recv
@@ -127,11 +225,11 @@ func (p *process) Start() {
 			p.tryRestart(v)
 		}
 	}()
-	p.context.message = Initialized{}
+	p.setLifecycleMessage(Initialized{})
 	p.receive(p.context)
 	p.context.engine.BroadcastEvent(ActorInitializedEvent{PID: p.pid, Timestamp: time.Now()})
 
-	p.context.message = Started{}
+	p.setLifecycleMessage(Started{})
 	p.receive(p.context)
 	p.context.engine.BroadcastEvent(ActorStartedEvent{PID: p.pid, Timestamp: time.Now()})
 	// If we have messages in our buffer, invoke them.
@@ -158,7 +256,7 @@ func (p *process) tryRestart(v any) {
 	stackTrace := cleanTrace(debug.Stack())
 	// If we reach the max restarts, we shutdown the inbox and clean
 	// everything up.
-	if p.restarts == p.MaxRestarts {
+	if p.restarts.Load() == p.MaxRestarts {
 		p.context.engine.BroadcastEvent(ActorMaxRestartsExceededEvent{
 			PID:       p.pid,
 			Timestamp: time.Now(),
@@ -167,17 +265,20 @@ func (p *process) tryRestart(v any) {
 		return
 	}
 
-	p.context.message = Stopped{}
+	p.setLifecycleMessage(Stopped{})
 	p.receive(p.context)
 
-	p.restarts++
+	restarts := p.restarts.Add(1)
+	if m := p.context.engine.metrics; m != nil {
+		m.ActorRestarted(KindOf(p.pid))
+	}
 	// Restart the process after its restartDelay
 	p.context.engine.BroadcastEvent(ActorRestartedEvent{
 		PID:        p.pid,
 		Timestamp:  time.Now(),
 		Stacktrace: stackTrace,
 		Reason:     v,
-		Restarts:   p.restarts,
+		Restarts:   restarts,
 	})
 	time.Sleep(p.Opts.RestartDelay)
 	p.Start()
@@ -201,7 +302,7 @@ func (p *process) cleanup(cancel context.CancelFunc) {
 
 	p.inbox.Stop()
 	p.context.engine.Registry.Remove(p.pid)
-	p.context.message = Stopped{}
+	p.setLifecycleMessage(Stopped{})
 	p.receive(p.context)
 
 	p.context.engine.BroadcastEvent(ActorStoppedEvent{PID: p.pid, Timestamp: time.Now()})
@@ -211,6 +312,9 @@ func (p *process) PID() *PID { return p.pid }
 func (p *process) Send(_ *PID, msg any, sender *PID) {
 	p.inbox.Send(Envelope{Msg: msg, Sender: sender})
 }
+func (p *process) SendEnvelope(_ *PID, env Envelope) {
+	p.inbox.Send(env)
+}
 func (p *process) Shutdown() {
 	p.cleanup(nil)
 }
@@ -117,3 +117,26 @@ func Test_StartupMessages(t *testing.T) {
 		return
 	}
 }
+
+func TestLifecycleMessagesHaveNoHeader(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	headers := make(chan string, 10)
+	pid := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case Initialized, Started, Stopped:
+			headers <- c.Header("trace")
+		case string:
+			panic("crash")
+		}
+	}, "foo", WithRestartDelay(time.Millisecond))
+	// Initialized and Started of the first start.
+	<-headers
+	<-headers
+
+	e.SendWithHeaders(pid, "crash", Header{"trace": "1"})
+	// Stopped, Initialized and Started of the restart.
+	for range 3 {
+		require.Empty(t, <-headers)
+	}
+}
@@ -57,6 +57,9 @@ func (r *Registry) Remove(pid *PID) {
 			return
 		}
 	}
+	if _, ok := snap[pid.ID]; !ok {
+		return
+	}
 	next := make(registrySnapshot, len(snap))
 	for k, v := range snap {
 		if k != pid.ID {
@@ -64,6 +67,9 @@ func (r *Registry) Remove(pid *PID) {
 		}
 	}
 	r.lookup.Store(&next)
+	if m := r.engine.metrics; m != nil {
+		m.ActorStopped(KindOf(pid))
+	}
 }
 
 // get returns the processer for the given PID, if it exists.
@@ -110,6 +116,9 @@ func (r *Registry) add(proc Processer) {
 	clone[id] = proc
 	r.lookup.Store(&clone)
 	r.mu.Unlock()
+	if m := r.engine.metrics; m != nil {
+		m.ActorSpawned(KindOf(proc.PID()))
+	}
 	proc.Start()
 }
 
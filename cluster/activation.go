@@ -4,6 +4,9 @@ import (
 	fmt "fmt"
 	"math"
 	"math/rand"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/zeebo/xxh3"
 )
 
 // ActivationConfig...
@@ -11,6 +14,10 @@ type ActivationConfig struct {
 	id           string
 	region       string
 	selectMember SelectMemberFunc
+	// the state handed off by the previous activation of a member that left.
+	handoff []byte
+	// the activation that is migrated, which the new activation replaces.
+	migrate *actor.PID
 }
 
 // NewActivationConfig returns a new default config.
@@ -18,13 +25,15 @@ func NewActivationConfig() ActivationConfig {
 	return ActivationConfig{
 		id:           fmt.Sprintf("%d", rand.Intn(math.MaxInt)),
 		region:       "default",
-		selectMember: SelectRandomMember,
+		selectMember: SelectRendezvousMember,
 	}
 }
 
 // WithSelectMemberFunc set's the fuction that will be invoked during
 // the activation process.
 // It will select the member where the actor will be activated/spawned on.
+//
+// Defaults to SelectRendezvousMember.
 func (config ActivationConfig) WithSelectMemberFunc(fun SelectMemberFunc) ActivationConfig {
 	config.selectMember = fun
 	return config
@@ -46,6 +55,20 @@ func (config ActivationConfig) WithRegion(region string) ActivationConfig {
 	return config
 }
 
+// The reasons an ActivationResponse can be unsuccessful with.
+const (
+	ReasonTopologyMismatch       = "topology mismatch"
+	ReasonKindNotRegistered      = "kind not registered"
+	ReasonRegionNotAllowed       = "region not allowed"
+	ReasonMaxActivationsReached  = "max activations reached"
+	ReasonSingletonAlreadyActive = "singleton already active"
+	ReasonMemberLeaving          = "member leaving"
+	ReasonAlreadyActive          = "already active"
+	// ReasonRedirectStopping is returned while the old activation of a
+	// migrated identity is stopping, the activation is retried.
+	ReasonRedirectStopping = "redirect stopping"
+)
+
 // SelectMemberFunc will be invoked during the activation process.
 // Given the ActivationDetails the actor will be spawned on the returned member.
 type SelectMemberFunc func(ActivationDetails) *Member
@@ -59,9 +82,52 @@ type ActivationDetails struct {
 	Members []*Member
 	// The kind of the actor
 	Kind string
+	// The id of the actor
+	ID string
+	// The number of activations hosted by each of the members, by their ID
+	Activations map[string]int
+	// The latest load stats the members published, by their ID
+	Stats map[string]*MemberStats
 }
 
 // SelectRandomMember selects a random member of the cluster.
 func SelectRandomMember(details ActivationDetails) *Member {
 	return details.Members[rand.Intn(len(details.Members))]
 }
+
+// SelectOldestMember selects the member that was started first. Unlike the
+// other placements, the selected member only changes when it leaves the
+// cluster, which makes it a good fit for singletons.
+func SelectOldestMember(details ActivationDetails) *Member {
+	var selected *Member
+	for _, member := range details.Members {
+		if selected == nil ||
+			member.Started < selected.Started ||
+			(member.Started == selected.Started && member.ID < selected.ID) {
+			selected = member
+		}
+	}
+	return selected
+}
+
+// SelectRendezvousMember selects a member by rendezvous (highest random weight)
+// hashing of the kind and id of the actor. As long as the members don't change,
+// the same identity always lands on the same member, and when a member leaves
+// only the identities that were placed on that member move.
+func SelectRendezvousMember(details ActivationDetails) *Member {
+	var (
+		identity = details.Kind + "/" + details.ID
+		selected *Member
+		max      uint64
+	)
+	for _, member := range details.Members {
+		weight := xxh3.HashString(member.ID + "/" + identity)
+		// ties are practically impossible, but break them by ID so that every
+		// member comes to the same conclusion.
+		if selected == nil || weight > max || (weight == max && member.ID < selected.ID) {
+			selected = member
+			max = weight
+		}
+	}
+	return selected
+}
@@ -0,0 +1,227 @@
+package cluster
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// adminEvent carries an event of the event stream to the admin actor.
+type adminEvent struct {
+	event any
+}
+
+// AdminConfig holds the configuration of the admin actor.
+type AdminConfig struct {
+	send bool
+}
+
+// NewAdminConfig returns an AdminConfig that is initialized with default
+// values.
+func NewAdminConfig() AdminConfig {
+	return AdminConfig{}
+}
+
+// WithSend set's whether the admin actor serves AdminSendRequest, which sends
+// a message to any actor of the engine, or of the engines it can reach, on
+// behalf of the client.
+//
+// Defaults to false.
+func (config AdminConfig) WithSend(send bool) AdminConfig {
+	config.send = send
+	return config
+}
+
+// AdminPID returns the PID of the admin actor of the engine listening on the
+// given address. The admin actor serves the admin protocol, the Admin*
+// messages, to tools like cmd/goactors:
+//
+//	resp, err := e.Request(cluster.AdminPID("127.0.0.1:3000"), &cluster.AdminMembersRequest{}, time.Second).Result()
+//
+// The admin actor is opt-in, a cluster spawns it when it's configured with
+// Config.WithAdmin and other engines with SpawnAdmin. The admin protocol is
+// not authenticated, every peer that can reach the remote of the engine can
+// use it, so it should only be served on trusted networks.
+func AdminPID(addr string) *actor.PID {
+	return actor.NewPID(addr, "admin/engine")
+}
+
+// SpawnAdmin spawns the admin actor on an engine that is not a member of a
+// cluster. The requests for the members and activations of a cluster respond
+// with an error.
+func SpawnAdmin(e *actor.Engine, config AdminConfig) *actor.PID {
+	return e.Spawn(newAdmin(e, nil, config), "admin", actor.WithID("engine"))
+}
+
+// admin serves the admin protocol of an engine. It forwards the events of the
+// event stream to its subscribers, and drops the subscribers that became
+// unreachable.
+type admin struct {
+	engine      *actor.Engine
+	cluster     *Cluster
+	config      AdminConfig
+	subscribers []*actor.PID
+	eventSubPID *actor.PID
+}
+
+func newAdmin(e *actor.Engine, c *Cluster, config AdminConfig) actor.Producer {
+	return func() actor.Receiver {
+		return &admin{
+			engine:  e,
+			cluster: c,
+			config:  config,
+		}
+	}
+}
+
+func (a *admin) Receive(c *actor.Context) {
+	switch msg := c.Message().(type) {
+	case actor.Started:
+		a.eventSubPID = c.SpawnChildFunc(func(ctx *actor.Context) {
+			switch ctx.Message().(type) {
+			case actor.Initialized, actor.Started, actor.Stopped:
+			default:
+				ctx.Send(ctx.Parent(), adminEvent{event: ctx.Message()})
+			}
+		}, "event")
+	case actor.Stopped:
+		a.engine.Unsubscribe(a.eventSubPID)
+	case *AdminMembersRequest:
+		c.Respond(a.members())
+	case *AdminActivationsRequest:
+		c.Respond(a.activations(msg.Kind))
+	case *AdminActorRequest:
+		resp := &AdminActorResponse{}
+		if info, ok := a.engine.ProcessInfo(msg.Pid); ok {
+			resp.Found = true
+			resp.Mailbox = int64(info.Mailbox)
+			resp.Restarts = int64(info.Restarts)
+		}
+		c.Respond(resp)
+	case *AdminSendRequest:
+		resp := &AdminSendResponse{}
+		if err := a.send(msg); err != nil {
+			resp.Error = err.Error()
+		}
+		c.Respond(resp)
+	case *AdminSubscribe:
+		if len(a.subscribers) == 0 {
+			a.engine.Subscribe(a.eventSubPID)
+		}
+		a.subscribers = append(a.subscribers, msg.Subscriber)
+	case *AdminUnsubscribe:
+		a.unsubscribe(func(pid *actor.PID) bool { return pid.Equals(msg.Subscriber) })
+		if c.Sender() != nil {
+			c.Respond(&AdminUnsubscribeResponse{})
+		}
+	case adminEvent:
+		a.handleEvent(c, msg.event)
+	}
+}
+
+func (a *admin) members() *AdminMembersResponse {
+	if a.cluster == nil {
+		return &AdminMembersResponse{Error: "engine is not a cluster member"}
+	}
+	return &AdminMembersResponse{Members: a.cluster.Members()}
+}
+
+// activations returns the activations of the given kind, or of all the kinds
+// the members host if kind is empty.
+func (a *admin) activations(kind string) *AdminActivationsResponse {
+	if a.cluster == nil {
+		return &AdminActivationsResponse{Error: "engine is not a cluster member"}
+	}
+	kinds := []string{kind}
+	if len(kind) == 0 {
+		kinds = kinds[:0]
+		for _, member := range a.cluster.Members() {
+			for _, kind := range member.Kinds {
+				if !slices.Contains(kinds, kind) {
+					kinds = append(kinds, kind)
+				}
+			}
+		}
+		slices.Sort(kinds)
+	}
+	resp := &AdminActivationsResponse{}
+	for _, kind := range kinds {
+		for _, pid := range a.cluster.GetActiveByKind(kind) {
+			if pid != nil {
+				resp.Pids = append(resp.Pids, pid)
+			}
+		}
+	}
+	return resp
+}
+
+// send decodes the message of the request from the proto JSON format and
+// sends it to the PID of the request.
+func (a *admin) send(req *AdminSendRequest) error {
+	if !a.config.send {
+		return fmt.Errorf("sending messages is disabled")
+	}
+	if req.Pid == nil {
+		return fmt.Errorf("pid is required")
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(req.Type))
+	if err != nil {
+		return fmt.Errorf("type %q: %w", req.Type, err)
+	}
+	msg := mt.New().Interface()
+	if len(req.Payload) > 0 {
+		if err := protojson.Unmarshal([]byte(req.Payload), msg); err != nil {
+			return fmt.Errorf("payload: %w", err)
+		}
+	}
+	a.engine.Send(req.Pid, msg)
+	return nil
+}
+
+func (a *admin) handleEvent(c *actor.Context, event any) {
+	// Subscribers that can't be reached anymore would keep the remote
+	// dialing them, drop them before the event is forwarded.
+	if msg, ok := event.(actor.RemoteUnreachableEvent); ok {
+		a.unsubscribe(func(pid *actor.PID) bool { return pid.Address == msg.ListenAddr })
+	}
+	msg := &AdminEvent{
+		Type:      fmt.Sprintf("%T", event),
+		Event:     formatEvent(event),
+		Timestamp: time.Now().UnixNano(),
+	}
+	for _, pid := range a.subscribers {
+		c.Send(pid, msg)
+	}
+}
+
+func (a *admin) unsubscribe(match func(*actor.PID) bool) {
+	if len(a.subscribers) == 0 {
+		return
+	}
+	a.subscribers = slices.DeleteFunc(a.subscribers, match)
+	if len(a.subscribers) == 0 {
+		a.engine.Unsubscribe(a.eventSubPID)
+	}
+}
+
+// formatEvent formats the event the way it's logged if it implements
+// actor.EventLogger.
+func formatEvent(event any) string {
+	logger, ok := event.(actor.EventLogger)
+	if !ok {
+		return fmt.Sprintf("%+v", event)
+	}
+	_, msg, attrs := logger.Log()
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", attrs[i], attrs[i+1])
+	}
+	return b.String()
+}
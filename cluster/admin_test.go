@@ -0,0 +1,140 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/khulnasoft/goactors/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeAdminClient returns an engine that talks to the admin actor as a remote
+// peer, like cmd/goactors does.
+func makeAdminClient(t *testing.T) *actor.Engine {
+	r := remote.New(getRandomLocalhostAddr(), remote.NewConfig())
+	e, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(r))
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Stop().Wait() })
+	return e
+}
+
+func adminRequest[T any](t *testing.T, e *actor.Engine, addr string, msg any) T {
+	resp, err := e.Request(AdminPID(addr), msg, time.Second).Result()
+	require.NoError(t, err)
+	result, ok := resp.(T)
+	require.True(t, ok, "unexpected response %T", resp)
+	return result
+}
+
+func TestAdmin(t *testing.T) {
+	addr := getRandomLocalhostAddr()
+	c, err := New(NewConfig().
+		WithID("A").
+		WithListenAddr(addr).
+		WithAdmin(NewAdminConfig().WithSend(true)))
+	require.NoError(t, err)
+	c.RegisterKind("counter", newCounter, NewKindConfig())
+	c.Start()
+	defer c.Stop()
+	client := makeAdminClient(t)
+
+	members := adminRequest[*AdminMembersResponse](t, client, addr, &AdminMembersRequest{})
+	require.Empty(t, members.Error)
+	require.Len(t, members.Members, 1)
+	assert.Equal(t, "A", members.Members[0].ID)
+	assert.Equal(t, []string{"counter"}, members.Members[0].Kinds)
+
+	pid := c.Activate("counter", NewActivationConfig().WithID("1"))
+	require.NotNil(t, pid)
+	for _, kind := range []string{"counter", ""} {
+		actives := adminRequest[*AdminActivationsResponse](t, client, addr, &AdminActivationsRequest{Kind: kind})
+		assert.Equal(t, fmt.Sprint([]*actor.PID{pid}), fmt.Sprint(actives.Pids))
+	}
+	actives := adminRequest[*AdminActivationsResponse](t, client, addr, &AdminActivationsRequest{Kind: "unknown"})
+	assert.Empty(t, actives.Pids)
+
+	sent := adminRequest[*AdminSendResponse](t, client, addr, &AdminSendRequest{
+		Pid:     pid,
+		Type:    "remote.TestMessage",
+		Payload: `{"data": "aGk="}`,
+	})
+	require.Empty(t, sent.Error)
+	assert.Equal(t, 2, requestCount(t, c, pid))
+
+	sent = adminRequest[*AdminSendResponse](t, client, addr, &AdminSendRequest{Pid: pid, Type: "unknown.Type"})
+	assert.Contains(t, sent.Error, "unknown.Type")
+	sent = adminRequest[*AdminSendResponse](t, client, addr, &AdminSendRequest{Pid: pid, Type: "remote.TestMessage", Payload: "{"})
+	assert.Contains(t, sent.Error, "payload")
+
+	info := adminRequest[*AdminActorResponse](t, client, addr, &AdminActorRequest{Pid: pid})
+	assert.True(t, info.Found)
+	assert.Equal(t, int64(0), info.Restarts)
+	info = adminRequest[*AdminActorResponse](t, client, addr, &AdminActorRequest{Pid: actor.NewPID(addr, "unknown")})
+	assert.False(t, info.Found)
+}
+
+func TestAdminEvents(t *testing.T) {
+	r := remote.New(getRandomLocalhostAddr(), remote.NewConfig())
+	e, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(r))
+	require.NoError(t, err)
+	defer func() { r.Stop().Wait() }()
+	SpawnAdmin(e, NewAdminConfig())
+	client := makeAdminClient(t)
+
+	members := adminRequest[*AdminMembersResponse](t, client, e.Address(), &AdminMembersRequest{})
+	assert.NotEmpty(t, members.Error)
+
+	events := make(chan *AdminEvent, 10)
+	subscriber := client.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*AdminEvent); ok {
+			events <- msg
+		}
+	}, "subscriber")
+	client.Send(AdminPID(e.Address()), &AdminSubscribe{Subscriber: subscriber})
+
+	// The subscription is processed asynchronously, retry until the event
+	// of a started actor is received.
+	require.Eventually(t, func() bool {
+		e.SpawnFunc(func(*actor.Context) {}, "foo")
+		for {
+			select {
+			case event := <-events:
+				if event.Type == "actor.ActorStartedEvent" {
+					assert.Contains(t, event.Event, "foo/")
+					return true
+				}
+			case <-time.After(time.Millisecond * 10):
+				return false
+			}
+		}
+	}, time.Second, time.Millisecond*10)
+
+	adminRequest[*AdminUnsubscribeResponse](t, client, e.Address(), &AdminUnsubscribe{Subscriber: subscriber})
+	for len(events) > 0 {
+		<-events
+	}
+	e.SpawnFunc(func(*actor.Context) {}, "bar")
+	time.Sleep(time.Millisecond * 50)
+	assert.Empty(t, events)
+}
+
+func TestAdminOptIn(t *testing.T) {
+	addr := getRandomLocalhostAddr()
+	c := makeCluster(t, addr, "A", "eu")
+	c.Start()
+	defer c.Stop()
+	client := makeAdminClient(t)
+
+	// The cluster only serves the admin protocol when it's configured to.
+	_, err := client.Request(AdminPID(addr), &AdminMembersRequest{}, time.Millisecond*200).Result()
+	assert.Error(t, err)
+
+	// Sending messages needs to be enabled on its own.
+	SpawnAdmin(c.Engine(), NewAdminConfig())
+	pid := actor.NewPID(addr, "foo")
+	sent := adminRequest[*AdminSendResponse](t, client, addr, &AdminSendRequest{Pid: pid, Type: "remote.TestMessage"})
+	assert.Contains(t, sent.Error, "disabled")
+}
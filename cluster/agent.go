@@ -3,16 +3,32 @@ package cluster
 import (
 	"log/slog"
 	"reflect"
-	"strings"
+	"slices"
+	"time"
 
 	"github.com/khulnasoft/goactors/actor"
 	"golang.org/x/exp/maps"
 )
 
+const (
+	// maxActivationAttempts is the number of times an activation is tried
+	// when the member we selected has a different view of the topology.
+	maxActivationAttempts = 3
+	// activationRetryBackoff is the time we give the members to converge on
+	// the same topology before an activation is retried.
+	activationRetryBackoff = time.Millisecond * 50
+)
+
 type (
 	activate struct {
 		kind   string
 		config ActivationConfig
+		// the number of times this activation was retried because of a
+		// topology mismatch.
+		attempt int
+		// respond with the active instance instead of failing when the
+		// identity is already activated.
+		lookup bool
 	}
 	getMembers struct{}
 	getKinds   struct{}
@@ -32,6 +48,32 @@ type Agent struct {
 	localKinds map[string]kind
 	// All the actors that are available cluster wide.
 	activated map[string]*actor.PID
+	// The hash of the members in our view of the cluster.
+	topologyHash uint64
+	// The local grains by the identity (kind/id) they route to.
+	grains map[string]*actor.PID
+	// The members that gracefully left the cluster by their ID.
+	departed map[string]*Member
+	// The IDs of the members the quorum of the leases is based on, all the
+	// members we've seen except the ones that gracefully left. Members that
+	// failed keep counting, so a member cut off by a partition can't reach a
+	// quorum on its own.
+	quorum map[string]bool
+	// True once we are leaving the cluster, activations are rejected.
+	leaving bool
+	// The latest load stats of the members by their ID.
+	stats         map[string]*MemberStats
+	cpu           cpuSampler
+	statsRepeater actor.SendRepeater
+	// The old activations of the identities that migrated to another member,
+	// which redirect to the new activation, by their ID.
+	redirects map[string]*actor.PID
+	// The IDs of the old activations that are stopping, the identities can't
+	// be activated on this member until they stopped.
+	draining map[string]bool
+	// True while a retry of the activation of the managed singletons is
+	// scheduled.
+	singletonRetry bool
 }
 
 func NewAgent(c *Cluster) actor.Producer {
@@ -42,12 +84,20 @@ func NewAgent(c *Cluster) actor.Producer {
 		localKinds[kind.name] = kind
 	}
 	return func() actor.Receiver {
+		members := NewMemberSet()
 		return &Agent{
-			members:    NewMemberSet(),
-			cluster:    c,
-			kinds:      kinds,
-			localKinds: localKinds,
-			activated:  make(map[string]*actor.PID),
+			members:      members,
+			cluster:      c,
+			kinds:        kinds,
+			localKinds:   localKinds,
+			activated:    make(map[string]*actor.PID),
+			topologyHash: members.TopologyHash(),
+			grains:       make(map[string]*actor.PID),
+			departed:     make(map[string]*Member),
+			quorum:       make(map[string]bool),
+			stats:        make(map[string]*MemberStats),
+			redirects:    make(map[string]*actor.PID),
+			draining:     make(map[string]bool),
 		}
 	}
 }
@@ -55,16 +105,28 @@ func NewAgent(c *Cluster) actor.Producer {
 func (a *Agent) Receive(c *actor.Context) {
 	switch msg := c.Message().(type) {
 	case actor.Started:
+		a.publishStats()
+		a.statsRepeater = c.SendRepeat(c.PID(), statsTick{}, a.cluster.config.statsInterval)
 	case actor.Stopped:
+		a.statsRepeater.Stop()
+		for _, pid := range a.grains {
+			a.cluster.engine.Poison(pid)
+		}
 	case *ActorTopology:
 		a.handleActorTopology(msg)
 	case *Members:
-		a.handleMembers(msg.Members)
+		a.handleMembers(a.withoutDeparted(msg.Members))
+	case *MembersLeave:
+		a.handleMembersLeave(msg.Members)
+	case leave:
+		a.handleLeave(c)
+	case down:
+		a.leaving = true
+		c.Respond(a.localActivations())
 	case *Activation:
 		a.handleActivation(msg)
 	case activate:
-		pid := a.activate(msg.kind, msg.config)
-		c.Respond(pid)
+		a.handleActivate(c, msg)
 	case deactivate:
 		a.bcast(&Deactivation{PID: msg.pid})
 	case *Deactivation:
@@ -74,6 +136,8 @@ func (a *Agent) Receive(c *actor.Context) {
 		c.Respond(resp)
 	case getMembers:
 		c.Respond(a.members.Slice())
+	case getQuorumSize:
+		c.Respond(max(len(a.quorum), a.members.Len()))
 	case getKinds:
 		kinds := make([]string, len(a.kinds))
 		i := 0
@@ -84,6 +148,53 @@ func (a *Agent) Receive(c *actor.Context) {
 		c.Respond(kinds)
 	case getActive:
 		a.handleGetActive(c, msg)
+	case getGrain:
+		c.Respond(a.getGrain(msg.kind, msg.config))
+	case grainIdle:
+		a.handleGrainIdle(msg)
+	case retrySingletons:
+		a.singletonRetry = false
+		a.ensureSingletons()
+	case statsTick:
+		a.publishStats()
+	case *MemberStats:
+		a.handleMemberStats(msg)
+	case getMemberStats:
+		c.Respond(a.memberStats())
+	case redirectExpired:
+		a.handleRedirectExpired(msg.pid)
+	case redirectStopped:
+		delete(a.draining, msg.id)
+	case getTopology:
+		c.Respond(&Topology{
+			Hash:    a.topologyHash,
+			Members: a.members.Slice(),
+		})
+	}
+}
+
+// getGrain returns the grain of the given identity, it's spawned when there
+// is none yet. Grains are only spawned for the kinds of the cluster, so the
+// callers can't fill the map with made up kinds.
+func (a *Agent) getGrain(kind string, config ActivationConfig) *actor.PID {
+	identity := kind + "/" + config.id
+	if pid, ok := a.grains[identity]; ok {
+		return pid
+	}
+	if !a.kinds[kind] {
+		return nil
+	}
+	pid := spawnGrain(a.cluster, kind, config)
+	a.grains[identity] = pid
+	return pid
+}
+
+// handleGrainIdle stops the grain that has been idle, unless it was replaced
+// already.
+func (a *Agent) handleGrainIdle(msg grainIdle) {
+	if pid, ok := a.grains[msg.identity]; ok && pid.Equals(msg.pid) {
+		delete(a.grains, msg.identity)
+		a.cluster.engine.Poison(pid)
 	}
 }
 
@@ -93,79 +204,249 @@ func (a *Agent) handleGetActive(c *actor.Context, msg getActive) {
 		c.Respond(pid)
 	}
 	if len(msg.kind) > 0 {
-		pids := make([]*actor.PID, 0)
-		for id, pid := range a.activated {
-			parts := strings.Split(id, "/")
-			if len(parts) == 0 {
-				break
-			}
-			kind := parts[0]
-			if msg.kind == kind {
-				pids = append(pids, pid)
-			}
-		}
-		c.Respond(pids)
+		c.Respond(a.activatedByKind(msg.kind, ""))
 	}
 }
 
 func (a *Agent) handleActorTopology(msg *ActorTopology) {
 	for _, actorInfo := range msg.Actors {
-		a.addActivated(actorInfo.PID)
+		pid := actorInfo.PID
+		if active, ok := a.activated[pid.ID]; ok && active.Equals(pid) {
+			continue
+		}
+		if a.addActivated(pid) {
+			a.cluster.engine.BroadcastEvent(ActivationEvent{PID: pid})
+		}
 	}
 }
 
 func (a *Agent) handleDeactivation(msg *Deactivation) {
+	// Another activation of an identity we host was deactivated. The other
+	// members could have dropped ours in favour of that one, remind them.
+	if active, ok := a.activated[msg.PID.ID]; ok && !active.Equals(msg.PID) &&
+		active.Address == a.cluster.engine.Address() {
+		a.bcast(&Activation{PID: active})
+	}
 	a.removeActivated(msg.PID)
-	a.cluster.engine.Poison(msg.PID)
+	// Poison only looks at the ID of the PID, make sure we don't poison a
+	// local activation of the same identity.
+	if msg.PID.Address == a.cluster.engine.Address() && !a.isRedirect(msg.PID) {
+		a.cluster.engine.Poison(msg.PID)
+	}
 	a.cluster.engine.BroadcastEvent(DeactivationEvent{PID: msg.PID})
+	a.ensureSingletons()
 }
 
 // A new kind is activated on this cluster.
 func (a *Agent) handleActivation(msg *Activation) {
-	a.addActivated(msg.PID)
-	a.cluster.engine.BroadcastEvent(ActivationEvent{PID: msg.PID})
+	if a.addActivated(msg.PID) {
+		a.cluster.engine.BroadcastEvent(ActivationEvent{PID: msg.PID})
+	}
 }
 
 func (a *Agent) handleActivationRequest(msg *ActivationRequest) *ActivationResponse {
+	if a.leaving {
+		return a.rejectActivation(ReasonMemberLeaving)
+	}
+	// The requester selected us based on a member list that differs from
+	// ours. Reject, so it can retry once the topology converged.
+	if msg.TopologyHash != a.topologyHash {
+		slog.Warn("rejected activation request, topology mismatch",
+			"kind", msg.Kind,
+			"id", msg.ID,
+			"requester", msg.TopologyHash,
+			"local", a.topologyHash)
+		return a.rejectActivation(ReasonTopologyMismatch)
+	}
 	if !a.hasKindLocal(msg.Kind) {
 		slog.Error("received activation request but kind not registered locally on this node", "kind", msg.Kind)
-		return &ActivationResponse{Success: false}
+		return a.rejectActivation(ReasonKindNotRegistered)
 	}
 
 	kind := a.localKinds[msg.Kind]
-	pid := a.cluster.engine.Spawn(kind.producer, msg.Kind, actor.WithID(msg.ID))
+	if !kind.config.allowsRegion(a.cluster.config.region) {
+		return a.rejectActivation(ReasonRegionNotAllowed)
+	}
+	if kind.config.singleton && len(a.activatedByKind(msg.Kind, "")) > 0 {
+		return a.rejectActivation(ReasonSingletonAlreadyActive)
+	}
+	if limit := kind.config.maxActivations; limit > 0 &&
+		len(a.activatedByKind(msg.Kind, a.cluster.engine.Address())) >= limit {
+		return a.rejectActivation(ReasonMaxActivationsReached)
+	}
+	// The identity was reactivated before the handoff arrived, the state
+	// can't be restored on a running actor.
+	active, isActive := a.activated[msg.Kind+"/"+msg.ID]
+	if len(msg.Handoff) > 0 && isActive && !msg.Migration {
+		return a.rejectActivation(ReasonAlreadyActive)
+	}
+	if msg.Migration && isActive && active.Address == a.cluster.engine.Address() {
+		return a.rejectActivation(ReasonAlreadyActive)
+	}
+	// The identity migrated away from us before, its old activation still
+	// redirects to the new one. It has to stop before the identity can be
+	// spawned again.
+	if a.stopRedirect(msg.Kind + "/" + msg.ID) {
+		return a.rejectActivation(ReasonRedirectStopping)
+	}
+
+	// The options of the kind go first, the ID of the activation can not be overridden.
+	opts := slices.Clone(kind.config.spawnOpts)
+	opts = append(opts, actor.WithID(msg.ID))
+	if kind.config.idleTimeout > 0 {
+		opts = append(opts, actor.WithMiddleware(withPassivation(a.cluster, kind.config.idleTimeout)))
+	}
+	// Migrations carry the state of every kind, other activations only get
+	// it when the kind has handoff enabled.
+	if kind.config.handoff || msg.Migration {
+		opts = append(opts, actor.WithMiddleware(withHandoff(msg.Handoff)))
+	} else if len(msg.Handoff) > 0 {
+		slog.Warn("ignored handoff state, kind has no handoff enabled", "kind", msg.Kind, "id", msg.ID)
+	}
+	opts = append(opts, actor.WithMiddleware(withMigration()))
+	pid := a.cluster.engine.Spawn(kind.producer, msg.Kind, opts...)
+	// The migrated activation is replaced, not in conflict with this one.
+	if msg.Migration && isActive {
+		a.removeActivated(active)
+	}
+	// Count the activation right away, the broadcast of the requester
+	// could arrive after the next request.
+	a.addActivated(pid)
 	resp := &ActivationResponse{
-		PID:     pid,
-		Success: true,
+		PID:          pid,
+		Success:      true,
+		TopologyHash: a.topologyHash,
 	}
 	return resp
 }
 
-func (a *Agent) activate(kind string, config ActivationConfig) *actor.PID {
+func (a *Agent) rejectActivation(reason string) *ActivationResponse {
+	return &ActivationResponse{
+		Success:      false,
+		TopologyHash: a.topologyHash,
+		Reason:       reason,
+	}
+}
+
+// activatedByKind returns the activations of the given kind. If address is
+// not empty, only the activations hosted on that address are returned.
+func (a *Agent) activatedByKind(kind, address string) []*actor.PID {
+	pids := make([]*actor.PID, 0)
+	for _, pid := range a.activated {
+		if actor.KindOf(pid) != kind {
+			continue
+		}
+		if len(address) > 0 && pid.Address != address {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids
+}
+
+// membersForKind returns the members the given kind can be activated on.
+func (a *Agent) membersForKind(kind string) []*Member {
+	members := a.members.FilterByKind(kind)
+	// When we know the policy of the kind, don't select members that would
+	// reject the activation anyway.
+	if local, ok := a.localKinds[kind]; ok {
+		members = slices.DeleteFunc(members, func(m *Member) bool {
+			return !local.config.allowsRegion(m.Region)
+		})
+	}
+	return members
+}
+
+// activationsByMember returns the number of activations each member hosts,
+// by the ID of the member.
+func (a *Agent) activationsByMember() map[string]int {
+	var (
+		ids    = make(map[string]string, a.members.Len())
+		counts = make(map[string]int, a.members.Len())
+	)
+	a.members.ForEach(func(m *Member) bool {
+		ids[m.Host] = m.ID
+		return true
+	})
+	for _, pid := range a.activated {
+		if id, ok := ids[pid.Address]; ok {
+			counts[id]++
+		}
+	}
+	return counts
+}
+
+func (a *Agent) handleActivate(c *actor.Context, msg activate) {
+	if msg.lookup {
+		if pid, ok := a.activated[msg.kind+"/"+msg.config.id]; ok {
+			c.Respond(pid)
+			return
+		}
+	}
+	pid, retry := a.activate(msg.kind, msg.config)
+	if !retry {
+		c.Respond(pid)
+		return
+	}
+	if msg.attempt+1 >= maxActivationAttempts {
+		slog.Error("activation failed, retries exhausted",
+			"kind", msg.kind,
+			"id", msg.config.id,
+			"attempts", msg.attempt+1)
+		c.Respond(nil)
+		return
+	}
+	// Retry later, so we can process the membership updates that are on
+	// their way in the mean time.
+	var (
+		self   = c.PID()
+		sender = c.Sender()
+		engine = c.Engine()
+	)
+	msg.attempt++
+	time.AfterFunc(activationRetryBackoff*time.Duration(msg.attempt), func() {
+		engine.SendWithSender(self, msg, sender)
+	})
+}
+
+// activate activates the given kind on one of the members. It returns true
+// if the activation should be retried, because the selected member has a
+// different view of the topology or still stops the old activation of the
+// identity.
+func (a *Agent) activate(kind string, config ActivationConfig) (*actor.PID, bool) {
 	// Make sure actors are unique across the whole cluster.
 	id := kind + "/" + config.id // the id part of the PID
-	if _, ok := a.activated[id]; ok {
+	if active, ok := a.activated[id]; ok && (config.migrate == nil || !active.Equals(config.migrate)) {
 		slog.Warn("activation failed", "err", "duplicated actor id across the cluster", "id", id)
-		return nil
+		return nil, false
 	}
-	members := a.members.FilterByKind(kind)
+	members := a.membersForKind(kind)
 	if len(members) == 0 {
 		slog.Warn("could not find any members with kind", "kind", kind)
-		return nil
+		return nil, false
 	}
 	if config.selectMember == nil {
-		config.selectMember = SelectRandomMember
+		config.selectMember = SelectRendezvousMember
 	}
 	memberPID := config.selectMember(ActivationDetails{
-		Members: members,
-		Region:  config.region,
-		Kind:    kind,
+		Members:     members,
+		Region:      config.region,
+		Kind:        kind,
+		ID:          config.id,
+		Activations: a.activationsByMember(),
+		Stats:       a.stats,
 	})
 	if memberPID == nil {
 		slog.Warn("activator did not found a member to activate on")
-		return nil
+		return nil, false
+	}
+	req := &ActivationRequest{
+		Kind:         kind,
+		ID:           config.id,
+		TopologyHash: a.topologyHash,
+		Handoff:      config.handoff,
+		Migration:    config.migrate != nil,
 	}
-	req := &ActivationRequest{Kind: kind, ID: config.id}
 	activatorPID := actor.NewPID(memberPID.Host, "cluster/"+memberPID.ID)
 
 	var activationResp *ActivationResponse
@@ -174,30 +455,38 @@ func (a *Agent) activate(kind string, config ActivationConfig) *actor.PID {
 		activationResp = a.handleActivationRequest(req)
 	} else {
 		// Remote activation
-		//
-		// TODO: topology hash
 		resp, err := a.cluster.engine.Request(activatorPID, req, a.cluster.config.requestTimeout).Result()
 		if err != nil {
 			slog.Error("failed activation request", "err", err)
-			return nil
+			return nil, false
 		}
 		r, ok := resp.(*ActivationResponse)
 		if !ok {
 			slog.Error("expected *ActivationResponse", "msg", reflect.TypeOf(resp))
-			return nil
-		}
-		if !r.Success {
-			slog.Error("activation unsuccessful", "msg", r)
-			return nil
+			return nil, false
 		}
 		activationResp = r
 	}
+	if !activationResp.Success {
+		if activationResp.TopologyHash != req.TopologyHash ||
+			activationResp.Reason == ReasonRedirectStopping {
+			return nil, true
+		}
+		slog.Error("activation unsuccessful", "kind", kind, "id", config.id, "reason", activationResp.Reason)
+		return nil, false
+	}
 
+	if config.migrate != nil {
+		a.migrated(config.migrate, activationResp.PID)
+	}
+	// Register the activation right away, so we don't activate the same
+	// identity twice before our own broadcast arrives.
+	a.addActivated(activationResp.PID)
 	a.bcast(&Activation{
 		PID: activationResp.PID,
 	})
 
-	return activationResp.PID
+	return activationResp.PID, false
 }
 
 func (a *Agent) handleMembers(members []*Member) {
@@ -210,10 +499,21 @@ func (a *Agent) handleMembers(members []*Member) {
 	for _, member := range left {
 		a.memberLeave(member)
 	}
+	// Members we already know may have changed their kinds, region, labels
+	// or capacity.
+	for _, member := range members {
+		if a.members.Contains(member) {
+			a.memberUpdate(member)
+		}
+	}
+	a.topologyHash = a.members.TopologyHash()
+	a.ensureSingletons()
 }
 
-func (a *Agent) memberJoin(member *Member) {
+// memberUpdate stores the given version of the member.
+func (a *Agent) memberUpdate(member *Member) {
 	a.members.Add(member)
+	a.quorum[member.ID] = true
 
 	// track cluster wide available kinds
 	for _, kind := range member.Kinds {
@@ -221,6 +521,10 @@ func (a *Agent) memberJoin(member *Member) {
 			a.kinds[kind] = true
 		}
 	}
+}
+
+func (a *Agent) memberJoin(member *Member) {
+	a.memberUpdate(member)
 
 	actorInfos := make([]*ActorInfo, 0)
 	for _, pid := range a.activated {
@@ -235,6 +539,11 @@ func (a *Agent) memberJoin(member *Member) {
 		a.cluster.engine.Send(member.PID(), &ActorTopology{Actors: actorInfos})
 	}
 
+	// Don't let the member wait for the next interval to know our load.
+	if stats, ok := a.stats[a.cluster.config.id]; ok && member.ID != stats.MemberID {
+		a.cluster.engine.Send(member.PID(), stats)
+	}
+
 	// Broadcast MemberJoinEvent
 	a.cluster.engine.BroadcastEvent(MemberJoinEvent{
 		Member: member,
@@ -250,6 +559,7 @@ func (a *Agent) memberJoin(member *Member) {
 
 func (a *Agent) memberLeave(member *Member) {
 	a.members.Remove(member)
+	delete(a.stats, member.ID)
 	a.rebuildKinds()
 
 	// Remove all the activeKinds that where running on the member that left the cluster.
@@ -271,15 +581,30 @@ func (a *Agent) bcast(msg any) {
 	})
 }
 
-func (a *Agent) addActivated(pid *actor.PID) {
-	if _, ok := a.activated[pid.ID]; !ok {
+// addActivated adds the given activation and returns true if it's the
+// activation of its identity afterwards, false if it lost a conflict.
+func (a *Agent) addActivated(pid *actor.PID) bool {
+	active, ok := a.activated[pid.ID]
+	if !ok {
 		a.activated[pid.ID] = pid
 		slog.Debug("new actor available on cluster", "pid", pid)
+		return true
+	}
+	if !active.Equals(pid) {
+		a.resolveConflict(active, pid)
 	}
+	return a.activated[pid.ID].Equals(pid)
 }
 
 func (a *Agent) removeActivated(pid *actor.PID) {
+	// The same identity could have been activated on another member.
+	if active, ok := a.activated[pid.ID]; !ok || !active.Equals(pid) {
+		return
+	}
 	delete(a.activated, pid.ID)
+	if grainPID, ok := a.grains[pid.ID]; ok {
+		a.cluster.engine.Send(grainPID, grainInvalidate{pid: pid})
+	}
 	slog.Debug("actor removed from cluster", "pid", pid)
 }
 
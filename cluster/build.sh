@@ -1,2 +1,2 @@
 
-protoc -I=../actor --go_out=. --go-vtproto_out=. --go_opt=paths=source_relative  --proto_path=. cluster.proto
\ No newline at end of file
+protoc -I=../actor --go_out=. --go-vtproto_out=. --go_opt=paths=source_relative  --proto_path=. cluster.proto replicated.proto admin.proto
\ No newline at end of file
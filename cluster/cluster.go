@@ -3,6 +3,7 @@ package cluster
 import (
 	fmt "fmt"
 	"log/slog"
+	"maps"
 	"math"
 	"math/rand"
 	"reflect"
@@ -22,22 +23,32 @@ type Producer func(c *Cluster) actor.Producer
 
 // Config holds the cluster configuration
 type Config struct {
-	listenAddr     string
-	id             string
-	region         string
-	engine         *actor.Engine
-	provider       Producer
-	requestTimeout time.Duration
+	listenAddr         string
+	id                 string
+	region             string
+	labels             map[string]string
+	capacity           uint32
+	engine             *actor.Engine
+	provider           Producer
+	requestTimeout     time.Duration
+	statsInterval      time.Duration
+	dataGossipInterval time.Duration
+	grainIdleTimeout   time.Duration
+	admin              *AdminConfig
 }
 
 // NewConfig returns a Config that is initialized with default values.
 func NewConfig() Config {
 	return Config{
-		listenAddr:     getRandomListenAddr(),
-		id:             fmt.Sprintf("%d", rand.Intn(math.MaxInt)),
-		region:         "default",
-		provider:       NewSelfManagedProvider(NewSelfManagedConfig()),
-		requestTimeout: defaultRequestTimeout,
+		listenAddr:         getRandomListenAddr(),
+		id:                 fmt.Sprintf("%d", rand.Intn(math.MaxInt)),
+		region:             "default",
+		capacity:           1,
+		provider:           NewSelfManagedProvider(NewSelfManagedConfig()),
+		requestTimeout:     defaultRequestTimeout,
+		statsInterval:      defaultStatsInterval,
+		dataGossipInterval: defaultDataGossipInterval,
+		grainIdleTimeout:   defaultGrainIdleTimeout,
 	}
 }
 
@@ -93,16 +104,81 @@ func (config Config) WithRegion(region string) Config {
 	return config
 }
 
+// WithLabels set's the labels of the member, which are advertised to the
+// other members so placement functions can select members by them.
+//
+//	cluster.NewConfig().WithLabels(map[string]string{"zone": "eu-west-1a", "gpu": "true"})
+//
+// Defaults to no labels.
+func (config Config) WithLabels(labels map[string]string) Config {
+	config.labels = maps.Clone(labels)
+	return config
+}
+
+// WithCapacity set's the relative number of activations the member can host
+// compared to the other members. A member with a capacity of 2 gets twice as
+// many activations as a member with a capacity of 1 when activations are
+// placed with SelectCapacityWeightedMember.
+//
+// Defaults to 1.
+func (config Config) WithCapacity(capacity uint32) Config {
+	config.capacity = capacity
+	return config
+}
+
+// WithStatsInterval set's the interval at which the member publishes its
+// load stats to the other members of the cluster.
+//
+// Defaults to 5 seconds.
+func (config Config) WithStatsInterval(d time.Duration) Config {
+	config.statsInterval = d
+	return config
+}
+
+// WithDataGossipInterval set's the interval at which the member gossips its
+// replicated data with a random other member.
+//
+// Defaults to 2 seconds.
+func (config Config) WithDataGossipInterval(d time.Duration) Config {
+	config.dataGossipInterval = d
+	return config
+}
+
+// WithGrainIdleTimeout set's the duration after which the local process
+// behind a PID returned by Get is stopped when it didn't receive any message.
+// Get returns a new one afterwards.
+//
+// Defaults to 1 minute.
+func (config Config) WithGrainIdleTimeout(d time.Duration) Config {
+	config.grainIdleTimeout = d
+	return config
+}
+
+// WithAdmin set's the configuration of the admin actor, the cluster serves the
+// admin protocol when it's set, see AdminPID.
+//
+// Defaults to no admin actor.
+func (config Config) WithAdmin(admin AdminConfig) Config {
+	config.admin = &admin
+	return config
+}
+
 // Cluster allows you to write distributed actors. It combines Engine, Remote, and
 // Provider which allows members of the cluster to send messages to eachother in a
 // self discovering environment.
 type Cluster struct {
-	config      Config
-	engine      *actor.Engine
-	agentPID    *actor.PID
-	providerPID *actor.PID
-	isStarted   bool
-	kinds       []kind
+	config        Config
+	engine        *actor.Engine
+	agentPID      *actor.PID
+	providerPID   *actor.PID
+	leasesPID     *actor.PID
+	replicatorPID *actor.PID
+	adminPID      *actor.PID
+	isStarted     bool
+	startedAt     time.Time
+	kinds         []kind
+	sharded       []shardedKind
+	regions       []*actor.PID
 }
 
 // New returns a new cluster given a Config.
@@ -125,13 +201,35 @@ func New(config Config) (*Cluster, error) {
 
 // Start the cluster
 func (c *Cluster) Start() {
+	c.startedAt = time.Now()
 	c.agentPID = c.engine.Spawn(NewAgent(c), "cluster", actor.WithID(c.config.id))
 	c.providerPID = c.engine.Spawn(c.config.provider(c), "provider", actor.WithID(c.config.id))
+	c.leasesPID = c.engine.Spawn(newLeaseTable(c), "lease", actor.WithID(c.config.id))
+	c.replicatorPID = c.engine.Spawn(newReplicator(c), "replicator", actor.WithID(c.config.id))
+	if c.config.admin != nil {
+		c.adminPID = c.engine.Spawn(newAdmin(c.engine, c, *c.config.admin), "admin", actor.WithID("engine"))
+	}
+	for _, sk := range c.sharded {
+		c.regions = append(c.regions, c.engine.Spawn(newShardRegion(c, sk), "shardregion", actor.WithID(sk.name)))
+	}
 	c.isStarted = true
 }
 
-// Stop will shutdown the cluster poisoning all its actors.
+// Stop will shutdown the cluster poisoning all its actors. The shards hosted
+// by the member are handed off to the other members first.
 func (c *Cluster) Stop() {
+	// The entities of the shards are asked for their state, which can take
+	// a request timeout before they are stopped.
+	c.stopRegions(2 * c.config.requestTimeout)
+	for _, pid := range c.regions {
+		<-c.engine.Poison(pid).Done()
+	}
+	c.regions = nil
+	if c.adminPID != nil {
+		<-c.engine.Poison(c.adminPID).Done()
+	}
+	<-c.engine.Poison(c.leasesPID).Done()
+	<-c.engine.Poison(c.replicatorPID).Done()
 	<-c.engine.Poison(c.agentPID).Done()
 	<-c.engine.Poison(c.providerPID).Done()
 }
@@ -281,10 +379,15 @@ func (c *Cluster) Member() *Member {
 		kinds[i] = c.kinds[i].name
 	}
 	m := &Member{
-		ID:     c.config.id,
-		Host:   c.engine.Address(),
-		Kinds:  kinds,
-		Region: c.config.region,
+		ID:       c.config.id,
+		Host:     c.engine.Address(),
+		Kinds:    kinds,
+		Region:   c.config.region,
+		Labels:   c.config.labels,
+		Capacity: c.config.capacity,
+	}
+	if !c.startedAt.IsZero() {
+		m.Started = c.startedAt.UnixNano()
 	}
 	return m
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"net"
 	"sync"
 	"testing"
 	"time"
@@ -63,21 +64,11 @@ func TestClusterSelectMemberFunc(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 
-	// Subscribe BEFORE starting clusters to catch all MemberJoinEvents
+	activated := make(chan *actor.PID, 1)
 	eventPID := c1.Engine().SpawnFunc(func(c *actor.Context) {
-		switch msg := c.Message().(type) {
-		case ActivationEvent:
-			// test that we spawned on member C
-			require.Equal(t, c3.Address(), msg.PID.Address)
+		if msg, ok := c.Message().(ActivationEvent); ok {
+			activated <- msg.PID
 			cancel()
-		case MemberJoinEvent:
-			if msg.Member.ID == "C" {
-				// Wait till member C is online before activating
-				// Activate the actor from member A
-				// Which should spawn the actor on member C
-				config := NewActivationConfig().WithSelectMemberFunc(selectMember)
-				c1.Activate("player", config)
-			}
 		}
 	}, "event")
 	c1.Engine().Subscribe(eventPID)
@@ -87,8 +78,21 @@ func TestClusterSelectMemberFunc(t *testing.T) {
 	c2.Start()
 	c3.Start()
 
+	// Wait till all members agree on the topology before activating the
+	// actor from member A, which should spawn it on member C.
+	require.Eventually(t, func() bool {
+		for _, c := range []*Cluster{c1, c2, c3} {
+			if len(c.Members()) != 3 {
+				return false
+			}
+		}
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+	c1.Activate("player", NewActivationConfig().WithSelectMemberFunc(selectMember))
+
 	<-ctx.Done()
 	require.Equal(t, context.Canceled, ctx.Err())
+	require.Equal(t, c3.Address(), (<-activated).Address)
 	c1.Stop()
 	c2.Stop()
 	c3.Stop()
@@ -430,6 +434,102 @@ func TestCannotDuplicateActor(t *testing.T) {
 	c2.Stop()
 }
 
+func TestSelectRendezvousMember(t *testing.T) {
+	members := []*Member{
+		{ID: "A", Host: ":3000"},
+		{ID: "B", Host: ":3001"},
+		{ID: "C", Host: ":3002"},
+	}
+	placement := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprint(i)
+		member := SelectRendezvousMember(ActivationDetails{Members: members, Kind: "player", ID: id})
+		require.NotNil(t, member)
+		// the order of the members does not matter.
+		reversed := []*Member{members[2], members[1], members[0]}
+		assert.Equal(t, member, SelectRendezvousMember(ActivationDetails{Members: reversed, Kind: "player", ID: id}))
+		placement[id] = member.ID
+	}
+
+	// Only the identities that were placed on the member that left should move.
+	for id, memberID := range placement {
+		member := SelectRendezvousMember(ActivationDetails{Members: members[:2], Kind: "player", ID: id})
+		if memberID != "C" {
+			assert.Equal(t, memberID, member.ID)
+		}
+	}
+}
+
+func TestTopologyHash(t *testing.T) {
+	a := &Member{ID: "A", Host: ":3000"}
+	b := &Member{ID: "B", Host: ":3001"}
+	assert.Equal(t, NewMemberSet(a, b).TopologyHash(), NewMemberSet(b, a).TopologyHash())
+	assert.NotEqual(t, NewMemberSet(a).TopologyHash(), NewMemberSet(a, b).TopologyHash())
+}
+
+func TestMemberWatch(t *testing.T) {
+	var (
+		a = &Member{ID: "A", Host: "127.0.0.1:3000"}
+		b = &Member{ID: "B", Host: "127.0.0.1:3001"}
+		c = &Member{ID: "C", Host: "127.0.0.1:3002"}
+	)
+	w := newMemberWatch()
+	assert.True(t, w.handle(MemberJoinEvent{Member: c}))
+	assert.True(t, w.handle(MemberLeaveEvent{Member: b}))
+	// The snapshot was taken before B left.
+	assert.True(t, w.handle(memberSnapshot{members: []*Member{a, b}}))
+	assert.Equal(t, map[string]bool{a.Host: true, c.Host: true}, w.hosts())
+
+	// B joins again.
+	w.handle(MemberJoinEvent{Member: b})
+	assert.Equal(t, 3, w.members.Len())
+	assert.False(t, w.handle("foo"))
+}
+
+func TestMemberSetDigest(t *testing.T) {
+	a := &Member{ID: "A", Host: ":3000", Started: 1}
+	b := &Member{ID: "B", Host: ":3001", Started: 1}
+	assert.Equal(t, NewMemberSet(a, b).Digest(), NewMemberSet(b, a).Digest())
+
+	restarted := &Member{ID: "A", Host: ":3000", Started: 2}
+	assert.Equal(t, NewMemberSet(a, b).TopologyHash(), NewMemberSet(restarted, b).TopologyHash())
+	assert.NotEqual(t, NewMemberSet(a, b).Digest(), NewMemberSet(restarted, b).Digest())
+}
+
+func TestActivationTopologyMismatch(t *testing.T) {
+	c1Addr := getRandomLocalhostAddr()
+	c1 := makeCluster(t, c1Addr, "A", "eu")
+	c1.RegisterKind("player", NewPlayer, NewKindConfig())
+	c1.Start()
+	defer c1.Stop()
+
+	c2 := makeClusterWithBootstrap(t, getRandomLocalhostAddr(), "B", "eu", MemberAddr{ListenAddr: c1Addr, ID: "A"})
+	c2.RegisterKind("player", NewPlayer, NewKindConfig())
+	c2.Start()
+	defer c2.Stop()
+
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 2 && len(c2.Members()) == 2
+	}, time.Second, time.Millisecond*10)
+
+	// A request that was computed against a stale member list is rejected.
+	stale := NewMemberSet(c1.Member()).TopologyHash()
+	req := &ActivationRequest{Kind: "player", ID: "1", TopologyHash: stale}
+	resp, err := c2.Engine().Request(c1.PID(), req, time.Second).Result()
+	require.NoError(t, err)
+	r, ok := resp.(*ActivationResponse)
+	require.True(t, ok)
+	assert.False(t, r.Success)
+	assert.NotEqual(t, stale, r.TopologyHash)
+	assert.Nil(t, c1.GetActiveByID("player/1"))
+
+	// Members agreeing on the topology place the same identity on the same member.
+	pid := c2.Activate("player", NewActivationConfig().WithID("1"))
+	require.NotNil(t, pid)
+	expected := SelectRendezvousMember(ActivationDetails{Members: c1.Members(), Kind: "player", ID: "1"})
+	assert.Equal(t, expected.Host, pid.Address)
+}
+
 func makeCluster(t *testing.T, addr, id, region string) *Cluster {
 	config := NewConfig().
 		WithID(id).
@@ -455,6 +555,13 @@ func makeClusterWithBootstrap(t *testing.T, addr, id, region string, bootstrapMe
 	return c
 }
 
+// getRandomLocalhostAddr returns an address with a port the OS considers free,
+// picking a random port could collide with members of other tests.
 func getRandomLocalhostAddr() string {
-	return fmt.Sprintf("127.0.0.1:%d", rand.Intn(50000)+10000)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Sprintf("127.0.0.1:%d", rand.Intn(50000)+10000)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
 }
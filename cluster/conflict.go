@@ -0,0 +1,59 @@
+package cluster
+
+import (
+	"log/slog"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/zeebo/xxh3"
+)
+
+// resolveConflict is called when we learn about an activation of an identity
+// that is already active on another member. Each member comes to the same
+// conclusion on which one stays, the member hosting the other one deactivates
+// it.
+func (a *Agent) resolveConflict(active, other *actor.PID) {
+	winner, loser := a.conflictWinner(active, other)
+	a.activated[winner.ID] = winner
+	if grainPID, ok := a.grains[loser.ID]; ok {
+		a.cluster.engine.Send(grainPID, grainInvalidate{pid: loser})
+	}
+
+	slog.Warn("activation conflict", "winner", winner, "loser", loser)
+	a.cluster.engine.BroadcastEvent(ActivationConflictEvent{
+		Winner: winner,
+		Loser:  loser,
+	})
+
+	if loser.Address == a.cluster.engine.Address() {
+		a.bcast(&Deactivation{PID: loser})
+	}
+}
+
+// conflictWinner returns which of the two activations of the same identity
+// stays active. Singletons stay on their owner, otherwise the activation with
+// the highest weight of its host and ID wins, which does not depend on the
+// view of the topology.
+func (a *Agent) conflictWinner(x, y *actor.PID) (winner, loser *actor.PID) {
+	name := actor.KindOf(x)
+	if kind, ok := a.localKinds[name]; ok && kind.config.singleton {
+		owner := SelectOldestMember(ActivationDetails{
+			Members: a.membersForKind(name),
+			Kind:    name,
+			ID:      singletonID,
+		})
+		if owner != nil {
+			switch owner.Host {
+			case x.Address:
+				return x, y
+			case y.Address:
+				return y, x
+			}
+		}
+	}
+	wx := xxh3.HashString(x.Address + "/" + x.ID)
+	wy := xxh3.HashString(y.Address + "/" + y.ID)
+	if wx > wy || (wx == wy && x.Address < y.Address) {
+		return x, y
+	}
+	return y, x
+}
@@ -0,0 +1,106 @@
+package cluster
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeRaceClusters starts n members that all have the player kind
+// registered and waits until they all see each other.
+func makeRaceClusters(t *testing.T, n int) []*Cluster {
+	bootstrapAddr := getRandomLocalhostAddr()
+	clusters := make([]*Cluster, n)
+	clusters[0] = makeCluster(t, bootstrapAddr, "A", "eu")
+	for i := 1; i < n; i++ {
+		clusters[i] = makeClusterWithBootstrap(t, getRandomLocalhostAddr(), fmt.Sprintf("%c", 'A'+i), "eu",
+			MemberAddr{ListenAddr: bootstrapAddr, ID: "A"})
+	}
+	for _, c := range clusters {
+		c.RegisterKind("player", NewPlayer, NewKindConfig())
+		c.Start()
+		t.Cleanup(c.Stop)
+	}
+	require.Eventually(t, func() bool {
+		for _, c := range clusters {
+			if len(c.Members()) != n {
+				return false
+			}
+		}
+		return true
+	}, time.Second*5, time.Millisecond*10)
+	return clusters
+}
+
+func TestActivationConflict(t *testing.T) {
+	const n = 4
+	clusters := makeRaceClusters(t, n)
+
+	var conflicts atomic.Int32
+	eventPID := clusters[0].Engine().SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(ActivationConflictEvent); ok {
+			conflicts.Add(1)
+		}
+	}, "event")
+	clusters[0].Engine().Subscribe(eventPID)
+	defer clusters[0].Engine().Unsubscribe(eventPID)
+
+	// Every member activates the same identity on itself at the same time.
+	var (
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+	)
+	for _, c := range clusters {
+		wg.Add(1)
+		go func(c *Cluster) {
+			defer wg.Done()
+			config := NewActivationConfig().
+				WithID("1").
+				WithSelectMemberFunc(func(ActivationDetails) *Member {
+					return c.Member()
+				})
+			<-start
+			c.Activate("player", config)
+		}(c)
+	}
+	close(start)
+	wg.Wait()
+
+	// All members agree on the same activation and only that one is alive.
+	require.Eventually(t, func() bool {
+		winner := clusters[0].GetActiveByID("player/1")
+		if winner == nil {
+			return false
+		}
+		alive := 0
+		for _, c := range clusters {
+			if pid := c.GetActiveByID("player/1"); pid == nil || !pid.Equals(winner) {
+				return false
+			}
+			if c.Engine().Registry.GetPID("player", "1") != nil {
+				alive++
+			}
+		}
+		return alive == 1
+	}, time.Second*5, time.Millisecond*10)
+	assert.Greater(t, conflicts.Load(), int32(0))
+}
+
+func TestConflictWinnerIsDeterministic(t *testing.T) {
+	var (
+		a = &Agent{}
+		x = actor.NewPID("127.0.0.1:3000", "player/1")
+		y = actor.NewPID("127.0.0.1:3001", "player/1")
+	)
+	w1, l1 := a.conflictWinner(x, y)
+	w2, l2 := a.conflictWinner(y, x)
+	assert.True(t, w1.Equals(w2))
+	assert.True(t, l1.Equals(l2))
+	assert.False(t, w1.Equals(l1))
+}
@@ -2,21 +2,28 @@ package cluster
 
 import (
 	fmt "fmt"
-	"log"
 	"log/slog"
 	"net"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/khulnasoft/goactors/actor"
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/api/watch"
+	"github.com/khulnasoft/goactors/actor"
 )
 
 const (
 	registerTTL = 4 * time.Second
 	refreshTTL  = 2 * time.Second
 	removeTTL   = 8 * time.Second
+
+	// the prefix of the service meta keys that hold the member labels.
+	consulLabelPrefix = "label_"
+	// the limits Consul puts on the service meta.
+	consulMaxMetaPairs    = 64
+	consulMaxMetaKeyLen   = 128
+	consulMaxMetaValueLen = 512
 )
 
 type ConsulProviderConfig struct {
@@ -38,6 +45,7 @@ type ConsulProvider struct {
 	config    ConsulProviderConfig
 	cluster   *Cluster
 	client    *api.Client
+	clientErr error
 	id        string
 	prevIndex watch.BlockingParamVal
 	quitch    chan struct{}
@@ -47,8 +55,13 @@ func (p *ConsulProvider) Receive(c *actor.Context) {
 	switch msg := c.Message().(type) {
 	case actor.Started:
 		_ = msg
+		if p.clientErr != nil {
+			p.failed(p.clientErr)
+			return
+		}
 		if err := p.registerService(); err != nil {
-			panic(err)
+			p.failed(err)
+			return
 		}
 		go p.watch()
 		go p.updateTTL()
@@ -57,19 +70,25 @@ func (p *ConsulProvider) Receive(c *actor.Context) {
 	}
 }
 
+func (p *ConsulProvider) failed(err error) {
+	slog.Error("[CLUSTER] consul provider", "err", err)
+	p.cluster.engine.BroadcastEvent(ProviderErrorEvent{
+		Provider: "ConsulProvider",
+		Err:      err,
+	})
+}
+
 func NewConsulProvider(config ConsulProviderConfig) Producer {
 	client, err := api.NewClient(&api.Config{
 		Address: config.address,
 	})
-	if err != nil {
-		log.Fatal(err)
-	}
 	return func(c *Cluster) actor.Producer {
 		return func() actor.Receiver {
 			return &ConsulProvider{
 				config:    config,
 				prevIndex: watch.WaitIndexVal(0),
 				client:    client,
+				clientErr: err,
 				cluster:   c,
 				quitch:    make(chan struct{}),
 			}
@@ -78,13 +97,11 @@ func NewConsulProvider(config ConsulProviderConfig) Producer {
 }
 
 func (p *ConsulProvider) registerService() error {
-	var (
-		config      = p.cluster.config
-		meta        = map[string]string{}
-		taggedAddrs = map[string]api.ServiceAddress{}
-	)
-
-	meta["name"] = config.id
+	meta, err := consulMeta(p.cluster.Member())
+	if err != nil {
+		return err
+	}
+	taggedAddrs := map[string]api.ServiceAddress{}
 	check := &api.AgentServiceCheck{
 		DeregisterCriticalServiceAfter: removeTTL.String(),
 		TLSSkipVerify:                  true,
@@ -132,7 +149,7 @@ func (p *ConsulProvider) watch() {
 		Address: p.config.address,
 	}
 	if err := plan.RunWithConfig(config.Address, config); err != nil {
-		panic(err)
+		p.failed(err)
 	}
 }
 
@@ -146,10 +163,16 @@ func (p *ConsulProvider) onUpdate(index watch.BlockingParamVal, msg any) {
 	for _, entry := range entries {
 		if len(entry.Checks) > 0 && entry.Checks.AggregatedStatus() == api.HealthPassing {
 			port := strconv.Itoa(entry.Service.Port)
+			started, _ := strconv.ParseInt(entry.Service.Meta["started"], 10, 64)
+			capacity, _ := strconv.ParseUint(entry.Service.Meta["capacity"], 10, 32)
 			member := &Member{
-				ID:    entry.Service.Meta["name"],
-				Host:  entry.Service.Address + ":" + port,
-				Kinds: entry.Service.Tags,
+				ID:       entry.Service.Meta["name"],
+				Host:     entry.Service.Address + ":" + port,
+				Region:   entry.Service.Meta["region"],
+				Kinds:    entry.Service.Tags,
+				Started:  started,
+				Labels:   consulLabels(entry.Service.Meta),
+				Capacity: uint32(capacity),
 			}
 			members = append(members, member)
 		}
@@ -183,3 +206,73 @@ func (p *ConsulProvider) memberID() string {
 	host, port, _ := net.SplitHostPort(config.listenAddr)
 	return fmt.Sprintf("%s.%s:%s", config.id, host, port)
 }
+
+// consulMeta returns the service meta the given member is registered with.
+// Consul only accepts meta keys made of letters, digits, dashes and
+// underscores, so the other characters of the label keys are escaped. An
+// error is returned when the meta exceeds the limits of Consul.
+func consulMeta(member *Member) (map[string]string, error) {
+	meta := map[string]string{
+		"name":     member.ID,
+		"started":  strconv.FormatInt(member.Started, 10),
+		"region":   member.Region,
+		"capacity": strconv.FormatUint(uint64(member.Capacity), 10),
+	}
+	for key, value := range member.Labels {
+		meta[consulLabelPrefix+escapeConsulKey(key)] = value
+	}
+	if len(meta) > consulMaxMetaPairs {
+		return nil, fmt.Errorf("consul service meta has %d pairs, at most %d are allowed", len(meta), consulMaxMetaPairs)
+	}
+	for key, value := range meta {
+		if len(key) > consulMaxMetaKeyLen {
+			return nil, fmt.Errorf("consul service meta key %q is longer than %d characters", key, consulMaxMetaKeyLen)
+		}
+		if len(value) > consulMaxMetaValueLen {
+			return nil, fmt.Errorf("consul service meta value of %q is longer than %d characters", key, consulMaxMetaValueLen)
+		}
+	}
+	return meta, nil
+}
+
+// consulLabels returns the member labels stored in the given service meta.
+func consulLabels(meta map[string]string) map[string]string {
+	labels := make(map[string]string)
+	for key, value := range meta {
+		if label, ok := strings.CutPrefix(key, consulLabelPrefix); ok {
+			labels[unescapeConsulKey(label)] = value
+		}
+	}
+	return labels
+}
+
+// escapeConsulKey replaces the characters Consul does not accept in meta
+// keys, and the underscore used for escaping, by an underscore followed by
+// their hex encoding.
+func escapeConsulKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "_%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func unescapeConsulKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		if key[i] == '_' && i+2 < len(key) {
+			if c, err := strconv.ParseUint(key[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(key[i])
+	}
+	return b.String()
+}
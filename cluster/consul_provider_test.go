@@ -0,0 +1,68 @@
+package cluster
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var consulMetaKey = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func TestConsulMeta(t *testing.T) {
+	member := &Member{
+		ID:       "A",
+		Region:   "eu",
+		Started:  42,
+		Capacity: 2,
+		Labels: map[string]string{
+			"zone":                        "a",
+			"topology.kubernetes.io/zone": "eu-west-1a",
+			"disk_type":                   "ssd",
+		},
+	}
+	meta, err := consulMeta(member)
+	require.NoError(t, err)
+	for key := range meta {
+		assert.Regexp(t, consulMetaKey, key)
+	}
+	assert.Equal(t, "A", meta["name"])
+	assert.Equal(t, "eu", meta["region"])
+	assert.Equal(t, "42", meta["started"])
+	assert.Equal(t, "2", meta["capacity"])
+	assert.Equal(t, "a", meta["label_zone"])
+	assert.Equal(t, member.Labels, consulLabels(meta))
+}
+
+func TestConsulMetaLimits(t *testing.T) {
+	labels := make(map[string]string)
+	for i := range consulMaxMetaPairs {
+		labels[fmt.Sprintf("label-%d", i)] = "x"
+	}
+	_, err := consulMeta(&Member{ID: "A", Labels: labels})
+	assert.Error(t, err)
+
+	_, err = consulMeta(&Member{ID: "A", Labels: map[string]string{strings.Repeat("k", consulMaxMetaKeyLen): "x"}})
+	assert.Error(t, err)
+
+	_, err = consulMeta(&Member{ID: "A", Labels: map[string]string{"k": strings.Repeat("v", consulMaxMetaValueLen+1)}})
+	assert.Error(t, err)
+}
+
+func TestConsulLabels(t *testing.T) {
+	meta := map[string]string{
+		"name":              "A",
+		"region":            "eu",
+		"label_zone":        "a",
+		"label_disk_5Ftype": "ssd",
+		"label_a_2Eb_2Fc":   "d",
+	}
+	assert.Equal(t, map[string]string{
+		"zone":      "a",
+		"disk_type": "ssd",
+		"a.b/c":     "d",
+	}, consulLabels(meta))
+}
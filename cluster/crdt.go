@@ -0,0 +1,284 @@
+package cluster
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ReplicatedData is a conflict-free replicated data type. Each member holds a
+// replica of the data, the replicas converge to the same value by merging
+// them. The data types are GCounter, PNCounter, ORSet, LWWMap and Flag.
+type ReplicatedData interface {
+	proto.Message
+	// merge merges the given replica of the same type into the data.
+	merge(other ReplicatedData)
+}
+
+// Writer identifies the member that modifies replicated data, the replicas
+// of the members only converge when each member writes on its own behalf. It's
+// handed to the modify function of Cluster.UpdateData by the replicator.
+type Writer struct {
+	memberID string
+}
+
+// id returns the ID of the member, it panics when the data is modified
+// outside of Cluster.UpdateData.
+func (w Writer) id() string {
+	if len(w.memberID) == 0 {
+		panic("replicated data modified without the writer of UpdateData")
+	}
+	return w.memberID
+}
+
+// wrapData puts the given data in an envelope, so it can be sent to other
+// members.
+func wrapData(data ReplicatedData) *DataEnvelope {
+	switch d := data.(type) {
+	case *GCounter:
+		return &DataEnvelope{Data: &DataEnvelope_GCounter{GCounter: d}}
+	case *PNCounter:
+		return &DataEnvelope{Data: &DataEnvelope_PnCounter{PnCounter: d}}
+	case *ORSet:
+		return &DataEnvelope{Data: &DataEnvelope_OrSet{OrSet: d}}
+	case *LWWMap:
+		return &DataEnvelope{Data: &DataEnvelope_LwwMap{LwwMap: d}}
+	case *Flag:
+		return &DataEnvelope{Data: &DataEnvelope_Flag{Flag: d}}
+	}
+	panic(fmt.Sprintf("unsupported replicated data %T", data))
+}
+
+// unwrap returns the data in the envelope, or nil if the envelope is empty.
+func (e *DataEnvelope) unwrap() ReplicatedData {
+	switch d := e.GetData().(type) {
+	case *DataEnvelope_GCounter:
+		return d.GCounter
+	case *DataEnvelope_PnCounter:
+		return d.PnCounter
+	case *DataEnvelope_OrSet:
+		return d.OrSet
+	case *DataEnvelope_LwwMap:
+		return d.LwwMap
+	case *DataEnvelope_Flag:
+		return d.Flag
+	}
+	return nil
+}
+
+// Increment increments the count of the writer by n.
+func (c *GCounter) Increment(w Writer, n uint64) {
+	c.add(w.id(), n)
+}
+
+func (c *GCounter) add(memberID string, n uint64) {
+	if c.Counts == nil {
+		c.Counts = make(map[string]uint64)
+	}
+	c.Counts[memberID] += n
+}
+
+// Value returns the value of the counter.
+func (c *GCounter) Value() uint64 {
+	var value uint64
+	for _, n := range c.GetCounts() {
+		value += n
+	}
+	return value
+}
+
+func (c *GCounter) merge(other ReplicatedData) {
+	for id, n := range other.(*GCounter).GetCounts() {
+		if n > c.Counts[id] {
+			c.add(id, n-c.Counts[id])
+		}
+	}
+}
+
+// Increment increments the counter by n on behalf of the writer, a negative n
+// decrements it.
+func (c *PNCounter) Increment(w Writer, n int64) {
+	if c.Increments == nil {
+		c.Increments = &GCounter{}
+	}
+	if c.Decrements == nil {
+		c.Decrements = &GCounter{}
+	}
+	if n >= 0 {
+		c.Increments.Increment(w, uint64(n))
+	} else {
+		c.Decrements.Increment(w, uint64(-n))
+	}
+}
+
+// Value returns the value of the counter.
+func (c *PNCounter) Value() int64 {
+	return int64(c.GetIncrements().Value()) - int64(c.GetDecrements().Value())
+}
+
+func (c *PNCounter) merge(other ReplicatedData) {
+	o := other.(*PNCounter)
+	if c.Increments == nil {
+		c.Increments = &GCounter{}
+	}
+	if c.Decrements == nil {
+		c.Decrements = &GCounter{}
+	}
+	c.Increments.merge(o.GetIncrements())
+	c.Decrements.merge(o.GetDecrements())
+}
+
+// Add adds the element to the set on behalf of the writer.
+func (s *ORSet) Add(w Writer, element string) {
+	memberID := w.id()
+	if s.Dots == nil {
+		s.Dots = make(map[string]*ORSetDots)
+	}
+	if s.Context == nil {
+		s.Context = make(map[string]uint64)
+	}
+	s.Context[memberID]++
+	// The new add replaces the adds we observed.
+	s.Dots[element] = &ORSetDots{Dots: map[string]uint64{memberID: s.Context[memberID]}}
+}
+
+// Remove removes the element from the set. Adds of the element on other
+// members that were not observed yet win over the remove.
+func (s *ORSet) Remove(element string) {
+	delete(s.Dots, element)
+}
+
+// Contains returns true if the element is in the set.
+func (s *ORSet) Contains(element string) bool {
+	_, ok := s.Dots[element]
+	return ok
+}
+
+// Elements returns the elements of the set in sorted order.
+func (s *ORSet) Elements() []string {
+	elements := make([]string, 0, len(s.Dots))
+	for element := range s.Dots {
+		elements = append(elements, element)
+	}
+	slices.Sort(elements)
+	return elements
+}
+
+// Len returns the number of elements in the set.
+func (s *ORSet) Len() int {
+	return len(s.Dots)
+}
+
+func (s *ORSet) merge(other ReplicatedData) {
+	o := other.(*ORSet)
+	merged := make(map[string]*ORSetDots)
+	for element := range s.Dots {
+		merged[element] = &ORSetDots{}
+	}
+	for element := range o.Dots {
+		merged[element] = &ORSetDots{}
+	}
+	for element, dots := range merged {
+		var (
+			ours   = s.Dots[element].GetDots()
+			theirs = o.Dots[element].GetDots()
+			keep   = make(map[string]uint64)
+		)
+		// A dot only one of the replicas has is kept unless the other one
+		// has seen it, which means the element was removed there.
+		for id, n := range ours {
+			if theirs[id] == n || n > o.Context[id] {
+				keep[id] = max(n, keep[id])
+			}
+		}
+		for id, n := range theirs {
+			if ours[id] == n || n > s.Context[id] {
+				keep[id] = max(n, keep[id])
+			}
+		}
+		if len(keep) == 0 {
+			delete(merged, element)
+			continue
+		}
+		dots.Dots = keep
+	}
+	s.Dots = merged
+	if s.Context == nil {
+		s.Context = make(map[string]uint64)
+	}
+	for id, n := range o.Context {
+		s.Context[id] = max(s.Context[id], n)
+	}
+}
+
+// Put sets the value of the key on behalf of the writer.
+func (m *LWWMap) Put(w Writer, key string, value []byte) {
+	m.write(w, key, &LWWRegister{Value: value})
+}
+
+// Delete deletes the key on behalf of the writer.
+func (m *LWWMap) Delete(w Writer, key string) {
+	m.write(w, key, &LWWRegister{Deleted: true})
+}
+
+func (m *LWWMap) write(w Writer, key string, reg *LWWRegister) {
+	reg.MemberID = w.id()
+	if m.Registers == nil {
+		m.Registers = make(map[string]*LWWRegister)
+	}
+	// Make sure the write wins over the one we have, even if the clocks of
+	// the members are not in sync.
+	reg.Timestamp = max(time.Now().UnixNano(), m.Registers[key].GetTimestamp()+1)
+	m.Registers[key] = reg
+}
+
+// Get returns the value of the key.
+func (m *LWWMap) Get(key string) ([]byte, bool) {
+	reg, ok := m.Registers[key]
+	if !ok || reg.Deleted {
+		return nil, false
+	}
+	return reg.Value, true
+}
+
+// Keys returns the keys of the map in sorted order.
+func (m *LWWMap) Keys() []string {
+	keys := make([]string, 0, len(m.Registers))
+	for key, reg := range m.Registers {
+		if !reg.Deleted {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (m *LWWMap) merge(other ReplicatedData) {
+	for key, theirs := range other.(*LWWMap).Registers {
+		ours, ok := m.Registers[key]
+		if ok && (ours.Timestamp > theirs.Timestamp ||
+			(ours.Timestamp == theirs.Timestamp && ours.MemberID >= theirs.MemberID)) {
+			continue
+		}
+		if m.Registers == nil {
+			m.Registers = make(map[string]*LWWRegister)
+		}
+		m.Registers[key] = proto.Clone(theirs).(*LWWRegister)
+	}
+}
+
+// SwitchOn switches the flag on, it can't be switched off again.
+func (f *Flag) SwitchOn() {
+	f.On = true
+}
+
+// Enabled returns true if the flag is switched on.
+func (f *Flag) Enabled() bool {
+	return f.On
+}
+
+func (f *Flag) merge(other ReplicatedData) {
+	f.On = f.On || other.(*Flag).On
+}
@@ -0,0 +1,123 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+// The writers of the replicas of two members.
+var (
+	writerA = Writer{memberID: "A"}
+	writerB = Writer{memberID: "B"}
+)
+
+// mergeBoth merges the replicas into each other.
+func mergeBoth(a, b ReplicatedData) {
+	other := proto.Clone(b).(ReplicatedData)
+	b.merge(a)
+	a.merge(other)
+}
+
+func TestGCounter(t *testing.T) {
+	a, b := &GCounter{}, &GCounter{}
+	a.Increment(writerA, 2)
+	b.Increment(writerB, 3)
+	b.Increment(writerA, 1)
+	mergeBoth(a, b)
+	assert.Equal(t, uint64(5), a.Value())
+	assert.Equal(t, uint64(5), b.Value())
+
+	// Merging is idempotent.
+	a.merge(b)
+	assert.Equal(t, uint64(5), a.Value())
+}
+
+func TestPNCounter(t *testing.T) {
+	a, b := &PNCounter{}, &PNCounter{}
+	a.Increment(writerA, 5)
+	b.Increment(writerB, -7)
+	mergeBoth(a, b)
+	assert.Equal(t, int64(-2), a.Value())
+	assert.Equal(t, int64(-2), b.Value())
+	assert.Equal(t, int64(0), (&PNCounter{}).Value())
+}
+
+func TestORSet(t *testing.T) {
+	a, b := &ORSet{}, &ORSet{}
+	a.Add(writerA, "x")
+	a.Add(writerA, "y")
+	b.merge(a)
+	assert.Equal(t, []string{"x", "y"}, b.Elements())
+
+	// A remove of an observed add wins.
+	b.Remove("x")
+	mergeBoth(a, b)
+	assert.Equal(t, []string{"y"}, a.Elements())
+	assert.Equal(t, []string{"y"}, b.Elements())
+
+	// A concurrent add wins over a remove.
+	a.Remove("y")
+	b.Add(writerB, "y")
+	mergeBoth(a, b)
+	assert.True(t, a.Contains("y"))
+	assert.True(t, b.Contains("y"))
+	assert.Equal(t, 1, a.Len())
+
+	// Re-adding a removed element works.
+	a.Remove("y")
+	mergeBoth(a, b)
+	assert.False(t, b.Contains("y"))
+	b.Add(writerB, "y")
+	mergeBoth(a, b)
+	assert.True(t, a.Contains("y"))
+}
+
+func TestLWWMap(t *testing.T) {
+	a, b := &LWWMap{}, &LWWMap{}
+	a.Put(writerA, "k", []byte("a"))
+	b.Put(writerB, "k", []byte("b"))
+	mergeBoth(a, b)
+	va, _ := a.Get("k")
+	vb, _ := b.Get("k")
+	assert.Equal(t, []byte("b"), va)
+	assert.Equal(t, va, vb)
+
+	// The last write wins, even if it's a delete.
+	a.Delete(writerA, "k")
+	a.Put(writerA, "other", []byte("1"))
+	mergeBoth(a, b)
+	_, ok := b.Get("k")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"other"}, b.Keys())
+}
+
+func TestFlag(t *testing.T) {
+	a, b := &Flag{}, &Flag{}
+	mergeBoth(a, b)
+	assert.False(t, a.Enabled())
+	b.SwitchOn()
+	mergeBoth(a, b)
+	assert.True(t, a.Enabled())
+}
+
+func TestDataEnvelope(t *testing.T) {
+	for _, data := range []ReplicatedData{&GCounter{}, &PNCounter{}, &ORSet{}, &LWWMap{}, &Flag{}} {
+		b, err := wrapData(data).MarshalVT()
+		assert.NoError(t, err)
+		env := &DataEnvelope{}
+		assert.NoError(t, env.UnmarshalVT(b))
+		assert.Equal(t, proto.MessageName(data), proto.MessageName(env.unwrap()))
+	}
+	assert.Nil(t, (&DataEnvelope{}).unwrap())
+}
+
+func TestWriterRequired(t *testing.T) {
+	assert.Panics(t, func() {
+		(&GCounter{}).Increment(Writer{}, 1)
+	})
+	assert.Panics(t, func() {
+		(&ORSet{}).Add(Writer{}, "x")
+	})
+}
@@ -0,0 +1,218 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
+
+// handshakeID is the ID of the well-known endpoint of the provider that
+// accepts handshakes of members that only know our address.
+const handshakeID = "handshake"
+
+// Discovery finds the members the SelfManaged provider will handshake with.
+// A Discovery is started by a single provider.
+type Discovery interface {
+	// Start starts discovering members and reports them to the given
+	// notifier. Start must not block, the discovery stops when the context
+	// is cancelled.
+	Start(ctx context.Context, self *Member, notifier DiscoveryNotifier) error
+	// Stop releases the resources of the discovery.
+	Stop() error
+}
+
+// DiscoveryNotifier is handed to a Discovery to report its findings. It is
+// safe to use from any goroutine.
+type DiscoveryNotifier interface {
+	// Found reports a member that was discovered. The ID of the member is
+	// optional, members that are already part of the cluster are ignored.
+	Found(member MemberAddr)
+	// Failed reports an error of the discovery, it's logged and broadcasted
+	// as a DiscoveryErrorEvent.
+	Failed(err error)
+}
+
+type discovered struct {
+	member MemberAddr
+}
+
+type discoveryNotifier struct {
+	engine    *actor.Engine
+	pid       *actor.PID
+	discovery string
+}
+
+func newDiscoveryNotifier(e *actor.Engine, pid *actor.PID, d Discovery) discoveryNotifier {
+	return discoveryNotifier{
+		engine:    e,
+		pid:       pid,
+		discovery: reflect.TypeOf(d).String(),
+	}
+}
+
+func (n discoveryNotifier) Found(member MemberAddr) {
+	n.engine.Send(n.pid, discovered{member: member})
+}
+
+func (n discoveryNotifier) Failed(err error) {
+	slog.Error("[CLUSTER] discovery failed", "discovery", n.discovery, "err", err)
+	n.engine.BroadcastEvent(DiscoveryErrorEvent{
+		Discovery: n.discovery,
+		Err:       err,
+	})
+}
+
+// memberAddrToProviderPID returns the PID of the provider of the given
+// member, or its handshake endpoint when we don't know the ID of the member.
+func memberAddrToProviderPID(member MemberAddr) *actor.PID {
+	if len(member.ID) == 0 {
+		return actor.NewPID(member.ListenAddr, "provider/"+handshakeID)
+	}
+	return actor.NewPID(member.ListenAddr, "provider/"+member.ID)
+}
+
+// spawnHandshakeEndpoint spawns the well-known endpoint that forwards the
+// handshakes it receives to the given provider.
+func spawnHandshakeEndpoint(e *actor.Engine, provider *actor.PID) *actor.PID {
+	return e.SpawnFunc(func(c *actor.Context) {
+		if hs, ok := c.Message().(*Handshake); ok {
+			c.Engine().SendWithSender(provider, hs, c.Sender())
+		}
+	}, "provider", actor.WithID(handshakeID))
+}
+
+// StaticDiscovery reports a fixed list of members.
+type StaticDiscovery struct {
+	members []MemberAddr
+}
+
+// NewStaticDiscovery returns a Discovery that reports the given members once.
+func NewStaticDiscovery(members ...MemberAddr) *StaticDiscovery {
+	return &StaticDiscovery{members: members}
+}
+
+func (d *StaticDiscovery) Start(_ context.Context, _ *Member, notifier DiscoveryNotifier) error {
+	for _, member := range d.members {
+		notifier.Found(member)
+	}
+	return nil
+}
+
+func (d *StaticDiscovery) Stop() error { return nil }
+
+// FileDiscovery reports the members listed in a file and watches the file
+// for changes. An error reading the file is reported once, until it changes,
+// and the recovery is logged.
+//
+// Each line of the file holds the listen address of a member, optionally
+// followed by its ID. Empty lines and lines starting with # are ignored.
+//
+//	# members
+//	10.0.0.1:3000 A
+//	10.0.0.2:3000
+type FileDiscovery struct {
+	path     string
+	interval time.Duration
+}
+
+// NewFileDiscovery returns a Discovery that reads the members from the file
+// at the given path, and checks the file for changes every interval.
+func NewFileDiscovery(path string, interval time.Duration) *FileDiscovery {
+	return &FileDiscovery{
+		path:     path,
+		interval: interval,
+	}
+}
+
+func (d *FileDiscovery) Start(ctx context.Context, _ *Member, notifier DiscoveryNotifier) error {
+	var (
+		modTime time.Time
+		size    int64
+		// the last error reported, the file is checked every interval.
+		lastErr string
+	)
+	fail := func(err error) {
+		if err.Error() != lastErr {
+			lastErr = err.Error()
+			notifier.Failed(err)
+		}
+	}
+	check := func() {
+		info, err := os.Stat(d.path)
+		if err != nil {
+			// Read the file again once it's back.
+			modTime, size = time.Time{}, 0
+			fail(err)
+			return
+		}
+		if info.ModTime().Equal(modTime) && info.Size() == size {
+			return
+		}
+		modTime, size = info.ModTime(), info.Size()
+		members, err := readMembersFile(d.path)
+		if err != nil {
+			fail(err)
+			return
+		}
+		if len(lastErr) > 0 {
+			lastErr = ""
+			slog.Info("[CLUSTER] discovery recovered", "path", d.path)
+		}
+		for _, member := range members {
+			notifier.Found(member)
+		}
+	}
+	check()
+	go func() {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				check()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (d *FileDiscovery) Stop() error { return nil }
+
+func readMembersFile(path string) ([]MemberAddr, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		members []MemberAddr
+		scanner = bufio.NewScanner(bytes.NewReader(b))
+		n       = 0
+	)
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("%s:%d: expected an address and an optional ID", path, n)
+		}
+		member := MemberAddr{ListenAddr: fields[0]}
+		if len(fields) == 2 {
+			member.ID = fields[1]
+		}
+		members = append(members, member)
+	}
+	return members, scanner.Err()
+}
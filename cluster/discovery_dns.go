@@ -0,0 +1,137 @@
+package cluster
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DNSResolver resolves the records of the DNSDiscovery. *net.Resolver
+// implements it.
+type DNSResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// DNSDiscoveryConfig holds the configuration of the DNSDiscovery.
+type DNSDiscoveryConfig struct {
+	name     string
+	service  string
+	proto    string
+	port     int
+	interval time.Duration
+	resolver DNSResolver
+}
+
+// NewDNSDiscoveryConfig returns a DNSDiscoveryConfig that resolves the A and
+// AAAA records of the given name, for example the name of a headless
+// Kubernetes service.
+func NewDNSDiscoveryConfig(name string) DNSDiscoveryConfig {
+	return DNSDiscoveryConfig{
+		name:     name,
+		interval: time.Second * 10,
+		resolver: net.DefaultResolver,
+	}
+}
+
+// WithSRV will resolve the SRV records of the given service and protocol
+// instead of the A records. The port of the members is taken from the records.
+//
+//	NewDNSDiscoveryConfig("example.com").WithSRV("goactors", "tcp")
+//	// resolves _goactors._tcp.example.com
+func (config DNSDiscoveryConfig) WithSRV(service, proto string) DNSDiscoveryConfig {
+	config.service = service
+	config.proto = proto
+	return config
+}
+
+// WithPort set's the port the members listen on when resolving A records.
+func (config DNSDiscoveryConfig) WithPort(port int) DNSDiscoveryConfig {
+	config.port = port
+	return config
+}
+
+// WithInterval set's the interval at which the records are resolved.
+//
+// Defaults to 10 seconds.
+func (config DNSDiscoveryConfig) WithInterval(d time.Duration) DNSDiscoveryConfig {
+	config.interval = d
+	return config
+}
+
+// WithResolver set's the resolver used to resolve the records.
+//
+// Defaults to net.DefaultResolver.
+func (config DNSDiscoveryConfig) WithResolver(r DNSResolver) DNSDiscoveryConfig {
+	config.resolver = r
+	return config
+}
+
+// DNSDiscovery periodically resolves DNS records and reports the addresses as
+// members. The IDs of the members are unknown, the handshake is sent to their
+// handshake endpoint.
+type DNSDiscovery struct {
+	config DNSDiscoveryConfig
+}
+
+// NewDNSDiscovery returns a Discovery over DNS.
+func NewDNSDiscovery(config DNSDiscoveryConfig) *DNSDiscovery {
+	return &DNSDiscovery{config: config}
+}
+
+func (d *DNSDiscovery) Start(ctx context.Context, self *Member, notifier DiscoveryNotifier) error {
+	resolve := func() {
+		addrs, err := d.resolve(ctx)
+		if err != nil {
+			notifier.Failed(err)
+			return
+		}
+		for _, addr := range addrs {
+			if addr != self.Host {
+				notifier.Found(MemberAddr{ListenAddr: addr})
+			}
+		}
+	}
+	go func() {
+		resolve()
+		ticker := time.NewTicker(d.config.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				resolve()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (d *DNSDiscovery) Stop() error { return nil }
+
+func (d *DNSDiscovery) resolve(ctx context.Context) ([]string, error) {
+	if len(d.config.service) > 0 {
+		_, records, err := d.config.resolver.LookupSRV(ctx, d.config.service, d.config.proto, d.config.name)
+		if err != nil {
+			return nil, err
+		}
+		addrs := make([]string, len(records))
+		for i, record := range records {
+			host := strings.TrimSuffix(record.Target, ".")
+			addrs[i] = net.JoinHostPort(host, strconv.Itoa(int(record.Port)))
+		}
+		return addrs, nil
+	}
+	hosts, err := d.config.resolver.LookupHost(ctx, d.config.name)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, len(hosts))
+	for i, host := range hosts {
+		addrs[i] = net.JoinHostPort(host, strconv.Itoa(d.config.port))
+	}
+	return addrs, nil
+}
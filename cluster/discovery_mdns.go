@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+
+	"github.com/grandcat/zeroconf"
+)
+
+// MDNSDiscovery announces the member over multicast DNS and reports the
+// other members announcing themselves on the local network.
+type MDNSDiscovery struct {
+	announcer *zeroconf.Server
+}
+
+// NewMDNSDiscovery returns a Discovery over multicast DNS.
+func NewMDNSDiscovery() *MDNSDiscovery {
+	return &MDNSDiscovery{}
+}
+
+func (d *MDNSDiscovery) Start(ctx context.Context, self *Member, notifier DiscoveryNotifier) error {
+	resolver, err := zeroconf.NewResolver()
+	if err != nil {
+		return err
+	}
+	host, portstr, err := net.SplitHostPort(self.Host)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portstr)
+	if err != nil {
+		return err
+	}
+	server, err := zeroconf.RegisterProxy(
+		self.ID,
+		serviceName,
+		domain,
+		port,
+		fmt.Sprintf("member_%s", self.ID),
+		[]string{host},
+		[]string{"txtv=0", "lo=1", "la=2"}, nil)
+	if err != nil {
+		return err
+	}
+	d.announcer = server
+
+	entries := make(chan *zeroconf.ServiceEntry)
+	go func(results <-chan *zeroconf.ServiceEntry) {
+		for entry := range results {
+			if entry.Instance == self.ID || len(entry.AddrIPv4) == 0 {
+				continue
+			}
+			notifier.Found(MemberAddr{
+				ListenAddr: fmt.Sprintf("%s:%d", entry.AddrIPv4[0], entry.Port),
+				ID:         entry.Instance,
+			})
+		}
+		slog.Debug("[CLUSTER] stopping discovery", "id", self.ID)
+	}(entries)
+
+	return resolver.Browse(ctx, serviceName, domain, entries)
+}
+
+func (d *MDNSDiscovery) Stop() error {
+	if d.announcer != nil {
+		d.announcer.Shutdown()
+		d.announcer = nil
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeDiscoveryCluster(t *testing.T, id string, discovery ...Discovery) *Cluster {
+	config := NewSelfManagedConfig().WithDiscovery(discovery...)
+	c, err := New(NewConfig().
+		WithID(id).
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithProvider(NewSelfManagedProvider(config)))
+	require.NoError(t, err)
+	return c
+}
+
+func TestReadMembersFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "members")
+	content := "# members\n127.0.0.1:3000 A\n\n127.0.0.1:3001\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	members, err := readMembersFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []MemberAddr{
+		{ListenAddr: "127.0.0.1:3000", ID: "A"},
+		{ListenAddr: "127.0.0.1:3001"},
+	}, members)
+
+	require.NoError(t, os.WriteFile(path, []byte("127.0.0.1:3000 A B\n"), 0o644))
+	_, err = readMembersFile(path)
+	assert.Error(t, err)
+}
+
+func TestFileDiscovery(t *testing.T) {
+	c1 := makeDiscoveryCluster(t, "A")
+	c1.Start()
+	defer c1.Stop()
+
+	path := filepath.Join(t.TempDir(), "members")
+	require.NoError(t, os.WriteFile(path, nil, 0o644))
+	c2 := makeDiscoveryCluster(t, "B", NewFileDiscovery(path, time.Millisecond*10))
+	c2.Start()
+	defer c2.Stop()
+
+	// the member shows up when it's added to the file, without an ID.
+	require.NoError(t, os.WriteFile(path, []byte(c1.Address()+"\n"), 0o644))
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 2 && len(c2.Members()) == 2
+	}, time.Second*2, time.Millisecond*10)
+}
+
+// recordingNotifier sends what a discovery reports on its channels.
+type recordingNotifier struct {
+	found  chan MemberAddr
+	failed chan error
+}
+
+func (n recordingNotifier) Found(member MemberAddr) { n.found <- member }
+func (n recordingNotifier) Failed(err error)        { n.failed <- err }
+
+func TestFileDiscoveryReportsErrorOnce(t *testing.T) {
+	notifier := recordingNotifier{
+		found:  make(chan MemberAddr, 10),
+		failed: make(chan error, 10),
+	}
+	path := filepath.Join(t.TempDir(), "members")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, NewFileDiscovery(path, time.Millisecond*10).Start(ctx, nil, notifier))
+
+	// The missing file is checked several times, but reported once.
+	time.Sleep(time.Millisecond * 100)
+	assert.Len(t, notifier.failed, 1)
+
+	require.NoError(t, os.WriteFile(path, []byte("127.0.0.1:3000 A B\n"), 0o644))
+	require.Eventually(t, func() bool {
+		return len(notifier.failed) == 2
+	}, time.Second, time.Millisecond*10)
+	time.Sleep(time.Millisecond * 100)
+	assert.Len(t, notifier.failed, 2)
+
+	require.NoError(t, os.WriteFile(path, []byte("127.0.0.1:3000 A\n"), 0o644))
+	select {
+	case member := <-notifier.found:
+		assert.Equal(t, MemberAddr{ListenAddr: "127.0.0.1:3000", ID: "A"}, member)
+	case <-time.After(time.Second):
+		t.Fatal("member not found")
+	}
+	assert.Len(t, notifier.failed, 2)
+}
+
+type fakeResolver struct {
+	host string
+	port int
+}
+
+func (r fakeResolver) LookupSRV(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	if service != "goactors" || proto != "tcp" || name != "example.com" {
+		return "", nil, errors.New("no such host")
+	}
+	return "", []*net.SRV{{Target: r.host + ".", Port: uint16(r.port)}}, nil
+}
+
+func (r fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if host != "example.com" {
+		return nil, errors.New("no such host")
+	}
+	return []string{r.host}, nil
+}
+
+func TestDNSDiscovery(t *testing.T) {
+	c1 := makeDiscoveryCluster(t, "A")
+	c1.Start()
+	defer c1.Stop()
+
+	host, portstr, err := net.SplitHostPort(c1.Address())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portstr)
+	require.NoError(t, err)
+	resolver := fakeResolver{host: host, port: port}
+
+	srv := NewDNSDiscoveryConfig("example.com").
+		WithSRV("goactors", "tcp").
+		WithResolver(resolver).
+		WithInterval(time.Millisecond * 10)
+	c2 := makeDiscoveryCluster(t, "B", NewDNSDiscovery(srv))
+	c2.Start()
+	defer c2.Stop()
+
+	a := NewDNSDiscoveryConfig("example.com").
+		WithPort(port).
+		WithResolver(resolver).
+		WithInterval(time.Millisecond * 10)
+	c3 := makeDiscoveryCluster(t, "C", NewDNSDiscovery(a))
+	c3.Start()
+	defer c3.Stop()
+
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 3
+	}, time.Second*2, time.Millisecond*10)
+}
+
+type failingDiscovery struct{}
+
+func (failingDiscovery) Start(context.Context, *Member, DiscoveryNotifier) error {
+	return errors.New("multicast not available")
+}
+
+func (failingDiscovery) Stop() error { return nil }
+
+func TestDiscoveryErrorEvent(t *testing.T) {
+	c := makeDiscoveryCluster(t, "A", failingDiscovery{})
+	events := make(chan DiscoveryErrorEvent, 1)
+	eventPID := c.Engine().SpawnFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(DiscoveryErrorEvent); ok {
+			events <- msg
+		}
+	}, "event")
+	c.Engine().Subscribe(eventPID)
+	defer c.Engine().Unsubscribe(eventPID)
+
+	c.Start()
+	defer c.Stop()
+
+	select {
+	case event := <-events:
+		assert.Equal(t, "cluster.failingDiscovery", event.Discovery)
+		assert.EqualError(t, event.Err, "multicast not available")
+	case <-time.After(time.Second):
+		t.Fatal("expected a DiscoveryErrorEvent")
+	}
+	// the member keeps working.
+	assert.Len(t, c.Members(), 1)
+}
+
+func TestSelfManagedConfigDefaultsToNoDiscovery(t *testing.T) {
+	assert.Empty(t, NewSelfManagedConfig().discovery)
+	config := NewSelfManagedConfig().WithDiscovery(NewMDNSDiscovery())
+	assert.Len(t, config.discovery, 1)
+}
@@ -23,3 +23,62 @@ type ActivationEvent struct {
 type DeactivationEvent struct {
 	PID *actor.PID
 }
+
+// ActivationConflictEvent gets triggered when the same identity turns out to
+// be activated on more than one member, for example when two members activated
+// it at the same time. Every member deterministically picks the same Winner,
+// the Loser is deactivated by the member hosting it.
+type ActivationConflictEvent struct {
+	Winner *actor.PID
+	Loser  *actor.PID
+}
+
+// MemberSuspectEvent gets triggered when the heartbeats of a member are overdue.
+// The member is still part of the cluster, it either becomes alive again
+// (MemberAliveEvent) or dead (MemberDeadEvent).
+type MemberSuspectEvent struct {
+	Member *Member
+	Phi    float64
+}
+
+// MemberAliveEvent gets triggered when a suspect member is sending heartbeats again.
+type MemberAliveEvent struct {
+	Member *Member
+}
+
+// MemberDeadEvent gets triggered when the failure detector considers a member
+// dead. The member is removed from the cluster, which triggers a MemberLeaveEvent.
+type MemberDeadEvent struct {
+	Member *Member
+	Phi    float64
+}
+
+// DiscoveryErrorEvent gets triggered when a Discovery of the SelfManaged
+// provider failed.
+type DiscoveryErrorEvent struct {
+	Discovery string
+	Err       error
+}
+
+// ProviderErrorEvent gets triggered when a cluster provider failed to talk to
+// the backend it discovers the members with.
+type ProviderErrorEvent struct {
+	Provider string
+	Err      error
+}
+
+// SplitBrainDecisionEvent gets triggered when the split brain resolver of the
+// SelfManaged provider decided the fate of our side of a network partition.
+// If our side did not survive, the member is downed.
+type SplitBrainDecisionEvent struct {
+	Reachable   []*Member
+	Unreachable []*Member
+	Survived    bool
+}
+
+// MigrationEvent gets triggered on the member an activation migrated away
+// from, once the activation runs on its new member.
+type MigrationEvent struct {
+	From *actor.PID
+	To   *actor.PID
+}
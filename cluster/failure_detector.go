@@ -0,0 +1,212 @@
+package cluster
+
+import (
+	"math"
+	"time"
+)
+
+// MemberStatus is the status the failure detector assigns to a member.
+type MemberStatus int
+
+const (
+	// MemberAlive is a member we receive heartbeats from.
+	MemberAlive MemberStatus = iota
+	// MemberSuspect is a member whose heartbeats are overdue. It is still part
+	// of the cluster and becomes alive again when a heartbeat arrives.
+	MemberSuspect
+	// MemberDead is a member that is removed from the cluster.
+	MemberDead
+)
+
+func (s MemberStatus) String() string {
+	switch s {
+	case MemberAlive:
+		return "alive"
+	case MemberSuspect:
+		return "suspect"
+	case MemberDead:
+		return "dead"
+	}
+	return "unknown"
+}
+
+// FailureDetectorConfig holds the configuration of the phi-accrual failure
+// detector the SelfManaged provider uses to decide if a member is still alive.
+//
+// Each member sends a heartbeat to all the other members every heartbeat
+// interval. The detector keeps the history of the arrival intervals and
+// computes phi, which expresses how unlikely it is that a heartbeat is still
+// on its way. A phi of 1 means there is a 10% chance we are wrong when we
+// consider the member gone, 2 means 1%, 3 means 0.1% and so on.
+type FailureDetectorConfig struct {
+	heartbeatInterval time.Duration
+	suspectThreshold  float64
+	deadThreshold     float64
+	windowSize        int
+	minStdDeviation   time.Duration
+	acceptablePause   time.Duration
+}
+
+// NewFailureDetectorConfig returns a FailureDetectorConfig initialized with
+// default values.
+func NewFailureDetectorConfig() FailureDetectorConfig {
+	return FailureDetectorConfig{
+		heartbeatInterval: memberPingInterval,
+		suspectThreshold:  5,
+		deadThreshold:     12,
+		windowSize:        100,
+		minStdDeviation:   time.Millisecond * 200,
+		acceptablePause:   memberPingInterval,
+	}
+}
+
+// WithHeartbeatInterval set's the interval at which heartbeats are sent to
+// the other members.
+//
+// Defaults to 2 seconds.
+func (config FailureDetectorConfig) WithHeartbeatInterval(d time.Duration) FailureDetectorConfig {
+	config.heartbeatInterval = d
+	return config
+}
+
+// WithSuspectThreshold set's the phi at which a member becomes suspect.
+//
+// Defaults to 5.
+func (config FailureDetectorConfig) WithSuspectThreshold(phi float64) FailureDetectorConfig {
+	config.suspectThreshold = phi
+	return config
+}
+
+// WithDeadThreshold set's the phi at which a member is considered dead and
+// removed from the cluster.
+//
+// Defaults to 12.
+func (config FailureDetectorConfig) WithDeadThreshold(phi float64) FailureDetectorConfig {
+	config.deadThreshold = phi
+	return config
+}
+
+// WithWindowSize set's the number of heartbeat intervals the detector keeps
+// to estimate the distribution of the arrival times.
+//
+// Defaults to 100.
+func (config FailureDetectorConfig) WithWindowSize(n int) FailureDetectorConfig {
+	config.windowSize = n
+	return config
+}
+
+// WithMinStdDeviation set's the minimum standard deviation of the arrival
+// times. Heartbeats that arrive very regularly would otherwise make the
+// detector overly sensitive to small delays.
+//
+// Defaults to 200 milliseconds.
+func (config FailureDetectorConfig) WithMinStdDeviation(d time.Duration) FailureDetectorConfig {
+	config.minStdDeviation = d
+	return config
+}
+
+// WithAcceptablePause set's the duration of pauses, like garbage collection
+// or network hiccups, that are tolerated on top of the expected interval.
+//
+// Defaults to 2 seconds.
+func (config FailureDetectorConfig) WithAcceptablePause(d time.Duration) FailureDetectorConfig {
+	config.acceptablePause = d
+	return config
+}
+
+// phiAccrual is a phi-accrual failure detector, as described in "The φ Accrual
+// Failure Detector" by Hayashibara et al. It is not safe for concurrent use,
+// it is owned by the provider actor.
+type phiAccrual struct {
+	config  FailureDetectorConfig
+	history map[string]*heartbeatHistory
+}
+
+func newPhiAccrual(config FailureDetectorConfig) *phiAccrual {
+	return &phiAccrual{
+		config:  config,
+		history: make(map[string]*heartbeatHistory),
+	}
+}
+
+// heartbeat records the arrival of a heartbeat of the given member.
+func (d *phiAccrual) heartbeat(id string, now time.Time) {
+	h, ok := d.history[id]
+	if !ok {
+		d.history[id] = newHeartbeatHistory(d.config, now)
+		return
+	}
+	h.add(now.Sub(h.last), d.config.windowSize)
+	h.last = now
+}
+
+// phi returns the suspicion level of the given member. Members we never
+// received a heartbeat from have a phi of 0.
+func (d *phiAccrual) phi(id string, now time.Time) float64 {
+	h, ok := d.history[id]
+	if !ok {
+		return 0
+	}
+	var (
+		elapsed = float64(now.Sub(h.last))
+		mean    = h.mean() + float64(d.config.acceptablePause)
+		std     = math.Max(h.stdDeviation(), float64(d.config.minStdDeviation))
+	)
+	return phi(elapsed, mean, std)
+}
+
+func (d *phiAccrual) remove(id string) {
+	delete(d.history, id)
+}
+
+// phi approximates -log10(1 - CDF(elapsed)) of the normal distribution with
+// the given mean and standard deviation, using the logistic approximation of
+// the cumulative distribution function.
+func phi(elapsed, mean, std float64) float64 {
+	y := (elapsed - mean) / std
+	e := math.Exp(-y * (1.5976 + 0.070566*y*y))
+	if elapsed > mean {
+		return -math.Log10(e / (1.0 + e))
+	}
+	return -math.Log10(1.0 - 1.0/(1.0+e))
+}
+
+type heartbeatHistory struct {
+	last      time.Time
+	intervals []float64
+	sum       float64
+	sumSq     float64
+}
+
+func newHeartbeatHistory(config FailureDetectorConfig, now time.Time) *heartbeatHistory {
+	h := &heartbeatHistory{last: now}
+	// Seed the history with the expected interval and a deviation of a
+	// quarter of it, so we can detect failures before the second heartbeat.
+	interval := float64(config.heartbeatInterval)
+	h.add(time.Duration(interval-interval/4), config.windowSize)
+	h.add(time.Duration(interval+interval/4), config.windowSize)
+	return h
+}
+
+func (h *heartbeatHistory) add(interval time.Duration, windowSize int) {
+	if len(h.intervals) >= windowSize && len(h.intervals) > 0 {
+		dropped := h.intervals[0]
+		h.intervals = h.intervals[1:]
+		h.sum -= dropped
+		h.sumSq -= dropped * dropped
+	}
+	v := float64(interval)
+	h.intervals = append(h.intervals, v)
+	h.sum += v
+	h.sumSq += v * v
+}
+
+func (h *heartbeatHistory) mean() float64 {
+	return h.sum / float64(len(h.intervals))
+}
+
+func (h *heartbeatHistory) stdDeviation() float64 {
+	mean := h.mean()
+	variance := h.sumSq/float64(len(h.intervals)) - mean*mean
+	return math.Sqrt(math.Max(variance, 0))
+}
@@ -0,0 +1,118 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhiAccrual(t *testing.T) {
+	config := NewFailureDetectorConfig().
+		WithHeartbeatInterval(time.Second).
+		WithAcceptablePause(0).
+		WithMinStdDeviation(time.Millisecond * 100)
+	d := newPhiAccrual(config)
+	now := time.Now()
+	assert.Equal(t, 0.0, d.phi("A", now))
+
+	for i := 0; i < 10; i++ {
+		d.heartbeat("A", now)
+		now = now.Add(time.Second)
+	}
+	last := now.Add(-time.Second)
+	// right after a heartbeat we are sure the member is alive.
+	assert.Less(t, d.phi("A", last), 0.1)
+	// phi grows the longer the next heartbeat is overdue.
+	p1 := d.phi("A", last.Add(time.Second))
+	p2 := d.phi("A", last.Add(time.Second*2))
+	p3 := d.phi("A", last.Add(time.Second*3))
+	assert.Less(t, p1, p2)
+	assert.Less(t, p2, p3)
+	assert.Greater(t, p3, 12.0)
+
+	d.remove("A")
+	assert.Equal(t, 0.0, d.phi("A", now))
+}
+
+func TestFailureDetectorRemovesDeadMember(t *testing.T) {
+	fd := NewFailureDetectorConfig().
+		WithHeartbeatInterval(time.Millisecond * 20).
+		WithAcceptablePause(time.Millisecond * 20).
+		WithMinStdDeviation(time.Millisecond * 10).
+		WithSuspectThreshold(2).
+		WithDeadThreshold(8)
+
+	makeMember := func(id string, bootstrap ...MemberAddr) *Cluster {
+		config := NewSelfManagedConfig().
+			WithFailureDetector(fd).
+			WithGossipInterval(time.Millisecond * 50)
+		for _, member := range bootstrap {
+			config = config.WithBootstrapMember(member)
+		}
+		c, err := New(NewConfig().
+			WithID(id).
+			WithListenAddr(getRandomLocalhostAddr()).
+			WithProvider(NewSelfManagedProvider(config)))
+		require.NoError(t, err)
+		return c
+	}
+	c1 := makeMember("A")
+	c1.Start()
+	defer c1.Stop()
+	seed := MemberAddr{ListenAddr: c1.Address(), ID: "A"}
+	c2 := makeMember("B", seed)
+	c2.Start()
+	defer c2.Stop()
+	c3 := makeMember("C", seed)
+	c3.Start()
+	defer c3.Stop()
+
+	suspect := make(chan *Member, 1)
+	dead := make(chan *Member, 1)
+	// Whichever member notices first lets the other one know, so listen to
+	// the events of both.
+	for _, c := range []*Cluster{c1, c2} {
+		eventPID := c.Engine().SpawnFunc(func(c *actor.Context) {
+			switch msg := c.Message().(type) {
+			case MemberSuspectEvent:
+				select {
+				case suspect <- msg.Member:
+				default:
+				}
+			case MemberDeadEvent:
+				select {
+				case dead <- msg.Member:
+				default:
+				}
+			}
+		}, "event")
+		c.Engine().Subscribe(eventPID)
+		defer c.Engine().Unsubscribe(eventPID)
+	}
+
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 3 && len(c2.Members()) == 3
+	}, time.Second*2, time.Millisecond*10)
+
+	// Member C stops sending heartbeats, while its remote stays reachable.
+	<-c3.Engine().Poison(c3.providerPID).Done()
+
+	select {
+	case member := <-suspect:
+		assert.Equal(t, "C", member.ID)
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected member C to become suspect")
+	}
+	select {
+	case member := <-dead:
+		assert.Equal(t, "C", member.ID)
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected member C to be dead")
+	}
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 2 && len(c2.Members()) == 2
+	}, time.Second*2, time.Millisecond*10)
+}
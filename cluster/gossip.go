@@ -0,0 +1,144 @@
+package cluster
+
+import (
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
+
+const (
+	defaultGossipInterval = time.Second
+	defaultGossipFanout   = 3
+	// tombstoneTTL is how long we remember members that left, which needs to
+	// be long enough for the leave to reach every member.
+	tombstoneTTL = time.Minute
+)
+
+type (
+	tombstone struct {
+		member *Member
+		at     time.Time
+	}
+	getTopology struct{}
+)
+
+// Topology returns the view this member has of the cluster. Members that
+// agree on the topology have the same hash.
+func (c *Cluster) Topology() *Topology {
+	resp, err := c.engine.Request(c.agentPID, getTopology{}, c.config.requestTimeout).Result()
+	if err != nil {
+		slog.Error("get topology failed", "err", err)
+		return nil
+	}
+	if topology, ok := resp.(*Topology); ok {
+		return topology
+	}
+	return nil
+}
+
+// gossip sends our view of the topology to random peers. Peers merge it with
+// their own view and answer with theirs when they know something we don't,
+// so every member converges on the same topology in a few rounds.
+func (s *SelfManaged) gossip(c *actor.Context) {
+	s.pruneTombstones()
+	peers := s.peers()
+	rand.Shuffle(len(peers), func(i, j int) {
+		peers[i], peers[j] = peers[j], peers[i]
+	})
+	if len(peers) > s.config.gossipFanout {
+		peers = peers[:s.config.gossipFanout]
+	}
+	topology := s.topology()
+	for _, peer := range peers {
+		c.Send(memberToProviderPID(peer), topology)
+	}
+}
+
+func (s *SelfManaged) handleTopology(c *actor.Context, msg *Topology) {
+	changed := false
+	for _, member := range msg.Left {
+		if member.Host != s.cluster.agentPID.Address && s.tombstone(member) {
+			changed = true
+		}
+	}
+	// never take our own entry from a peer, it could be outdated.
+	members := make([]*Member, 0, len(msg.Members))
+	for _, member := range msg.Members {
+		if member.Host != s.cluster.agentPID.Address {
+			members = append(members, member)
+		}
+	}
+	if s.mergeMembers(members...) {
+		changed = true
+	}
+	if changed {
+		s.sendMembersToAgent()
+	}
+	// Let the peer know what it's missing.
+	if c.Sender() != nil && s.members.Digest() != msg.Hash {
+		c.Send(c.Sender(), s.topology())
+	}
+}
+
+func (s *SelfManaged) topology() *Topology {
+	left := make([]*Member, 0, len(s.left))
+	for _, t := range s.left {
+		left = append(left, t.member)
+	}
+	return &Topology{
+		Hash:    s.members.Digest(),
+		Members: s.members.Slice(),
+		Left:    left,
+	}
+}
+
+// memberLeft removes the member and remembers it left, so gossip of peers
+// that did not notice yet can't add it back.
+func (s *SelfManaged) memberLeft(member *Member) {
+	if s.tombstone(member) {
+		s.sendMembersToAgent()
+	}
+}
+
+// tombstone records that the given member left and removes it when we knew
+// about it. It returns true if the member was removed.
+func (s *SelfManaged) tombstone(member *Member) bool {
+	if t, ok := s.left[member.ID]; ok && t.member.Started >= member.Started {
+		return false
+	}
+	known := s.members.GetByID(member.ID)
+	// the member restarted after this leave.
+	if known != nil && known.Started > member.Started {
+		return false
+	}
+	s.left[member.ID] = tombstone{member: member, at: time.Now()}
+	if known == nil {
+		return false
+	}
+	s.members.Remove(known)
+	s.detector.remove(known.ID)
+	delete(s.status, known.ID)
+	return true
+}
+
+func (s *SelfManaged) pruneTombstones() {
+	for id, t := range s.left {
+		if time.Since(t.at) > tombstoneTTL {
+			delete(s.left, id)
+		}
+	}
+}
+
+// peers returns all the members except ourselves.
+func (s *SelfManaged) peers() []*Member {
+	peers := make([]*Member, 0, s.members.Len())
+	s.members.ForEach(func(m *Member) bool {
+		if m.Host != s.cluster.agentPID.Address {
+			peers = append(peers, m)
+		}
+		return true
+	})
+	return peers
+}
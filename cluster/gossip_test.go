@@ -0,0 +1,69 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeGossipCluster(t *testing.T, id string, bootstrap ...MemberAddr) *Cluster {
+	config := NewSelfManagedConfig().WithGossipInterval(time.Millisecond * 20)
+	for _, member := range bootstrap {
+		config = config.WithBootstrapMember(member)
+	}
+	c, err := New(NewConfig().
+		WithID(id).
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithProvider(NewSelfManagedProvider(config)))
+	require.NoError(t, err)
+	c.Start()
+	t.Cleanup(c.Stop)
+	return c
+}
+
+func TestGossipConverges(t *testing.T) {
+	const n = 5
+	seed := makeGossipCluster(t, "0")
+	clusters := []*Cluster{seed}
+	for i := 1; i < n; i++ {
+		c := makeGossipCluster(t, fmt.Sprint(i), MemberAddr{ListenAddr: seed.Address(), ID: "0"})
+		clusters = append(clusters, c)
+	}
+
+	require.Eventually(t, func() bool {
+		hash := seed.Topology().Hash
+		for _, c := range clusters {
+			topology := c.Topology()
+			if len(topology.Members) != n || topology.Hash != hash {
+				return false
+			}
+		}
+		return true
+	}, time.Second*5, time.Millisecond*20)
+}
+
+func TestGossipLeftMemberIsNotAddedBack(t *testing.T) {
+	c1 := makeGossipCluster(t, "A")
+	c2 := makeGossipCluster(t, "B", MemberAddr{ListenAddr: c1.Address(), ID: "A"})
+
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 2 && len(c2.Members()) == 2
+	}, time.Second*2, time.Millisecond*10)
+
+	// C left the cluster, but B still gossips it.
+	ghost := &Member{ID: "C", Host: "127.0.0.1:1", Started: 1}
+	c1.Engine().Send(c1.providerPID, &Topology{Left: []*Member{ghost}})
+	c1.Engine().Send(c1.providerPID, &Topology{Members: []*Member{ghost}})
+	time.Sleep(time.Millisecond * 50)
+	assert.Len(t, c1.Members(), 2)
+
+	// unless it was restarted after it left.
+	restarted := &Member{ID: "C", Host: "127.0.0.1:1", Started: 2}
+	c1.Engine().Send(c1.providerPID, &Topology{Members: []*Member{restarted}})
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 3
+	}, time.Second, time.Millisecond*10)
+}
@@ -0,0 +1,194 @@
+package cluster
+
+import (
+	"log/slog"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
+
+var defaultGrainIdleTimeout = time.Minute
+
+type (
+	getGrain struct {
+		kind   string
+		config ActivationConfig
+	}
+	// grainInvalidate is sent by the agent to the grain of an identity when
+	// the activation the grain routes to is no longer available.
+	grainInvalidate struct{ pid *actor.PID }
+	// passivateTick is the message an activation sends to itself to check
+	// whether it has been idle for too long.
+	passivateTick struct{}
+	// grainTick is the message a grain sends to itself to check whether it
+	// has been idle for too long.
+	grainTick struct{}
+	// grainIdle is sent by a grain to the agent when it has been idle for
+	// too long, the agent stops it.
+	grainIdle struct {
+		identity string
+		pid      *actor.PID
+	}
+)
+
+// Get returns the PID of the virtual actor (grain) of the given kind and id.
+// The returned PID can always be sent to: the actor is activated on the member
+// selected by placement when it receives its first message, and it is
+// reactivated when the member that hosts it leaves the cluster or it was
+// passivated after being idle (see KindConfig.WithIdleTimeout).
+//
+//	playerPID := cluster.Get("player", "1")
+//	cluster.Engine().Send(playerPID, &Move{})
+//
+// The PID belongs to a local process that routes the messages to the
+// current activation, with their sender, header and deadline, so requests
+// work as expected. The process is stopped when it didn't receive a message
+// for the grain idle timeout (see Config.WithGrainIdleTimeout), hold on to
+// the PID only as long as it's used and call Get again afterwards. Nil is
+// returned when none of the members registered the kind.
+func (c *Cluster) Get(kind, id string) *actor.PID {
+	return c.getGrain(kind, NewActivationConfig().WithID(id))
+}
+
+func (c *Cluster) getGrain(kind string, config ActivationConfig) *actor.PID {
+	resp, err := c.engine.Request(c.agentPID, getGrain{kind: kind, config: config}, c.config.requestTimeout).Result()
+	if err != nil {
+		slog.Error("get grain failed", "err", err)
+		return nil
+	}
+	if pid, ok := resp.(*actor.PID); ok {
+		return pid
+	}
+	return nil
+}
+
+// grain routes the messages it receives to the current activation of its
+// identity, activating it when needed.
+type grain struct {
+	cluster *Cluster
+	kind    string
+	config  ActivationConfig
+	// the current activation, nil when we need to resolve it.
+	pid        *actor.PID
+	lastActive time.Time
+	repeater   actor.SendRepeater
+}
+
+func newGrain(c *Cluster, kind string, config ActivationConfig) actor.Producer {
+	return func() actor.Receiver {
+		return &grain{
+			cluster: c,
+			kind:    kind,
+			config:  config,
+		}
+	}
+}
+
+// spawnGrain spawns the grain of the given identity. The grain of an identity
+// that is being stopped could still be registered, so each one gets its own
+// ID.
+func spawnGrain(c *Cluster, kind string, config ActivationConfig) *actor.PID {
+	id := kind + "/" + config.id + "/" + strconv.Itoa(rand.Intn(math.MaxInt))
+	return c.engine.Spawn(newGrain(c, kind, config), "grain", actor.WithID(id))
+}
+
+func (g *grain) Receive(c *actor.Context) {
+	switch msg := c.Message().(type) {
+	case actor.Initialized:
+	case actor.Started:
+		g.lastActive = time.Now()
+		g.repeater = c.SendRepeat(c.PID(), grainTick{}, g.cluster.config.grainIdleTimeout/2)
+	case actor.Stopped:
+		g.repeater.Stop()
+	case grainTick:
+		if time.Since(g.lastActive) >= g.cluster.config.grainIdleTimeout {
+			// Ask again after another timeout if we are still running by
+			// then.
+			g.lastActive = time.Now()
+			c.Send(g.cluster.agentPID, grainIdle{identity: g.kind + "/" + g.config.id, pid: c.PID()})
+		}
+	case grainInvalidate:
+		if g.pid != nil && g.pid.Equals(msg.pid) {
+			g.pid = nil
+		}
+	default:
+		g.lastActive = time.Now()
+		if g.pid == nil {
+			g.pid = g.resolve(c)
+		}
+		if g.pid == nil {
+			slog.Warn("dropped message, grain could not be activated",
+				"kind", g.kind,
+				"id", g.config.id,
+				"msg", msg)
+			return
+		}
+		c.Engine().SendEnvelope(g.pid, c.Envelope())
+	}
+}
+
+func (g *grain) resolve(c *actor.Context) *actor.PID {
+	msg := activate{
+		kind:   g.kind,
+		config: g.config,
+		lookup: true,
+	}
+	resp, err := c.Request(g.cluster.agentPID, msg, g.cluster.config.requestTimeout).Result()
+	if err != nil {
+		slog.Error("grain activation failed", "kind", g.kind, "id", g.config.id, "err", err)
+		return nil
+	}
+	pid, _ := resp.(*actor.PID)
+	return pid
+}
+
+// withPassivation returns a middleware that deactivates the actor when it did
+// not receive any message within the given timeout. The actor is not
+// deactivated while it's migrated, it's paused or redirects to the new
+// activation.
+func withPassivation(c *Cluster, timeout time.Duration) actor.MiddlewareFunc {
+	return func(next actor.ReceiveFunc) actor.ReceiveFunc {
+		var (
+			lastActive time.Time
+			repeater   *actor.SendRepeater
+			migrating  bool
+		)
+		return func(ctx *actor.Context) {
+			switch ctx.Message().(type) {
+			case actor.Started:
+				lastActive = time.Now()
+				if repeater == nil {
+					sr := ctx.SendRepeat(ctx.PID(), passivateTick{}, timeout/2)
+					repeater = &sr
+				}
+				next(ctx)
+			case migrationPause:
+				migrating = true
+				next(ctx)
+			case migrationResume:
+				migrating = false
+				lastActive = time.Now()
+				next(ctx)
+			case passivateTick:
+				if !migrating && time.Since(lastActive) >= timeout {
+					// Deactivate again after another timeout if we are
+					// still running by then.
+					lastActive = time.Now()
+					c.Deactivate(ctx.PID())
+				}
+			case actor.Stopped:
+				if repeater != nil {
+					repeater.Stop()
+					repeater = nil
+				}
+				next(ctx)
+			default:
+				lastActive = time.Now()
+				next(ctx)
+			}
+		}
+	}
+}
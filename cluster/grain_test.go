@@ -0,0 +1,294 @@
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/khulnasoft/goactors/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// counter responds with the number of messages it received.
+type counter struct {
+	n int
+}
+
+func newCounter() actor.Receiver {
+	return &counter{}
+}
+
+func (c *counter) Receive(ctx *actor.Context) {
+	switch ctx.Message().(type) {
+	case *remote.TestMessage:
+		c.n++
+		ctx.Respond(&remote.TestMessage{Data: []byte(strconv.Itoa(c.n))})
+	}
+}
+
+func requestCount(t *testing.T, c *Cluster, pid *actor.PID) int {
+	resp, err := c.Engine().Request(pid, &remote.TestMessage{}, time.Second).Result()
+	require.NoError(t, err)
+	msg, ok := resp.(*remote.TestMessage)
+	require.True(t, ok)
+	n, err := strconv.Atoi(string(msg.Data))
+	require.NoError(t, err)
+	return n
+}
+
+func TestGrainActivatesOnFirstMessage(t *testing.T) {
+	c1Addr := getRandomLocalhostAddr()
+	c1 := makeCluster(t, c1Addr, "A", "eu")
+	c1.RegisterKind("counter", newCounter, NewKindConfig())
+	c1.Start()
+	defer c1.Stop()
+
+	c2 := makeClusterWithBootstrap(t, getRandomLocalhostAddr(), "B", "eu", MemberAddr{ListenAddr: c1Addr, ID: "A"})
+	c2.Start()
+	defer c2.Stop()
+
+	require.Eventually(t, func() bool {
+		return c2.HasKind("counter")
+	}, time.Second, time.Millisecond*10)
+
+	pid := c2.Get("counter", "1")
+	require.NotNil(t, pid)
+	assert.True(t, pid.Equals(c2.Get("counter", "1")))
+	assert.Nil(t, c2.GetActiveByID("counter/1"))
+
+	assert.Equal(t, 1, requestCount(t, c2, pid))
+	assert.Equal(t, 2, requestCount(t, c2, pid))
+	// both grains route to the same activation.
+	assert.Equal(t, 3, requestCount(t, c1, c1.Get("counter", "1")))
+
+	active := c2.GetActiveByID("counter/1")
+	require.NotNil(t, active)
+	assert.Equal(t, c1Addr, active.Address)
+}
+
+func TestGrainStopsWhenIdle(t *testing.T) {
+	c, err := New(NewConfig().
+		WithID("A").
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithGrainIdleTimeout(time.Millisecond * 50))
+	require.NoError(t, err)
+	c.RegisterKind("counter", newCounter, NewKindConfig())
+	c.Start()
+	defer c.Stop()
+
+	pid := c.Get("counter", "1")
+	require.NotNil(t, pid)
+	assert.Equal(t, 1, requestCount(t, c, pid))
+	require.Eventually(t, func() bool {
+		_, ok := c.Engine().ProcessInfo(pid)
+		return !ok
+	}, time.Second, time.Millisecond*10)
+
+	// A new grain routes to the same activation.
+	other := c.Get("counter", "1")
+	require.NotNil(t, other)
+	assert.False(t, pid.Equals(other))
+	assert.Equal(t, 2, requestCount(t, c, other))
+}
+
+func TestGrainUnknownKind(t *testing.T) {
+	c := makeCluster(t, getRandomLocalhostAddr(), "A", "eu")
+	c.RegisterKind("counter", newCounter, NewKindConfig())
+	c.Start()
+	defer c.Stop()
+
+	assert.Nil(t, c.Get("unknown", "1"))
+	assert.NotNil(t, c.Get("counter", "1"))
+}
+
+func TestGrainPassivation(t *testing.T) {
+	c := makeCluster(t, getRandomLocalhostAddr(), "A", "eu")
+	c.RegisterKind("counter", newCounter, NewKindConfig().WithIdleTimeout(time.Millisecond*50))
+	c.Start()
+	defer c.Stop()
+
+	deactivated := make(chan struct{}, 1)
+	eventPID := c.Engine().SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(DeactivationEvent); ok {
+			deactivated <- struct{}{}
+		}
+	}, "event")
+	c.Engine().Subscribe(eventPID)
+	defer c.Engine().Unsubscribe(eventPID)
+
+	require.Eventually(t, func() bool {
+		return len(c.Members()) == 1
+	}, time.Second, time.Millisecond*10)
+
+	pid := c.Get("counter", "1")
+	assert.Equal(t, 1, requestCount(t, c, pid))
+	assert.Equal(t, 2, requestCount(t, c, pid))
+
+	select {
+	case <-deactivated:
+	case <-time.After(time.Second):
+		t.Fatal("expected the counter to be passivated")
+	}
+	assert.Nil(t, c.GetActiveByID("counter/1"))
+
+	// the next message reactivates the counter with a fresh state.
+	require.Eventually(t, func() bool {
+		return c.Engine().Registry.GetPID("counter", "1") == nil
+	}, time.Second, time.Millisecond*10)
+	assert.Equal(t, 1, requestCount(t, c, pid))
+}
+
+func TestGrainPassivationRetries(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	deactivations := make(chan struct{}, 10)
+	// The agent ignores the deactivations, the activation keeps running.
+	c := &Cluster{engine: e}
+	c.agentPID = e.SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(deactivate); ok {
+			deactivations <- struct{}{}
+		}
+	}, "agent")
+	e.SpawnFunc(func(*actor.Context) {}, "counter",
+		actor.WithMiddleware(withPassivation(c, time.Millisecond*20)))
+
+	for range 2 {
+		select {
+		case <-deactivations:
+		case <-time.After(time.Second):
+			t.Fatal("expected a deactivation")
+		}
+	}
+}
+
+func TestGrainNotPassivatedWhileMigrating(t *testing.T) {
+	c := makeCluster(t, getRandomLocalhostAddr(), "A", "eu")
+	c.RegisterKind("counter", newCounter, NewKindConfig().WithIdleTimeout(time.Millisecond*50))
+	c.Start()
+	defer c.Stop()
+
+	deactivated := make(chan struct{}, 1)
+	eventPID := c.Engine().SpawnFunc(func(ctx *actor.Context) {
+		if _, ok := ctx.Message().(DeactivationEvent); ok {
+			deactivated <- struct{}{}
+		}
+	}, "event")
+	c.Engine().Subscribe(eventPID)
+	defer c.Engine().Unsubscribe(eventPID)
+
+	require.Eventually(t, func() bool {
+		return len(c.Members()) == 1
+	}, time.Second, time.Millisecond*10)
+
+	pid := c.Activate("counter", NewActivationConfig().WithID("1"))
+	require.NotNil(t, pid)
+	_, err := c.Engine().Request(pid, migrationPause{}, time.Second).Result()
+	require.NoError(t, err)
+
+	select {
+	case <-deactivated:
+		t.Fatal("expected the paused counter not to be passivated")
+	case <-time.After(time.Millisecond * 200):
+	}
+
+	c.Engine().Send(pid, migrationResume{})
+	select {
+	case <-deactivated:
+	case <-time.After(time.Second):
+		t.Fatal("expected the counter to be passivated once resumed")
+	}
+}
+
+type envelopeResult struct {
+	header      string
+	hasDeadline bool
+}
+
+// envelopeProbe reports the header and deadline of the messages it receives.
+type envelopeProbe struct {
+	results chan<- envelopeResult
+}
+
+func (p envelopeProbe) Receive(c *actor.Context) {
+	if _, ok := c.Message().(*remote.TestMessage); ok {
+		_, hasDeadline := c.Deadline()
+		p.results <- envelopeResult{header: c.Header("id"), hasDeadline: hasDeadline}
+		if c.Sender() != nil {
+			c.Respond(&remote.TestMessage{})
+		}
+	}
+}
+
+func TestGrainForwardsEnvelope(t *testing.T) {
+	results := make(chan envelopeResult, 2)
+	c := makeCluster(t, getRandomLocalhostAddr(), "A", "eu")
+	c.RegisterKind("probe", func() actor.Receiver {
+		return envelopeProbe{results: results}
+	}, NewKindConfig())
+	c.Start()
+	defer c.Stop()
+	require.Eventually(t, func() bool {
+		return len(c.Members()) == 1
+	}, time.Second, time.Millisecond*10)
+
+	pid := c.Get("probe", "1")
+	c.Engine().SendWithHeaders(pid, &remote.TestMessage{}, actor.Header{"id": "1"})
+	assert.Equal(t, envelopeResult{header: "1"}, <-results)
+	_, err := c.Engine().Request(pid, &remote.TestMessage{}, time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, envelopeResult{hasDeadline: true}, <-results)
+}
+
+func TestGrainReactivatesAfterMemberLeave(t *testing.T) {
+	c1Addr := getRandomLocalhostAddr()
+	c2Addr := getRandomLocalhostAddr()
+
+	c1 := makeCluster(t, c1Addr, "A", "eu")
+	c1.RegisterKind("counter", newCounter, NewKindConfig())
+	c1.Start()
+	defer c1.Stop()
+
+	r := remote.New(c2Addr, remote.NewConfig())
+	e, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(r))
+	require.NoError(t, err)
+	selfManagedConfig := NewSelfManagedConfig().WithBootstrapMember(MemberAddr{ListenAddr: c1Addr, ID: "A"})
+	c2, err := New(NewConfig().
+		WithID("B").
+		WithEngine(e).
+		WithProvider(NewSelfManagedProvider(selfManagedConfig)))
+	require.NoError(t, err)
+	c2.RegisterKind("counter", newCounter, NewKindConfig())
+	c2.Start()
+	defer c2.Stop()
+
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 2
+	}, time.Second, time.Millisecond*10)
+
+	// find an identity that is placed on member B.
+	members := c1.Members()
+	id := ""
+	for i := 0; ; i++ {
+		member := SelectRendezvousMember(ActivationDetails{Members: members, Kind: "counter", ID: fmt.Sprint(i)})
+		if member.ID == "B" {
+			id = fmt.Sprint(i)
+			break
+		}
+	}
+
+	pid := c1.Get("counter", id)
+	assert.Equal(t, 1, requestCount(t, c1, pid))
+	require.Equal(t, c2Addr, c1.GetActiveByID("counter/"+id).Address)
+
+	r.Stop().Wait()
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 1
+	}, time.Second*5, time.Millisecond*10)
+
+	// the counter is reactivated on the member that is left.
+	assert.Equal(t, 1, requestCount(t, c1, pid))
+	assert.Equal(t, c1Addr, c1.GetActiveByID("counter/"+id).Address)
+}
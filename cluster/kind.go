@@ -1,15 +1,107 @@
 package cluster
 
-import "github.com/khulnasoft/goactors/actor"
+import (
+	"slices"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
 
 // KindConfig holds configuration for a registered kind.
-type KindConfig struct{}
+type KindConfig struct {
+	idleTimeout      time.Duration
+	maxActivations   int
+	regions          []string
+	spawnOpts        []actor.OptFunc
+	singleton        bool
+	managedSingleton bool
+	handoff          bool
+}
 
 // NewKindConfig returns a default kind configuration.
 func NewKindConfig() KindConfig {
 	return KindConfig{}
 }
 
+// WithIdleTimeout set's the duration after which an activation of the kind
+// that did not receive any message is passivated (deactivated). Virtual
+// actors obtained with Cluster.Get are transparently reactivated on the
+// next message.
+//
+// Defaults to 0, which never passivates.
+func (config KindConfig) WithIdleTimeout(d time.Duration) KindConfig {
+	config.idleTimeout = d
+	return config
+}
+
+// WithMaxActivations set's the maximum number of activations of the kind a
+// single member will host. Activation requests exceeding the limit are
+// rejected.
+//
+// Defaults to 0, which is unlimited.
+func (config KindConfig) WithMaxActivations(n int) KindConfig {
+	config.maxActivations = n
+	return config
+}
+
+// WithRegions set's the regions the kind can be activated in. Members in
+// other regions will reject activation requests for the kind.
+//
+// Defaults to all regions.
+func (config KindConfig) WithRegions(regions ...string) KindConfig {
+	config.regions = append(slices.Clone(config.regions), regions...)
+	return config
+}
+
+// WithSpawnOpts set's the options that are applied when the kind is spawned
+// on activation, for example middleware or the restart policy. The ID of the
+// actor is always the one of the activation.
+func (config KindConfig) WithSpawnOpts(opts ...actor.OptFunc) KindConfig {
+	config.spawnOpts = append(slices.Clone(config.spawnOpts), opts...)
+	return config
+}
+
+// WithSingleton marks the kind as a singleton. Only one activation of the
+// kind can exist across the cluster.
+//
+// Defaults to false.
+func (config KindConfig) WithSingleton(b bool) KindConfig {
+	config.singleton = b
+	return config
+}
+
+// WithManagedSingleton marks the kind as a singleton that the cluster keeps
+// activated: it's activated on the oldest member that has the kind
+// registered as soon as that member joined, and moved to the next oldest
+// member when the owner leaves. See Cluster.Singleton. It implies
+// WithSingleton.
+//
+// Defaults to false.
+func (config KindConfig) WithManagedSingleton(b bool) KindConfig {
+	config.managedSingleton = b
+	if b {
+		config.singleton = true
+	}
+	return config
+}
+
+// WithHandoff set's whether the activations of the kind are handed off to
+// the other members when their member leaves the cluster with
+// Cluster.Leave. Actors implementing the Handoff interface carry their state
+// over to the new activation. Singletons are not handed off, they fail over
+// to the next owner.
+//
+// Defaults to false, which deactivates them.
+func (config KindConfig) WithHandoff(b bool) KindConfig {
+	config.handoff = b
+	return config
+}
+
+// allowsRegion returns true if the kind can be activated in the given region.
+func (config KindConfig) allowsRegion(region string) bool {
+	return len(config.regions) == 0 || slices.Contains(config.regions, region)
+}
+
 // A kind is a type of actor that can be activated from any member of the cluster.
 type kind struct {
 	config   KindConfig
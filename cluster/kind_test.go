@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startKindCluster(t *testing.T, region string, config KindConfig) *Cluster {
+	c := makeCluster(t, getRandomLocalhostAddr(), "A", region)
+	c.RegisterKind("player", NewPlayer, config)
+	c.Start()
+	t.Cleanup(c.Stop)
+	require.Eventually(t, func() bool {
+		return len(c.Members()) == 1
+	}, time.Second, time.Millisecond*10)
+	return c
+}
+
+func requestActivation(t *testing.T, c *Cluster, id string) *ActivationResponse {
+	req := &ActivationRequest{
+		Kind:         "player",
+		ID:           id,
+		TopologyHash: NewMemberSet(c.Members()...).TopologyHash(),
+	}
+	resp, err := c.Engine().Request(c.PID(), req, time.Second).Result()
+	require.NoError(t, err)
+	r, ok := resp.(*ActivationResponse)
+	require.True(t, ok)
+	return r
+}
+
+func TestKindConfigMaxActivations(t *testing.T) {
+	c := startKindCluster(t, "eu", NewKindConfig().WithMaxActivations(2))
+
+	assert.True(t, requestActivation(t, c, "1").Success)
+	assert.True(t, requestActivation(t, c, "2").Success)
+	resp := requestActivation(t, c, "3")
+	assert.False(t, resp.Success)
+	assert.Equal(t, ReasonMaxActivationsReached, resp.Reason)
+
+	// deactivating frees up a slot.
+	c.Deactivate(actor.NewPID(c.Address(), "player/1"))
+	require.Eventually(t, func() bool {
+		return c.GetActiveByID("player/1") == nil
+	}, time.Second, time.Millisecond*10)
+	assert.NotNil(t, c.Activate("player", NewActivationConfig().WithID("3")))
+}
+
+func TestKindConfigRegions(t *testing.T) {
+	c := startKindCluster(t, "eu", NewKindConfig().WithRegions("us-east", "us-west"))
+
+	resp := requestActivation(t, c, "1")
+	assert.False(t, resp.Success)
+	assert.Equal(t, ReasonRegionNotAllowed, resp.Reason)
+	assert.Nil(t, c.Activate("player", NewActivationConfig().WithID("1")))
+}
+
+func TestKindConfigSingleton(t *testing.T) {
+	c := startKindCluster(t, "eu", NewKindConfig().WithSingleton(true))
+
+	assert.NotNil(t, c.Activate("player", NewActivationConfig().WithID("1")))
+	resp := requestActivation(t, c, "2")
+	assert.False(t, resp.Success)
+	assert.Equal(t, ReasonSingletonAlreadyActive, resp.Reason)
+}
+
+func TestKindConfigSpawnOpts(t *testing.T) {
+	received := make(chan struct{}, 1)
+	mw := func(next actor.ReceiveFunc) actor.ReceiveFunc {
+		return func(c *actor.Context) {
+			if _, ok := c.Message().(actor.Started); ok {
+				received <- struct{}{}
+			}
+			next(c)
+		}
+	}
+	// the ID in the spawn options is ignored.
+	config := NewKindConfig().WithSpawnOpts(actor.WithMiddleware(mw), actor.WithID("foo"))
+	c := startKindCluster(t, "eu", config)
+
+	pid := c.Activate("player", NewActivationConfig().WithID("1"))
+	require.NotNil(t, pid)
+	assert.Equal(t, "player/1", pid.ID)
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("expected the middleware of the kind to be applied")
+	}
+}
@@ -0,0 +1,406 @@
+package cluster
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
+
+type (
+	// holdLease asks the lease table to spawn the holder of an acquired
+	// lease.
+	holdLease struct {
+		req        *LeaseRequest
+		holder     *actor.PID
+		members    []*Member
+		acquiredAt time.Time
+		lost       chan struct{}
+	}
+	// leaseRenew triggers the holder to renew its lease.
+	leaseRenew struct{}
+	// leaseRenewed reports the outcome of a renewal to the holder.
+	leaseRenewed struct {
+		ok   bool
+		held string
+		at   time.Time
+	}
+	// leaseHolderLeft drops the leases held by a member that left.
+	leaseHolderLeft struct {
+		memberID string
+	}
+	// leaseRelease stops the holder after it released its lease.
+	leaseRelease struct{}
+	// getQuorumSize asks the agent for the number of members the quorum of
+	// a lease is based on.
+	getQuorumSize struct{}
+)
+
+// Lease is a lease acquired with Cluster.AcquireLease. It's renewed
+// automatically until it's released or lost.
+type Lease struct {
+	name    string
+	pid     *actor.PID
+	cluster *Cluster
+	lost    chan struct{}
+}
+
+// Name returns the name of the lease.
+func (l *Lease) Name() string {
+	return l.name
+}
+
+// Done returns a channel that is closed when the lease is lost or released.
+func (l *Lease) Done() <-chan struct{} {
+	return l.lost
+}
+
+// Release stops renewing the lease and releases it on all the members, so it
+// can be acquired again right away.
+func (l *Lease) Release() {
+	select {
+	case <-l.lost:
+		return
+	default:
+	}
+	_, _ = l.cluster.engine.Request(l.pid, leaseRelease{}, l.cluster.config.requestTimeout).Result()
+}
+
+// AcquireLease acquires the lease with the given name in the cluster. The
+// lease is granted when a majority of the members grant it, and it's renewed
+// automatically while it's held. The majority is based on all the members
+// seen since the cluster started, except the ones that gracefully left, so a
+// member that is cut off by a partition can't keep the lease on its own side.
+// Members that failed keep counting until they're back. The lease is lost when it can't
+// be renewed before its time to live expires. The other members drop the
+// leases of a member that leaves the cluster or fails, so the lease can be
+// acquired by another member.
+//
+// When the lease is lost, or the cluster is stopped while it's held, a
+// LeaseLost message is sent to the given holder, which can be nil.
+//
+//	lease, err := c.AcquireLease("billing", time.Second*10, pid)
+//	if err != nil {
+//		// the lease is held by another member.
+//	}
+//	defer lease.Release()
+func (c *Cluster) AcquireLease(name string, ttl time.Duration, holder *actor.PID) (*Lease, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("lease %s: ttl must be positive", name)
+	}
+	var (
+		members = c.Members()
+		now     = time.Now()
+		req     = &LeaseRequest{
+			Name:     name,
+			Token:    fmt.Sprintf("%s/%d", c.config.id, rand.Intn(math.MaxInt)),
+			MemberID: c.config.id,
+			Ttl:      int64(ttl),
+		}
+	)
+	if ok, held := c.requestLease(members, req); !ok {
+		c.releaseLease(members, req)
+		if len(held) > 0 {
+			return nil, fmt.Errorf("lease %s: held by member %s", name, held)
+		}
+		return nil, fmt.Errorf("lease %s: no quorum", name)
+	}
+	lost := make(chan struct{})
+	resp, err := c.engine.Request(c.leasesPID, holdLease{
+		req:        req,
+		holder:     holder,
+		members:    members,
+		acquiredAt: now,
+		lost:       lost,
+	}, c.config.requestTimeout).Result()
+	if err != nil {
+		c.releaseLease(members, req)
+		return nil, fmt.Errorf("lease %s: %w", name, err)
+	}
+	pid, ok := resp.(*actor.PID)
+	if !ok {
+		c.releaseLease(members, req)
+		return nil, fmt.Errorf("lease %s: unexpected response %T", name, resp)
+	}
+	return &Lease{
+		name:    name,
+		pid:     pid,
+		cluster: c,
+		lost:    lost,
+	}, nil
+}
+
+// requestLease asks the lease tables of the given members for the lease. It
+// returns true if a quorum of the cluster granted it, otherwise the ID of the
+// member holding the lease, if any of them told.
+func (c *Cluster) requestLease(members []*Member, req *LeaseRequest) (bool, string) {
+	size := c.quorumSize()
+	responses := make(chan *LeaseResponse, len(members))
+	for _, member := range members {
+		go func() {
+			resp, err := c.engine.Request(leaseTablePID(member), req, c.config.requestTimeout).Result()
+			if err != nil {
+				responses <- &LeaseResponse{}
+				return
+			}
+			r, _ := resp.(*LeaseResponse)
+			responses <- r
+		}()
+	}
+	var (
+		granted int
+		held    string
+	)
+	for range members {
+		resp := <-responses
+		switch {
+		case resp == nil:
+		case resp.Granted:
+			granted++
+		case len(resp.MemberID) > 0:
+			held = resp.MemberID
+		}
+	}
+	size = max(size, len(members))
+	return size > 0 && granted >= size/2+1, held
+}
+
+// quorumSize returns the number of members the quorum of a lease is based on.
+func (c *Cluster) quorumSize() int {
+	resp, err := c.engine.Request(c.agentPID, getQuorumSize{}, c.config.requestTimeout).Result()
+	if err != nil {
+		return 0
+	}
+	size, _ := resp.(int)
+	return size
+}
+
+// releaseLease releases the lease on the given members, it waits until they
+// released it.
+func (c *Cluster) releaseLease(members []*Member, req *LeaseRequest) {
+	var wg sync.WaitGroup
+	for _, member := range members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := &LeaseRelease{Name: req.Name, Token: req.Token}
+			_, _ = c.engine.Request(leaseTablePID(member), release, c.config.requestTimeout).Result()
+		}()
+	}
+	wg.Wait()
+}
+
+func leaseTablePID(member *Member) *actor.PID {
+	return actor.NewPID(member.Host, "lease/"+member.ID)
+}
+
+type leaseEntry struct {
+	token    string
+	memberID string
+	expires  time.Time
+}
+
+// leaseTable grants the leases on a member, a lease is granted to one holder
+// at a time until it expires. The table also hosts the holders of the leases
+// acquired on the member.
+type leaseTable struct {
+	cluster     *Cluster
+	leases      map[string]leaseEntry
+	eventSubPID *actor.PID
+}
+
+func newLeaseTable(c *Cluster) actor.Producer {
+	return func() actor.Receiver {
+		return &leaseTable{
+			cluster: c,
+			leases:  make(map[string]leaseEntry),
+		}
+	}
+}
+
+func (t *leaseTable) Receive(c *actor.Context) {
+	switch msg := c.Message().(type) {
+	case actor.Started:
+		t.eventSubPID = c.SpawnChildFunc(func(ctx *actor.Context) {
+			if msg, ok := ctx.Message().(MemberLeaveEvent); ok {
+				ctx.Send(ctx.Parent(), leaseHolderLeft{memberID: msg.Member.ID})
+			}
+		}, "event")
+		t.cluster.engine.Subscribe(t.eventSubPID)
+	case actor.Stopped:
+		t.cluster.engine.Unsubscribe(t.eventSubPID)
+	case *LeaseRequest:
+		c.Respond(t.handleLeaseRequest(msg))
+	case *LeaseRelease:
+		if entry, ok := t.leases[msg.Name]; ok && entry.token == msg.Token {
+			delete(t.leases, msg.Name)
+		}
+		c.Respond(&LeaseResponse{})
+	case leaseHolderLeft:
+		for name, entry := range t.leases {
+			if entry.memberID == msg.memberID {
+				delete(t.leases, name)
+			}
+		}
+	case holdLease:
+		c.Respond(c.SpawnChild(newLeaseHolder(t.cluster, msg), "holder", actor.WithID(msg.req.Token)))
+	}
+}
+
+func (t *leaseTable) handleLeaseRequest(req *LeaseRequest) *LeaseResponse {
+	now := time.Now()
+	if entry, ok := t.leases[req.Name]; ok && entry.token != req.Token && now.Before(entry.expires) {
+		return &LeaseResponse{MemberID: entry.memberID}
+	}
+	t.leases[req.Name] = leaseEntry{
+		token:    req.Token,
+		memberID: req.MemberID,
+		expires:  now.Add(time.Duration(req.Ttl)),
+	}
+	return &LeaseResponse{Granted: true}
+}
+
+// leaseHolder renews an acquired lease, a few times per time to live. The
+// renewals and the release run in the background, so a slow agent or member
+// doesn't hold up the holder.
+type leaseHolder struct {
+	cluster *Cluster
+	req     *LeaseRequest
+	holder  *actor.PID
+	members *memberWatch
+	// the moment the lease expires on the members that granted it, unless
+	// it's renewed.
+	validUntil time.Time
+	lost       chan struct{}
+	released   bool
+	renewing   bool
+	repeater   actor.SendRepeater
+}
+
+func newLeaseHolder(c *Cluster, msg holdLease) actor.Producer {
+	return func() actor.Receiver {
+		members := newMemberWatch()
+		// Start with the members the lease was acquired from.
+		members.handle(memberSnapshot{members: msg.members})
+		return &leaseHolder{
+			cluster:    c,
+			req:        msg.req,
+			holder:     msg.holder,
+			members:    members,
+			validUntil: msg.acquiredAt.Add(time.Duration(msg.req.Ttl)),
+			lost:       msg.lost,
+		}
+	}
+}
+
+func (h *leaseHolder) Receive(c *actor.Context) {
+	if h.members.handle(c.Message()) {
+		return
+	}
+	switch msg := c.Message().(type) {
+	case actor.Started:
+		h.members.start(c, h.cluster)
+		h.repeater = c.SendRepeat(c.PID(), leaseRenew{}, time.Duration(h.req.Ttl)/3)
+	case actor.Stopped:
+		h.repeater.Stop()
+		h.members.stop(c)
+		// We're only stopped by our parent, the lease table, when the cluster
+		// stops. It waits for us, so the lease is released on the others.
+		if !h.released {
+			h.lose(c)
+			h.cluster.releaseLease(h.others(), h.req)
+		}
+	case leaseRenew:
+		// Renewals could be queued up behind the release, or the previous
+		// one could still be running.
+		if !h.released && !h.renewing {
+			h.renew(c)
+		}
+	case leaseRenewed:
+		h.renewing = false
+		if !h.released {
+			h.handleRenewed(c, msg)
+		}
+	case leaseRelease:
+		if h.released {
+			c.Respond(struct{}{})
+			return
+		}
+		h.done()
+		h.release(c, c.Sender())
+	}
+}
+
+// renew requests the lease from the members in the background, the outcome
+// is sent back to us as leaseRenewed.
+func (h *leaseHolder) renew(c *actor.Context) {
+	h.renewing = true
+	var (
+		engine  = c.Engine()
+		self    = c.PID()
+		members = h.members.members.Slice()
+	)
+	go func() {
+		now := time.Now()
+		ok, held := h.cluster.requestLease(members, h.req)
+		engine.Send(self, leaseRenewed{ok: ok, held: held, at: now})
+	}()
+}
+
+func (h *leaseHolder) handleRenewed(c *actor.Context, msg leaseRenewed) {
+	if msg.ok {
+		h.validUntil = msg.at.Add(time.Duration(h.req.Ttl))
+		return
+	}
+	// Retry on the next renewal, unless the lease expires before then or it
+	// was granted to another member in the meantime.
+	if len(msg.held) == 0 && time.Now().Add(time.Duration(h.req.Ttl)/3).Before(h.validUntil) {
+		return
+	}
+	slog.Warn("[CLUSTER] lease lost", "name", h.req.Name, "held_by", msg.held)
+	h.lose(c)
+	h.release(c, nil)
+}
+
+// lose tells the holder the lease is lost.
+func (h *leaseHolder) lose(c *actor.Context) {
+	if h.holder != nil {
+		c.Send(h.holder, &LeaseLost{Name: h.req.Name})
+	}
+	h.done()
+}
+
+// done stops renewing the lease and closes the lost channel.
+func (h *leaseHolder) done() {
+	h.released = true
+	close(h.lost)
+}
+
+// release releases the lease on the members in the background and stops us
+// once it's released. The given sender, if any, is told when it's done.
+func (h *leaseHolder) release(c *actor.Context, sender *actor.PID) {
+	var (
+		engine  = c.Engine()
+		self    = c.PID()
+		members = h.members.members.Slice()
+	)
+	go func() {
+		h.cluster.releaseLease(members, h.req)
+		if sender != nil {
+			engine.Send(sender, struct{}{})
+		}
+		engine.Poison(self)
+	}()
+}
+
+// others returns the members except this one.
+func (h *leaseHolder) others() []*Member {
+	return slices.DeleteFunc(h.members.members.Slice(), func(m *Member) bool {
+		return m.ID == h.cluster.config.id
+	})
+}
@@ -0,0 +1,135 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// leaseLostReceiver spawns a holder that sends the name of the leases it
+// loses on the returned channel.
+func leaseLostReceiver(c *Cluster) (*actor.PID, <-chan string) {
+	lost := make(chan string, 1)
+	pid := c.Engine().SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*LeaseLost); ok {
+			lost <- msg.Name
+		}
+	}, "holder")
+	return pid, lost
+}
+
+func TestAcquireLease(t *testing.T) {
+	clusters := makeLeaveClusters(t, newCounter, NewKindConfig())
+	ttl := time.Millisecond * 150
+
+	lease, err := clusters[0].AcquireLease("job", ttl, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "job", lease.Name())
+	_, err = clusters[1].AcquireLease("job", ttl, nil)
+	assert.ErrorContains(t, err, "held by member A")
+
+	// The lease is renewed while it's held.
+	time.Sleep(ttl * 3)
+	_, err = clusters[2].AcquireLease("job", ttl, nil)
+	assert.ErrorContains(t, err, "held by member A")
+	select {
+	case <-lease.Done():
+		t.Fatal("lease lost")
+	default:
+	}
+
+	lease.Release()
+	<-lease.Done()
+	other, err := clusters[1].AcquireLease("job", ttl, nil)
+	require.NoError(t, err)
+	other.Release()
+
+	_, err = clusters[1].AcquireLease("job", 0, nil)
+	assert.Error(t, err)
+}
+
+func TestLeaseLost(t *testing.T) {
+	clusters := makeLeaveClusters(t, newCounter, NewKindConfig())
+	holder, lost := leaseLostReceiver(clusters[0])
+	lease, err := clusters[0].AcquireLease("job", time.Millisecond*150, holder)
+	require.NoError(t, err)
+
+	// B and C consider A failed and B takes over the lease, A finds out on
+	// its next renewal.
+	for _, c := range clusters[1:] {
+		c.Engine().Send(leaseTablePID(c.Member()), leaseHolderLeft{memberID: "A"})
+	}
+	require.Eventually(t, func() bool {
+		_, err := clusters[1].AcquireLease("job", time.Minute, nil)
+		return err == nil
+	}, time.Second, time.Millisecond*10)
+
+	select {
+	case name := <-lost:
+		assert.Equal(t, "job", name)
+	case <-time.After(time.Second):
+		t.Fatal("lease not lost")
+	}
+	<-lease.Done()
+}
+
+func TestLeaseLostOnMemberLeave(t *testing.T) {
+	clusters := makeLeaveClusters(t, newCounter, NewKindConfig())
+	holder, lost := leaseLostReceiver(clusters[0])
+	_, err := clusters[0].AcquireLease("job", time.Minute, holder)
+	require.NoError(t, err)
+
+	require.NoError(t, clusters[0].Leave(context.Background()))
+	assert.Equal(t, "job", <-lost)
+
+	// The lease of the member that left is dropped right away.
+	require.Eventually(t, func() bool {
+		_, err := clusters[1].AcquireLease("job", time.Minute, nil)
+		return err == nil
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestLeaseLostOnPartition(t *testing.T) {
+	members := makePartitionMembers(t, 3, nil)
+	a, b := members[0].cluster, members[1].cluster
+	holder, lost := leaseLostReceiver(a)
+	_, err := a.AcquireLease("job", time.Millisecond*300, holder)
+	require.NoError(t, err)
+
+	// Both sides of the partition consider the other side failed.
+	partition(members[:1], members[1:])
+	require.Eventually(t, func() bool {
+		return len(a.Members()) == 1 && len(b.Members()) == 2
+	}, time.Second*3, time.Millisecond*10)
+
+	// A can't renew the lease with only itself, while B and C are a majority.
+	select {
+	case name := <-lost:
+		assert.Equal(t, "job", name)
+	case <-time.After(time.Second * 3):
+		t.Fatal("lease not lost")
+	}
+	_, err = a.AcquireLease("other", time.Minute, nil)
+	assert.ErrorContains(t, err, "no quorum")
+	require.Eventually(t, func() bool {
+		_, err := b.AcquireLease("job", time.Minute, nil)
+		return err == nil
+	}, time.Second*2, time.Millisecond*10)
+}
+
+func TestLeaseReleasedOnStop(t *testing.T) {
+	clusters := makeLeaveClusters(t, newCounter, NewKindConfig())
+	_, err := clusters[0].AcquireLease("job", time.Minute, nil)
+	require.NoError(t, err)
+
+	// Stopping doesn't wait for the lease table, which stops the holder.
+	start := time.Now()
+	clusters[0].Stop()
+	assert.Less(t, time.Since(start), defaultRequestTimeout)
+	_, err = clusters[1].AcquireLease("job", time.Minute, nil)
+	assert.NoError(t, err)
+}
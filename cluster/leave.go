@@ -0,0 +1,207 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
+
+type (
+	leave struct{}
+	// handoffState asks an activation for the state it hands off.
+	handoffState       struct{}
+	handoffStateResult struct {
+		state []byte
+		err   error
+	}
+)
+
+// Handoff is implemented by actors that carry their state over to their new
+// activation when the member hosting them leaves the cluster. The kind of the
+// actor needs to have handoff enabled, see KindConfig.WithHandoff.
+type Handoff interface {
+	// HandoffState returns the state of the actor. It's called on the
+	// activation of the member that leaves, right before it's stopped.
+	HandoffState() ([]byte, error)
+	// RestoreHandoff restores the given state on the new activation, before
+	// it receives actor.Initialized.
+	RestoreHandoff(state []byte) error
+}
+
+// Leave gracefully removes the member from the cluster. The other members
+// are told right away, instead of finding out by failure detection, and no
+// new activations are accepted. The shards of the sharded kinds are handed
+// off to the other members, the activations of kinds with handoff enabled
+// are reactivated on the other members, the other activations are
+// deactivated. Finally, the cluster is stopped.
+//
+// An error is returned if not all the activations could be handed off
+// before the context is done.
+func (c *Cluster) Leave(ctx context.Context) error {
+	defer c.Stop()
+
+	// Hand off the shards while the coordinator, which could be one of our
+	// activations, is still running.
+	c.stopRegions(c.timeout(ctx))
+	resp, err := c.engine.Request(c.agentPID, leave{}, c.timeout(ctx)).Result()
+	if err != nil {
+		return err
+	}
+	pids, _ := resp.([]*actor.PID)
+	var errs []error
+	for _, pid := range pids {
+		if err := ctx.Err(); err != nil {
+			// We are out of time, make sure we don't leave orphans behind.
+			c.engine.Poison(pid)
+			errs = append(errs, fmt.Errorf("handoff of %s: %w", pid.ID, err))
+			continue
+		}
+		if err := c.handoff(ctx, pid); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// handoff stops the given local activation and, if its kind has handoff
+// enabled, activates it on another member with the state it handed off.
+func (c *Cluster) handoff(ctx context.Context, pid *actor.PID) error {
+	name := actor.KindOf(pid)
+	idx := slices.IndexFunc(c.kinds, func(k kind) bool { return k.name == name })
+	if idx < 0 || !c.kinds[idx].config.handoff || c.kinds[idx].config.singleton {
+		<-c.engine.PoisonCtx(ctx, pid).Done()
+		return nil
+	}
+
+	config := NewActivationConfig().WithID(strings.TrimPrefix(pid.ID, name+"/"))
+	resp, err := c.engine.Request(pid, handoffState{}, c.timeout(ctx)).Result()
+	if err != nil {
+		slog.Warn("handoff without state", "pid", pid, "err", err)
+	} else if result, ok := resp.(handoffStateResult); ok {
+		if result.err != nil {
+			slog.Error("handoff without state", "pid", pid, "err", result.err)
+		}
+		config.handoff = result.state
+	}
+	<-c.engine.PoisonCtx(ctx, pid).Done()
+
+	msg := activate{
+		kind:   name,
+		config: config,
+	}
+	resp, err = c.engine.Request(c.agentPID, msg, c.timeout(ctx)).Result()
+	if err != nil {
+		return fmt.Errorf("handoff of %s: %w", pid.ID, err)
+	}
+	if newPID, _ := resp.(*actor.PID); newPID == nil {
+		return fmt.Errorf("handoff of %s: activation failed", pid.ID)
+	}
+	return nil
+}
+
+// timeout returns the request timeout, bounded by the deadline of the given
+// context.
+func (c *Cluster) timeout(ctx context.Context) time.Duration {
+	timeout := c.config.requestTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+	return timeout
+}
+
+// handleLeave stops accepting activations and tells the other members we
+// leave. It responds with the local activations that need to be handed off.
+func (a *Agent) handleLeave(c *actor.Context) {
+	var (
+		self  = a.cluster.Member()
+		local = a.localActivations()
+	)
+	a.leaving = true
+
+	msg := &MembersLeave{Members: []*Member{self}}
+	a.members.ForEach(func(member *Member) bool {
+		if member.ID != self.ID {
+			a.cluster.engine.Send(member.PID(), msg)
+			a.cluster.engine.Send(memberToProviderPID(member), msg)
+		}
+		return true
+	})
+	a.handleMembersLeave(msg.Members)
+	c.Respond(local)
+}
+
+// localActivations returns the activations of the locally registered kinds
+// that are hosted on this member.
+func (a *Agent) localActivations() []*actor.PID {
+	local := make([]*actor.PID, 0)
+	for _, pid := range a.activated {
+		if pid.Address == a.cluster.engine.Address() && a.hasKindLocal(actor.KindOf(pid)) {
+			local = append(local, pid)
+		}
+	}
+	return local
+}
+
+// handleMembersLeave removes the members that gracefully left the cluster.
+// They are remembered, so they are not added back when the provider did not
+// notice they left yet.
+func (a *Agent) handleMembersLeave(members []*Member) {
+	for _, member := range members {
+		a.departed[member.ID] = member
+		delete(a.quorum, member.ID)
+		if known := a.members.GetByID(member.ID); known != nil {
+			a.memberLeave(known)
+		}
+	}
+	a.topologyHash = a.members.TopologyHash()
+	a.ensureSingletons()
+}
+
+// withoutDeparted returns the given members without the ones that left the
+// cluster, unless they were restarted since.
+func (a *Agent) withoutDeparted(members []*Member) []*Member {
+	for id := range a.departed {
+		if !slices.ContainsFunc(members, func(m *Member) bool { return m.ID == id }) {
+			delete(a.departed, id)
+		}
+	}
+	return slices.DeleteFunc(slices.Clone(members), func(m *Member) bool {
+		departed, ok := a.departed[m.ID]
+		return ok && m.Started <= departed.Started
+	})
+}
+
+// withHandoff returns a middleware that hands off the state of the actor
+// when asked and restores the given state, if any, on the new activation.
+func withHandoff(state []byte) actor.MiddlewareFunc {
+	return func(next actor.ReceiveFunc) actor.ReceiveFunc {
+		return func(ctx *actor.Context) {
+			switch ctx.Message().(type) {
+			case actor.Initialized:
+				if h, ok := ctx.Receiver().(Handoff); ok && state != nil {
+					if err := h.RestoreHandoff(state); err != nil {
+						slog.Error("failed to restore handoff", "pid", ctx.PID(), "err", err)
+					}
+				}
+				state = nil
+				next(ctx)
+			case handoffState:
+				h, ok := ctx.Receiver().(Handoff)
+				if !ok {
+					ctx.Respond(handoffStateResult{})
+					return
+				}
+				b, err := h.HandoffState()
+				ctx.Respond(handoffStateResult{state: b, err: err})
+			default:
+				next(ctx)
+			}
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package cluster
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handoffCounter is a counter that carries its count over on handoff.
+type handoffCounter struct {
+	counter
+	restored bool
+}
+
+func newHandoffCounter() actor.Receiver {
+	return &handoffCounter{}
+}
+
+func (c *handoffCounter) HandoffState() ([]byte, error) {
+	return []byte(strconv.Itoa(c.n)), nil
+}
+
+func (c *handoffCounter) RestoreHandoff(state []byte) error {
+	n, err := strconv.Atoi(string(state))
+	c.n = n
+	c.restored = true
+	return err
+}
+
+// makeLeaveClusters starts three members with the given kind registered,
+// the failure detector is slow enough to not notice anyone leaving. The
+// given opts are applied to the config of every member.
+func makeLeaveClusters(t *testing.T, producer actor.Producer, config KindConfig, opts ...func(Config) Config) []*Cluster {
+	var (
+		ids          = []string{"A", "B", "C"}
+		clusters     = make([]*Cluster, len(ids))
+		bootstrap    MemberAddr
+		detectorConf = NewFailureDetectorConfig().WithHeartbeatInterval(time.Minute)
+	)
+	for i, id := range ids {
+		addr := getRandomLocalhostAddr()
+		smConfig := NewSelfManagedConfig().WithDiscovery().WithFailureDetector(detectorConf)
+		if i > 0 {
+			smConfig = smConfig.WithBootstrapMember(bootstrap)
+		} else {
+			bootstrap = MemberAddr{ListenAddr: addr, ID: id}
+		}
+		clusterConfig := NewConfig().
+			WithID(id).
+			WithListenAddr(addr).
+			WithProvider(NewSelfManagedProvider(smConfig))
+		for _, opt := range opts {
+			clusterConfig = opt(clusterConfig)
+		}
+		c, err := New(clusterConfig)
+		require.NoError(t, err)
+		c.RegisterKind("counter", producer, config)
+		c.Start()
+		t.Cleanup(c.Stop)
+		clusters[i] = c
+	}
+	for _, c := range clusters {
+		require.Eventually(t, func() bool {
+			return len(c.Members()) == len(ids)
+		}, 2*time.Second, 10*time.Millisecond)
+	}
+	return clusters
+}
+
+// hostOf returns the cluster hosting the given PID and the other ones.
+func hostOf(clusters []*Cluster, pid *actor.PID) (*Cluster, []*Cluster) {
+	var (
+		host   *Cluster
+		others []*Cluster
+	)
+	for _, c := range clusters {
+		if c.Address() == pid.Address {
+			host = c
+		} else {
+			others = append(others, c)
+		}
+	}
+	return host, others
+}
+
+func TestLeaveHandoff(t *testing.T) {
+	clusters := makeLeaveClusters(t, newHandoffCounter, NewKindConfig().WithHandoff(true))
+
+	pid := clusters[0].Activate("counter", NewActivationConfig().WithID("1"))
+	require.NotNil(t, pid)
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, i, requestCount(t, clusters[0], pid))
+	}
+
+	host, others := hostOf(clusters, pid)
+	require.NotNil(t, host)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, host.Leave(ctx))
+
+	for _, c := range others {
+		// the other members know soon, the failure detector did not kick in.
+		assert.Eventually(t, func() bool {
+			active := c.GetActiveByID("counter/1")
+			return len(c.Members()) == 2 && active != nil && active.Address != host.Address()
+		}, time.Second, 10*time.Millisecond)
+	}
+	// the count was handed off to the new activation.
+	assert.Equal(t, 4, requestCount(t, others[0], others[0].Get("counter", "1")))
+}
+
+func TestLeaveWithoutHandoff(t *testing.T) {
+	clusters := makeLeaveClusters(t, newCounter, NewKindConfig())
+
+	pid := clusters[0].Activate("counter", NewActivationConfig().WithID("1"))
+	require.NotNil(t, pid)
+	assert.Equal(t, 1, requestCount(t, clusters[0], pid))
+
+	host, others := hostOf(clusters, pid)
+	require.NoError(t, host.Leave(context.Background()))
+	assert.Nil(t, host.Engine().Registry.GetPID("counter", "1"))
+
+	for _, c := range others {
+		// the other members know soon, the failure detector did not kick in.
+		assert.Eventually(t, func() bool {
+			return len(c.Members()) == 2 && c.GetActiveByID("counter/1") == nil
+		}, time.Second, 10*time.Millisecond)
+	}
+	// the grain reactivates it on one of the remaining members.
+	assert.Equal(t, 1, requestCount(t, others[0], others[0].Get("counter", "1")))
+}
+
+func TestActivationIgnoresHandoffOfKindWithoutHandoff(t *testing.T) {
+	c := makeCluster(t, getRandomLocalhostAddr(), "A", "eu")
+	c.RegisterKind("counter", newHandoffCounter, NewKindConfig())
+	c.Start()
+	t.Cleanup(c.Stop)
+	require.Eventually(t, func() bool {
+		return len(c.Members()) == 1
+	}, time.Second, time.Millisecond*10)
+
+	req := &ActivationRequest{
+		Kind:         "counter",
+		ID:           "1",
+		TopologyHash: NewMemberSet(c.Members()...).TopologyHash(),
+		Handoff:      []byte("5"),
+	}
+	resp, err := c.Engine().Request(c.PID(), req, time.Second).Result()
+	require.NoError(t, err)
+	r, ok := resp.(*ActivationResponse)
+	require.True(t, ok)
+	require.True(t, r.Success)
+	assert.Equal(t, 1, requestCount(t, c, r.PID))
+}
+
+func TestLeaveRejectsActivations(t *testing.T) {
+	c := startKindCluster(t, "eu", NewKindConfig())
+	_, err := c.engine.Request(c.PID(), leave{}, time.Second).Result()
+	require.NoError(t, err)
+
+	resp := requestActivation(t, c, "1")
+	assert.False(t, resp.Success)
+	assert.Equal(t, ReasonMemberLeaving, resp.Reason)
+}
@@ -1,5 +1,14 @@
 package cluster
 
+import (
+	"slices"
+	"strings"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/zeebo/xxh3"
+	"google.golang.org/protobuf/proto"
+)
+
 type MemberSet struct {
 	members map[string]*Member
 }
@@ -91,3 +100,122 @@ func (s *MemberSet) FilterByKind(kind string) []*Member {
 	}
 	return members
 }
+
+// TopologyHash returns a hash of the members in the set. Two sets containing
+// the same members always produce the same hash, regardless of insertion order.
+func (s *MemberSet) TopologyHash() uint64 {
+	ids := make([]string, 0, len(s.members))
+	for _, member := range s.members {
+		ids = append(ids, member.ID+"@"+member.Host)
+	}
+	slices.Sort(ids)
+	h := xxh3.New()
+	for _, id := range ids {
+		_, _ = h.WriteString(id)
+		_, _ = h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// Digest returns a hash of the full members in the set, including the time
+// they were started, their kinds, region, labels and capacity. Unlike the
+// TopologyHash it changes when a member restarts on the same ID and host, or
+// when any of its properties change.
+func (s *MemberSet) Digest() uint64 {
+	members := s.Slice()
+	slices.SortFunc(members, func(a, b *Member) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	opts := proto.MarshalOptions{Deterministic: true}
+	h := xxh3.New()
+	for _, member := range members {
+		b, _ := opts.Marshal(member)
+		_, _ = h.Write(b)
+		_, _ = h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// GetByID returns the member with the given ID, or nil if it is not part of
+// the set.
+func (s *MemberSet) GetByID(id string) *Member {
+	return s.members[id]
+}
+
+// memberSnapshot carries the members of the cluster to a memberWatch.
+type memberSnapshot struct {
+	members []*Member
+}
+
+// memberWatch keeps track of the members of the cluster for an actor, from
+// the member events and a snapshot it gets when it's started, so the actor
+// doesn't have to ask the agent while it handles a message.
+type memberWatch struct {
+	members *MemberSet
+	// the IDs of the members that left since we started, the snapshot could
+	// be older than their leave event.
+	left        map[string]bool
+	eventSubPID *actor.PID
+}
+
+func newMemberWatch() *memberWatch {
+	return &memberWatch{
+		members: NewMemberSet(),
+		left:    make(map[string]bool),
+	}
+}
+
+// start subscribes to the member events and requests the snapshot in the
+// background. The actor passes the messages it receives to handle.
+func (w *memberWatch) start(c *actor.Context, cluster *Cluster) {
+	w.eventSubPID = c.SpawnChildFunc(func(ctx *actor.Context) {
+		switch msg := ctx.Message().(type) {
+		case MemberJoinEvent, MemberLeaveEvent:
+			ctx.Send(ctx.Parent(), msg)
+		}
+	}, "members")
+	c.Engine().Subscribe(w.eventSubPID)
+	var (
+		engine = c.Engine()
+		self   = c.PID()
+	)
+	go func() {
+		engine.Send(self, memberSnapshot{members: cluster.Members()})
+	}()
+}
+
+func (w *memberWatch) stop(c *actor.Context) {
+	c.Engine().Unsubscribe(w.eventSubPID)
+}
+
+// handle updates the members with the given message, it returns false if the
+// message is not about the members.
+func (w *memberWatch) handle(msg any) bool {
+	switch msg := msg.(type) {
+	case MemberJoinEvent:
+		delete(w.left, msg.Member.ID)
+		w.members.Add(msg.Member)
+	case MemberLeaveEvent:
+		w.left[msg.Member.ID] = true
+		w.members.Remove(msg.Member)
+	case memberSnapshot:
+		for _, member := range msg.members {
+			if !w.left[member.ID] && !w.members.Contains(member) {
+				w.members.Add(member)
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// hosts returns the hosts of the members.
+func (w *memberWatch) hosts() map[string]bool {
+	hosts := make(map[string]bool, w.members.Len())
+	w.members.ForEach(func(m *Member) bool {
+		hosts[m.Host] = true
+		return true
+	})
+	return hosts
+}
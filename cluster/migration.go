@@ -0,0 +1,206 @@
+package cluster
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
+
+// migrationRedirectTimeout is how long the old activation of a migrated
+// actor redirects the messages of senders that hold on to its PID.
+var migrationRedirectTimeout = time.Minute
+
+type (
+	// migrationPause pauses an activation, it buffers the messages it
+	// receives from then on and responds with its state.
+	migrationPause  struct{}
+	migrationPaused struct {
+		state []byte
+		err   error
+	}
+	// migrationResume lets a paused activation process the buffered
+	// messages, when the migration failed.
+	migrationResume struct{}
+	// migrationForward makes a paused activation forward the buffered
+	// messages, and the messages it receives from then on, to the new
+	// activation.
+	migrationForward struct {
+		pid *actor.PID
+	}
+	// redirectExpired stops the old activation of a migrated actor.
+	redirectExpired struct {
+		pid *actor.PID
+	}
+	// redirectStopped is sent to the agent once the old activation of a
+	// migrated actor stopped.
+	redirectStopped struct {
+		id string
+	}
+)
+
+// Migrate moves the given activation, hosted on this member, to the target
+// member while it keeps running. The activation is paused, its state is
+// carried over to the new activation on the target when the actor implements
+// the Handoff interface, and the messages it received in the meantime are
+// forwarded. The old activation keeps redirecting the messages of senders
+// that still use its PID for a while.
+//
+// The new PID of the activation is returned.
+//
+//	pid, err := c.Migrate(playerPID, target)
+func (c *Cluster) Migrate(pid *actor.PID, target *Member) (*actor.PID, error) {
+	if pid.Address != c.engine.Address() {
+		return nil, fmt.Errorf("migrate %s: not hosted on this member", pid.ID)
+	}
+	if target.Host == c.engine.Address() {
+		return nil, fmt.Errorf("migrate %s: already hosted on the target", pid.ID)
+	}
+	name := actor.KindOf(pid)
+	idx := slices.IndexFunc(c.kinds, func(k kind) bool { return k.name == name })
+	if idx < 0 {
+		return nil, fmt.Errorf("migrate %s: kind not registered", pid.ID)
+	}
+	if c.kinds[idx].config.singleton {
+		return nil, fmt.Errorf("migrate %s: singletons are not migrated", pid.ID)
+	}
+
+	resp, err := c.engine.Request(pid, migrationPause{}, c.config.requestTimeout).Result()
+	if err != nil {
+		return nil, fmt.Errorf("migrate %s: %w", pid.ID, err)
+	}
+	paused, ok := resp.(migrationPaused)
+	if !ok {
+		return nil, fmt.Errorf("migrate %s: not an activation", pid.ID)
+	}
+	if paused.err != nil {
+		c.engine.Send(pid, migrationResume{})
+		return nil, fmt.Errorf("migrate %s: %w", pid.ID, paused.err)
+	}
+
+	config := NewActivationConfig().
+		WithID(strings.TrimPrefix(pid.ID, name+"/")).
+		WithSelectMemberFunc(func(details ActivationDetails) *Member {
+			idx := slices.IndexFunc(details.Members, target.Equals)
+			if idx < 0 {
+				return nil
+			}
+			return details.Members[idx]
+		})
+	config.handoff = paused.state
+	config.migrate = pid
+	resp, err = c.engine.Request(c.agentPID, activate{kind: name, config: config}, c.config.requestTimeout).Result()
+	newPID, _ := resp.(*actor.PID)
+	if err != nil || newPID == nil {
+		c.engine.Send(pid, migrationResume{})
+		if err == nil {
+			err = fmt.Errorf("activation on %s failed", target.ID)
+		}
+		return nil, fmt.Errorf("migrate %s: %w", pid.ID, err)
+	}
+	c.engine.Send(pid, migrationForward{pid: newPID})
+	c.engine.BroadcastEvent(MigrationEvent{From: pid, To: newPID})
+	return newPID, nil
+}
+
+// migrated replaces the old activation of a migrated identity by the new
+// one. The old activation stays around to redirect messages, until the
+// redirect expires.
+func (a *Agent) migrated(old, pid *actor.PID) {
+	a.removeActivated(old)
+	a.redirects[old.ID] = old
+	time.AfterFunc(migrationRedirectTimeout, func() {
+		a.cluster.engine.Send(a.cluster.agentPID, redirectExpired{pid: old})
+	})
+	a.bcast(&Deactivation{PID: old})
+}
+
+// handleRedirectExpired stops the old activation of a migrated identity,
+// unless it was replaced since.
+func (a *Agent) handleRedirectExpired(pid *actor.PID) {
+	if redirect, ok := a.redirects[pid.ID]; ok && redirect.Equals(pid) {
+		a.poisonRedirect(pid)
+	}
+}
+
+// stopRedirect stops the old activation of the given identity right away,
+// so the identity can be activated on this member again. It returns true
+// while the old activation is stopping.
+func (a *Agent) stopRedirect(id string) bool {
+	if pid, ok := a.redirects[id]; ok {
+		a.poisonRedirect(pid)
+	}
+	return a.draining[id]
+}
+
+// poisonRedirect stops the old activation of a migrated identity without
+// waiting for it, the agent gets a redirectStopped once it stopped.
+func (a *Agent) poisonRedirect(pid *actor.PID) {
+	delete(a.redirects, pid.ID)
+	a.draining[pid.ID] = true
+	done := a.cluster.engine.Poison(pid).Done()
+	go func() {
+		<-done
+		a.cluster.engine.Send(a.cluster.agentPID, redirectStopped{id: pid.ID})
+	}()
+}
+
+// isRedirect returns true if the given PID is the old activation of a
+// migrated identity.
+func (a *Agent) isRedirect(pid *actor.PID) bool {
+	redirect, ok := a.redirects[pid.ID]
+	return ok && redirect.Equals(pid)
+}
+
+// withMigration returns a middleware that pauses the activation while it's
+// migrated, and forwards the messages to the new activation once it's
+// migrated.
+func withMigration() actor.MiddlewareFunc {
+	return func(next actor.ReceiveFunc) actor.ReceiveFunc {
+		var (
+			paused   bool
+			buffered []actor.Envelope
+			forward  *actor.PID
+		)
+		return func(ctx *actor.Context) {
+			switch msg := ctx.Message().(type) {
+			case actor.Started, actor.Initialized, actor.Stopped:
+				next(ctx)
+			case migrationPause:
+				paused = true
+				h, ok := ctx.Receiver().(Handoff)
+				if !ok {
+					ctx.Respond(migrationPaused{})
+					return
+				}
+				state, err := h.HandoffState()
+				ctx.Respond(migrationPaused{state: state, err: err})
+			case migrationResume:
+				// The buffered messages are processed right away, before
+				// the messages that arrived after them.
+				paused = false
+				for _, env := range buffered {
+					ctx.Replay(env, next)
+				}
+				buffered = nil
+			case migrationForward:
+				forward = msg.pid
+				for _, env := range buffered {
+					ctx.Engine().SendEnvelope(forward, env)
+				}
+				buffered = nil
+			default:
+				switch {
+				case forward != nil:
+					ctx.Engine().SendEnvelope(forward, ctx.Envelope())
+				case paused:
+					buffered = append(buffered, ctx.Envelope())
+				default:
+					next(ctx)
+				}
+			}
+		}
+	}
+}
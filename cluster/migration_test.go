@@ -0,0 +1,152 @@
+package cluster
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/khulnasoft/goactors/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	clusters := makeLeaveClusters(t, newHandoffCounter, NewKindConfig())
+	pid := clusters[0].Activate("counter", NewActivationConfig().WithID("1"))
+	require.NotNil(t, pid)
+	host, others := hostOf(clusters, pid)
+	for i := range 3 {
+		assert.Equal(t, i+1, requestCount(t, others[1], pid))
+	}
+
+	// Keep sending to the old PID while the actor migrates, none of the
+	// messages may get lost.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 50 {
+			others[1].Engine().Send(pid, &remote.TestMessage{})
+		}
+	}()
+	target := others[0].Member()
+	newPID, err := host.Migrate(pid, target)
+	require.NoError(t, err)
+	assert.Equal(t, target.Host, newPID.Address)
+	assert.Equal(t, pid.ID, newPID.ID)
+	wg.Wait()
+
+	// The old PID redirects to the new activation.
+	assert.Equal(t, 54, requestCount(t, others[1], pid))
+	assert.Equal(t, 55, requestCount(t, others[1], newPID))
+
+	for _, c := range clusters {
+		require.Eventually(t, func() bool {
+			active := c.GetActiveByID("counter/1")
+			return active != nil && active.Equals(newPID)
+		}, time.Second, time.Millisecond*10)
+	}
+
+	// Migrating back stops the redirect of the old activation.
+	backPID, err := others[0].Migrate(newPID, host.Member())
+	require.NoError(t, err)
+	assert.Equal(t, host.Address(), backPID.Address)
+	assert.Equal(t, 56, requestCount(t, others[1], backPID))
+	assert.Equal(t, 57, requestCount(t, others[1], newPID))
+}
+
+func TestMigrateRedirectExpires(t *testing.T) {
+	timeout := migrationRedirectTimeout
+	migrationRedirectTimeout = time.Millisecond * 50
+	t.Cleanup(func() { migrationRedirectTimeout = timeout })
+
+	clusters := makeLeaveClusters(t, newHandoffCounter, NewKindConfig())
+	pid := clusters[0].Activate("counter", NewActivationConfig().WithID("1"))
+	require.NotNil(t, pid)
+	host, others := hostOf(clusters, pid)
+
+	_, err := host.Migrate(pid, others[0].Member())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return host.Engine().Registry.GetPID("counter", "1") == nil
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestMigrateWithoutState(t *testing.T) {
+	clusters := makeLeaveClusters(t, newCounter, NewKindConfig())
+	pid := clusters[0].Activate("counter", NewActivationConfig().WithID("1"))
+	require.NotNil(t, pid)
+	host, others := hostOf(clusters, pid)
+	assert.Equal(t, 1, requestCount(t, host, pid))
+
+	newPID, err := host.Migrate(pid, others[0].Member())
+	require.NoError(t, err)
+	// The counter does not hand off its state, it starts over.
+	assert.Equal(t, 1, requestCount(t, host, newPID))
+}
+
+func TestMigrateErrors(t *testing.T) {
+	clusters := makeLeaveClusters(t, newHandoffCounter, NewKindConfig())
+	pid := clusters[0].Activate("counter", NewActivationConfig().WithID("1"))
+	require.NotNil(t, pid)
+	host, others := hostOf(clusters, pid)
+
+	_, err := others[0].Migrate(pid, others[1].Member())
+	assert.ErrorContains(t, err, "not hosted on this member")
+	_, err = host.Migrate(pid, host.Member())
+	assert.ErrorContains(t, err, "already hosted on the target")
+	_, err = host.Migrate(actor.NewPID(host.Address(), "counter/2"), others[0].Member())
+	assert.Error(t, err)
+
+	// A member that is not part of the cluster can't be selected, the actor
+	// resumes on its current member.
+	_, err = host.Migrate(pid, &Member{ID: "X", Host: "127.0.0.1:1"})
+	assert.ErrorContains(t, err, "activation on X failed")
+	assert.Equal(t, 1, requestCount(t, host, pid))
+	assert.Equal(t, pid, host.GetActiveByID("counter/1"))
+}
+
+func TestMigrationResumeKeepsOrder(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	received := make(chan string, 4)
+	pid := e.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*remote.TestMessage); ok {
+			received <- string(msg.Data)
+		}
+	}, "counter", actor.WithMiddleware(withMigration()))
+
+	_, err = e.Request(pid, migrationPause{}, time.Second).Result()
+	require.NoError(t, err)
+	for _, data := range []string{"1", "2", "3"} {
+		e.Send(pid, &remote.TestMessage{Data: []byte(data)})
+	}
+	e.Send(pid, migrationResume{})
+	e.Send(pid, &remote.TestMessage{Data: []byte("4")})
+
+	for _, data := range []string{"1", "2", "3", "4"} {
+		assert.Equal(t, data, <-received)
+	}
+}
+
+func TestMigrationForwardsEnvelope(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	results := make(chan envelopeResult, 2)
+	target := e.Spawn(func() actor.Receiver {
+		return envelopeProbe{results: results}
+	}, "probe")
+	pid := e.SpawnFunc(func(c *actor.Context) {}, "counter", actor.WithMiddleware(withMigration()))
+
+	_, err = e.Request(pid, migrationPause{}, time.Second).Result()
+	require.NoError(t, err)
+	// buffered while paused.
+	e.SendWithHeaders(pid, &remote.TestMessage{}, actor.Header{"id": "1"})
+	e.Send(pid, migrationForward{pid: target})
+	assert.Equal(t, envelopeResult{header: "1"}, <-results)
+	// forwarded once migrated.
+	_, err = e.Request(pid, &remote.TestMessage{}, time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, envelopeResult{hasDeadline: true}, <-results)
+}
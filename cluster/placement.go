@@ -0,0 +1,97 @@
+package cluster
+
+import (
+	"slices"
+)
+
+// SelectRegionAffinity returns a SelectMemberFunc that selects one of the
+// members in the region of the activation with the given function. When
+// none of the members is hosted in that region, it selects one of all the
+// members instead.
+//
+//	config := cluster.NewActivationConfig().
+//		WithRegion("eu-west").
+//		WithSelectMemberFunc(cluster.SelectRegionAffinity(cluster.SelectRendezvousMember))
+func SelectRegionAffinity(next SelectMemberFunc) SelectMemberFunc {
+	return func(details ActivationDetails) *Member {
+		inRegion := slices.DeleteFunc(slices.Clone(details.Members), func(m *Member) bool {
+			return m.Region != details.Region
+		})
+		if len(inRegion) > 0 {
+			details.Members = inRegion
+		}
+		return next(details)
+	}
+}
+
+// SelectByLabels returns a SelectMemberFunc that selects one of the members
+// that have all the labels of the selector with the given function. Unlike
+// region affinity, the selector is a constraint: when none of the members
+// match, the activation fails.
+//
+//	config := cluster.NewActivationConfig().
+//		WithSelectMemberFunc(cluster.SelectByLabels(map[string]string{"gpu": "true"}, cluster.SelectLeastActivationsMember))
+func SelectByLabels(selector map[string]string, next SelectMemberFunc) SelectMemberFunc {
+	return func(details ActivationDetails) *Member {
+		details.Members = slices.DeleteFunc(slices.Clone(details.Members), func(m *Member) bool {
+			return !m.HasLabels(selector)
+		})
+		if len(details.Members) == 0 {
+			return nil
+		}
+		return next(details)
+	}
+}
+
+// SelectLeastActivationsMember selects the member that hosts the least
+// activations.
+func SelectLeastActivationsMember(details ActivationDetails) *Member {
+	var selected *Member
+	for _, member := range details.Members {
+		if selected == nil {
+			selected = member
+			continue
+		}
+		n, min := details.Activations[member.ID], details.Activations[selected.ID]
+		if n < min || (n == min && member.ID < selected.ID) {
+			selected = member
+		}
+	}
+	return selected
+}
+
+// SelectCapacityWeightedMember selects the member that hosts the least
+// activations relative to its advertised capacity, which spreads the
+// activations over the members proportional to their capacity. Members
+// without capacity are only selected when no other member is available.
+func SelectCapacityWeightedMember(details ActivationDetails) *Member {
+	var selected *Member
+	for _, member := range details.Members {
+		if selected == nil || lessLoaded(member, selected, details.Activations) {
+			selected = member
+		}
+	}
+	return selected
+}
+
+// lessLoaded returns true if member a would be less loaded than member b
+// relative to their capacity, with one more activation. Ties are broken by
+// ID, so that every member comes to the same conclusion.
+func lessLoaded(a, b *Member, activations map[string]int) bool {
+	if (a.Capacity == 0) != (b.Capacity == 0) {
+		return b.Capacity == 0
+	}
+	if a.Capacity == 0 {
+		return SelectLeastActivationsMember(ActivationDetails{
+			Members:     []*Member{a, b},
+			Activations: activations,
+		}) == a
+	}
+	// compare (na+1)/ca < (nb+1)/cb without dividing.
+	la := uint64(activations[a.ID]+1) * uint64(b.Capacity)
+	lb := uint64(activations[b.ID]+1) * uint64(a.Capacity)
+	if la != lb {
+		return la < lb
+	}
+	return a.ID < b.ID
+}
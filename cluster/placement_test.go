@@ -0,0 +1,138 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func placementMembers() []*Member {
+	return []*Member{
+		{ID: "A", Region: "eu", Labels: map[string]string{"gpu": "true", "zone": "a"}, Capacity: 1},
+		{ID: "B", Region: "eu", Labels: map[string]string{"zone": "b"}, Capacity: 2},
+		{ID: "C", Region: "us", Labels: map[string]string{"gpu": "true", "zone": "c"}, Capacity: 4},
+	}
+}
+
+func TestSelectRegionAffinity(t *testing.T) {
+	selectMember := SelectRegionAffinity(SelectLeastActivationsMember)
+	details := ActivationDetails{
+		Members:     placementMembers(),
+		Region:      "us",
+		Activations: map[string]int{"A": 1, "C": 10},
+	}
+	assert.Equal(t, "C", selectMember(details).ID)
+
+	// no member in the region, fall back on all members.
+	details.Region = "asia"
+	assert.Equal(t, "B", selectMember(details).ID)
+}
+
+func TestSelectByLabels(t *testing.T) {
+	details := ActivationDetails{
+		Members:     placementMembers(),
+		Activations: map[string]int{"A": 2, "B": 0, "C": 1},
+	}
+	selectMember := SelectByLabels(map[string]string{"gpu": "true"}, SelectLeastActivationsMember)
+	assert.Equal(t, "C", selectMember(details).ID)
+	selectMember = SelectByLabels(map[string]string{"gpu": "true", "zone": "a"}, SelectLeastActivationsMember)
+	assert.Equal(t, "A", selectMember(details).ID)
+	selectMember = SelectByLabels(map[string]string{"gpu": "false"}, SelectLeastActivationsMember)
+	assert.Nil(t, selectMember(details))
+	// the members of the activation details are not modified.
+	assert.Len(t, details.Members, 3)
+}
+
+func TestSelectLeastActivationsMember(t *testing.T) {
+	members := placementMembers()
+	assert.Equal(t, "A", SelectLeastActivationsMember(ActivationDetails{Members: members}).ID)
+	assert.Equal(t, "C", SelectLeastActivationsMember(ActivationDetails{
+		Members:     members,
+		Activations: map[string]int{"A": 2, "B": 1},
+	}).ID)
+	assert.Nil(t, SelectLeastActivationsMember(ActivationDetails{}))
+}
+
+func TestSelectCapacityWeightedMember(t *testing.T) {
+	var (
+		members     = placementMembers()
+		activations = make(map[string]int)
+	)
+	for range 14 {
+		member := SelectCapacityWeightedMember(ActivationDetails{
+			Members:     members,
+			Activations: activations,
+		})
+		activations[member.ID]++
+	}
+	assert.Equal(t, map[string]int{"A": 2, "B": 4, "C": 8}, activations)
+
+	// members without capacity are the last resort.
+	members = []*Member{{ID: "A"}, {ID: "B", Capacity: 1}}
+	assert.Equal(t, "B", SelectCapacityWeightedMember(ActivationDetails{
+		Members:     members,
+		Activations: map[string]int{"B": 100},
+	}).ID)
+	assert.Equal(t, "A", SelectCapacityWeightedMember(ActivationDetails{Members: members[:1]}).ID)
+}
+
+func TestMemberLabelsArePropagated(t *testing.T) {
+	c1, err := New(NewConfig().
+		WithID("A").
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithLabels(map[string]string{"zone": "a"}).
+		WithCapacity(1))
+	require.NoError(t, err)
+	c1.RegisterKind("player", NewPlayer, NewKindConfig())
+	c1.Start()
+	defer c1.Stop()
+
+	c2, err := New(NewConfig().
+		WithID("B").
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithLabels(map[string]string{"zone": "b", "gpu": "true"}).
+		WithCapacity(3).
+		WithProvider(NewSelfManagedProvider(NewSelfManagedConfig().
+			WithBootstrapMember(MemberAddr{ListenAddr: c1.Address(), ID: "A"}))))
+	require.NoError(t, err)
+	c2.RegisterKind("player", NewPlayer, NewKindConfig())
+	c2.Start()
+	defer c2.Stop()
+
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 2 && len(c2.Members()) == 2
+	}, time.Second*2, time.Millisecond*10)
+	for _, member := range c1.Members() {
+		if member.ID == "B" {
+			assert.Equal(t, map[string]string{"zone": "b", "gpu": "true"}, member.Labels)
+			assert.Equal(t, uint32(3), member.Capacity)
+		}
+	}
+
+	// only B has a gpu.
+	gpu := SelectByLabels(map[string]string{"gpu": "true"}, SelectRendezvousMember)
+	for i := range 5 {
+		pid := c1.Activate("player", NewActivationConfig().
+			WithID(fmt.Sprintf("gpu-%d", i)).
+			WithSelectMemberFunc(gpu))
+		require.NotNil(t, pid)
+		assert.Equal(t, c2.Address(), pid.Address)
+	}
+	assert.Nil(t, c1.Activate("player", NewActivationConfig().
+		WithSelectMemberFunc(SelectByLabels(map[string]string{"gpu": "false"}, SelectRendezvousMember))))
+
+	// B already hosts 5 activations, with three times the capacity of A it
+	// gets 4 of the next 7.
+	hosts := make(map[string]int)
+	for i := range 7 {
+		pid := c1.Activate("player", NewActivationConfig().
+			WithID(fmt.Sprintf("weighted-%d", i)).
+			WithSelectMemberFunc(SelectCapacityWeightedMember))
+		require.NotNil(t, pid)
+		hosts[pid.Address]++
+	}
+	assert.Equal(t, map[string]int{c1.Address(): 3, c2.Address(): 4}, hosts)
+}
@@ -0,0 +1,310 @@
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
+
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// KubernetesEndpoints mirrors the parts of the Kubernetes Endpoints resource
+// the KubernetesProvider uses.
+type KubernetesEndpoints struct {
+	Subsets []KubernetesEndpointSubset `json:"subsets"`
+}
+
+// KubernetesEndpointSubset is a set of addresses that expose the same ports.
+type KubernetesEndpointSubset struct {
+	Addresses []KubernetesEndpointAddress `json:"addresses"`
+	Ports     []KubernetesEndpointPort    `json:"ports"`
+}
+
+// KubernetesEndpointAddress is the address of a single ready pod.
+type KubernetesEndpointAddress struct {
+	IP string `json:"ip"`
+}
+
+// KubernetesEndpointPort is a port exposed by the pods of a subset.
+type KubernetesEndpointPort struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+}
+
+// KubernetesClient fetches the endpoints of a service from the Kubernetes
+// API. It can be faked to test the provider without a cluster.
+type KubernetesClient interface {
+	Endpoints(ctx context.Context, namespace, service string) (*KubernetesEndpoints, error)
+}
+
+type inClusterClient struct {
+	host   string
+	token  string
+	client *http.Client
+}
+
+// NewInClusterKubernetesClient returns a KubernetesClient that talks to the
+// API server of the cluster the process runs in, authenticated with the
+// service account of the pod. The service account needs permission to get
+// the endpoints of the service.
+func NewInClusterKubernetesClient() (KubernetesClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if len(host) == 0 || len(port) == 0 {
+		return nil, fmt.Errorf("not running in a kubernetes cluster")
+	}
+	token, err := os.ReadFile(serviceAccountDir + "/token")
+	if err != nil {
+		return nil, err
+	}
+	ca, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("invalid service account CA certificate")
+	}
+	return &inClusterClient{
+		host:  "https://" + net.JoinHostPort(host, port),
+		token: strings.TrimSpace(string(token)),
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		},
+	}, nil
+}
+
+func (k *inClusterClient) Endpoints(ctx context.Context, namespace, service string) (*KubernetesEndpoints, error) {
+	u := fmt.Sprintf("%s/api/v1/namespaces/%s/endpoints/%s", k.host, url.PathEscape(namespace), url.PathEscape(service))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+k.token)
+	req.Header.Set("Accept", "application/json")
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get endpoints %s/%s: %s", namespace, service, resp.Status)
+	}
+	endpoints := &KubernetesEndpoints{}
+	if err := json.NewDecoder(resp.Body).Decode(endpoints); err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// KubernetesProviderConfig holds the configuration of the KubernetesProvider.
+type KubernetesProviderConfig struct {
+	service   string
+	namespace string
+	portName  string
+	interval  time.Duration
+	client    KubernetesClient
+}
+
+// NewKubernetesProviderConfig returns a new default KubernetesProviderConfig
+// that discovers the members behind the given service.
+func NewKubernetesProviderConfig(service string) KubernetesProviderConfig {
+	namespace := "default"
+	if b, err := os.ReadFile(serviceAccountDir + "/namespace"); err == nil {
+		namespace = strings.TrimSpace(string(b))
+	}
+	return KubernetesProviderConfig{
+		service:   service,
+		namespace: namespace,
+		interval:  5 * time.Second,
+	}
+}
+
+// WithNamespace set's the namespace of the service.
+//
+// Defaults to the namespace of the pod, or "default" when running outside
+// of a cluster.
+func (config KubernetesProviderConfig) WithNamespace(namespace string) KubernetesProviderConfig {
+	config.namespace = namespace
+	return config
+}
+
+// WithPortName set's the name of the endpoint port the members listen on.
+//
+// Defaults to the first port of the endpoints.
+func (config KubernetesProviderConfig) WithPortName(name string) KubernetesProviderConfig {
+	config.portName = name
+	return config
+}
+
+// WithInterval set's the interval in which the endpoints are fetched.
+//
+// Defaults to 5 seconds.
+func (config KubernetesProviderConfig) WithInterval(d time.Duration) KubernetesProviderConfig {
+	config.interval = d
+	return config
+}
+
+// WithClient set's the client used to fetch the endpoints.
+//
+// Defaults to the in-cluster client.
+func (config KubernetesProviderConfig) WithClient(client KubernetesClient) KubernetesProviderConfig {
+	config.client = client
+	return config
+}
+
+type kubernetesRefresh struct{}
+
+// KubernetesProvider is a provider that uses the ready addresses of the
+// Endpoints of a Kubernetes service as members. The members exchange a
+// handshake to learn the ID and kinds of each other, a member leaves the
+// cluster as soon as it's no longer a ready endpoint of the service.
+type KubernetesProvider struct {
+	config       KubernetesProviderConfig
+	cluster      *Cluster
+	refresher    actor.SendRepeater
+	handshakePID *actor.PID
+	// the addresses of the ready endpoints of the service.
+	hosts map[string]bool
+	// the members that handshaked with us by their address.
+	known map[string]*Member
+	// the hash of the members last sent to the agent.
+	hash uint64
+}
+
+// NewKubernetesProvider returns a provider that discovers the members with
+// the Endpoints of the service of the given config.
+func NewKubernetesProvider(config KubernetesProviderConfig) Producer {
+	return func(c *Cluster) actor.Producer {
+		return func() actor.Receiver {
+			return &KubernetesProvider{
+				config:  config,
+				cluster: c,
+				hosts:   make(map[string]bool),
+				known:   make(map[string]*Member),
+			}
+		}
+	}
+}
+
+func (p *KubernetesProvider) Receive(c *actor.Context) {
+	switch msg := c.Message().(type) {
+	case actor.Started:
+		p.handshakePID = spawnHandshakeEndpoint(p.cluster.engine, c.PID())
+		p.sendMembersToAgent(c)
+		if p.config.client == nil {
+			client, err := NewInClusterKubernetesClient()
+			if err != nil {
+				p.failed(err)
+				return
+			}
+			p.config.client = client
+		}
+		p.refresh(c)
+		p.refresher = c.SendRepeat(c.PID(), kubernetesRefresh{}, p.config.interval)
+	case actor.Stopped:
+		if p.config.client != nil {
+			p.refresher.Stop()
+		}
+		p.cluster.engine.Poison(p.handshakePID)
+	case kubernetesRefresh:
+		p.refresh(c)
+	case *Handshake:
+		p.addMember(c, msg.Member)
+		c.Send(c.Sender(), &Members{Members: []*Member{p.cluster.Member()}})
+	case *Members:
+		for _, member := range msg.Members {
+			p.addMember(c, member)
+		}
+	}
+}
+
+// refresh fetches the endpoints of the service, handshakes with the members
+// we don't know yet and drops the ones that are no longer ready.
+func (p *KubernetesProvider) refresh(c *actor.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.cluster.config.requestTimeout)
+	defer cancel()
+	endpoints, err := p.config.client.Endpoints(ctx, p.config.namespace, p.config.service)
+	if err != nil {
+		p.failed(err)
+		return
+	}
+	p.hosts = p.endpointHosts(endpoints)
+
+	handshake := &Handshake{Member: p.cluster.Member()}
+	for host := range p.hosts {
+		if _, ok := p.known[host]; ok || host == p.cluster.Address() {
+			continue
+		}
+		c.Send(memberAddrToProviderPID(MemberAddr{ListenAddr: host}), handshake)
+	}
+	for host := range p.known {
+		if !p.hosts[host] {
+			delete(p.known, host)
+		}
+	}
+	p.sendMembersToAgent(c)
+}
+
+// endpointHosts returns the listen addresses of the ready endpoints.
+func (p *KubernetesProvider) endpointHosts(endpoints *KubernetesEndpoints) map[string]bool {
+	hosts := make(map[string]bool)
+	for _, subset := range endpoints.Subsets {
+		port := -1
+		for _, ep := range subset.Ports {
+			if len(p.config.portName) == 0 || ep.Name == p.config.portName {
+				port = ep.Port
+				break
+			}
+		}
+		if port < 0 {
+			continue
+		}
+		for _, addr := range subset.Addresses {
+			hosts[net.JoinHostPort(addr.IP, strconv.Itoa(port))] = true
+		}
+	}
+	return hosts
+}
+
+func (p *KubernetesProvider) addMember(c *actor.Context, member *Member) {
+	if member == nil || member.Host == p.cluster.Address() || !p.hosts[member.Host] {
+		return
+	}
+	p.known[member.Host] = member
+	p.sendMembersToAgent(c)
+}
+
+// sendMembersToAgent sends ourself and the known members to the agent when
+// they changed.
+func (p *KubernetesProvider) sendMembersToAgent(c *actor.Context) {
+	members := NewMemberSet(p.cluster.Member())
+	for _, member := range p.known {
+		members.Add(member)
+	}
+	if hash := members.Digest(); hash != p.hash {
+		p.hash = hash
+		c.Send(p.cluster.PID(), &Members{Members: members.Slice()})
+	}
+}
+
+func (p *KubernetesProvider) failed(err error) {
+	slog.Warn("[CLUSTER] kubernetes provider", "err", err)
+	p.cluster.engine.BroadcastEvent(ProviderErrorEvent{
+		Provider: "KubernetesProvider",
+		Err:      err,
+	})
+}
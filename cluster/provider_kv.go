@@ -0,0 +1,216 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
+
+// KV is a key/value store the KVProvider registers the members in. It follows
+// the model of etcd: keys are put with a time to live, so the keys of members
+// that crashed expire by themselves.
+type KV interface {
+	// Put stores the value under the given key. The key is removed when it's
+	// not put again within the given ttl.
+	Put(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the given key.
+	Delete(ctx context.Context, key string) error
+	// List returns the values of all the keys with the given prefix.
+	List(ctx context.Context, prefix string) (map[string][]byte, error)
+}
+
+// MemoryKV is an in-memory KV, it can be shared by the members of a cluster
+// running in the same process, which makes it useful for testing.
+type MemoryKV struct {
+	mu      sync.Mutex
+	entries map[string]memoryKVEntry
+}
+
+type memoryKVEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryKV returns a new empty MemoryKV.
+func NewMemoryKV() *MemoryKV {
+	return &MemoryKV{
+		entries: make(map[string]memoryKVEntry),
+	}
+}
+
+func (kv *MemoryKV) Put(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	kv.entries[key] = memoryKVEntry{
+		value:   value,
+		expires: time.Now().Add(ttl),
+	}
+	return nil
+}
+
+func (kv *MemoryKV) Delete(_ context.Context, key string) error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	delete(kv.entries, key)
+	return nil
+}
+
+func (kv *MemoryKV) List(_ context.Context, prefix string) (map[string][]byte, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	var (
+		now    = time.Now()
+		values = make(map[string][]byte)
+	)
+	for key, entry := range kv.entries {
+		if now.After(entry.expires) {
+			delete(kv.entries, key)
+			continue
+		}
+		if strings.HasPrefix(key, prefix) {
+			values[key] = entry.value
+		}
+	}
+	return values, nil
+}
+
+// KVProviderConfig holds the configuration of the KVProvider.
+type KVProviderConfig struct {
+	kv       KV
+	prefix   string
+	ttl      time.Duration
+	interval time.Duration
+}
+
+// NewKVProviderConfig returns a new default KVProviderConfig that registers
+// the members in the given KV.
+func NewKVProviderConfig(kv KV) KVProviderConfig {
+	return KVProviderConfig{
+		kv:       kv,
+		prefix:   "goactors/members/",
+		ttl:      10 * time.Second,
+		interval: 2 * time.Second,
+	}
+}
+
+// WithPrefix set's the prefix of the keys the members are registered under.
+//
+// Defaults to "goactors/members/".
+func (config KVProviderConfig) WithPrefix(prefix string) KVProviderConfig {
+	config.prefix = prefix
+	return config
+}
+
+// WithTTL set's the time to live of the key of a member. A member that
+// crashed leaves the cluster once its key expired.
+//
+// Defaults to 10 seconds.
+func (config KVProviderConfig) WithTTL(ttl time.Duration) KVProviderConfig {
+	config.ttl = ttl
+	return config
+}
+
+// WithInterval set's the interval in which the provider refreshes its own
+// key and lists the members.
+//
+// Defaults to 2 seconds.
+func (config KVProviderConfig) WithInterval(d time.Duration) KVProviderConfig {
+	config.interval = d
+	return config
+}
+
+type kvRefresh struct{}
+
+// KVProvider is a provider that registers the members in a KV store, like
+// etcd. Each member puts itself under the key prefix and lists the keys of
+// the other members in the configured interval.
+type KVProvider struct {
+	config    KVProviderConfig
+	cluster   *Cluster
+	refresher actor.SendRepeater
+	// the hash of the members last sent to the agent.
+	hash uint64
+}
+
+// NewKVProvider returns a provider that registers the members in the KV of
+// the given config.
+func NewKVProvider(config KVProviderConfig) Producer {
+	return func(c *Cluster) actor.Producer {
+		return func() actor.Receiver {
+			return &KVProvider{
+				config:  config,
+				cluster: c,
+			}
+		}
+	}
+}
+
+func (p *KVProvider) Receive(c *actor.Context) {
+	switch c.Message().(type) {
+	case actor.Started:
+		p.refresh(c)
+		p.refresher = c.SendRepeat(c.PID(), kvRefresh{}, p.config.interval)
+	case actor.Stopped:
+		p.refresher.Stop()
+		ctx, cancel := context.WithTimeout(context.Background(), p.cluster.config.requestTimeout)
+		defer cancel()
+		if err := p.config.kv.Delete(ctx, p.key()); err != nil {
+			p.failed(err)
+		}
+	case kvRefresh:
+		p.refresh(c)
+	}
+}
+
+// refresh puts our own key and sends the listed members to the agent when
+// they changed.
+func (p *KVProvider) refresh(c *actor.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.cluster.config.requestTimeout)
+	defer cancel()
+
+	self := p.cluster.Member()
+	b, err := self.MarshalVT()
+	if err != nil {
+		p.failed(err)
+		return
+	}
+	if err := p.config.kv.Put(ctx, p.key(), b, p.config.ttl); err != nil {
+		p.failed(err)
+		return
+	}
+	values, err := p.config.kv.List(ctx, p.config.prefix)
+	if err != nil {
+		p.failed(err)
+		return
+	}
+	members := NewMemberSet(self)
+	for key, value := range values {
+		member := &Member{}
+		if err := member.UnmarshalVT(value); err != nil {
+			p.failed(fmt.Errorf("invalid member at key %s: %w", key, err))
+			continue
+		}
+		members.Add(member)
+	}
+	if hash := members.Digest(); hash != p.hash {
+		p.hash = hash
+		c.Send(p.cluster.PID(), &Members{Members: members.Slice()})
+	}
+}
+
+func (p *KVProvider) key() string {
+	return p.config.prefix + p.cluster.ID()
+}
+
+func (p *KVProvider) failed(err error) {
+	slog.Warn("[CLUSTER] kv provider", "err", err)
+	p.cluster.engine.BroadcastEvent(ProviderErrorEvent{
+		Provider: "KVProvider",
+		Err:      err,
+	})
+}
@@ -0,0 +1,58 @@
+package cluster
+
+import (
+	"github.com/khulnasoft/goactors/actor"
+)
+
+// StaticProviderConfig holds the configuration of the StaticProvider.
+type StaticProviderConfig struct {
+	members []*Member
+}
+
+// NewStaticProviderConfig returns a new StaticProviderConfig without any
+// members.
+func NewStaticProviderConfig() StaticProviderConfig {
+	return StaticProviderConfig{
+		members: []*Member{},
+	}
+}
+
+// WithMembers set's the members of the cluster. The member of the cluster
+// running the provider is always part of the cluster, whether it's listed or
+// not.
+func (config StaticProviderConfig) WithMembers(members ...*Member) StaticProviderConfig {
+	config.members = append(config.members, members...)
+	return config
+}
+
+// StaticProvider is a provider with a fixed set of members, which makes it a
+// good fit for small deployments where the topology is known upfront. The
+// members are never removed, the cluster only learns about members that are
+// down when sending to them fails.
+type StaticProvider struct {
+	config  StaticProviderConfig
+	cluster *Cluster
+}
+
+// NewStaticProvider returns a provider that announces the members of the
+// given config.
+func NewStaticProvider(config StaticProviderConfig) Producer {
+	return func(c *Cluster) actor.Producer {
+		return func() actor.Receiver {
+			return &StaticProvider{
+				config:  config,
+				cluster: c,
+			}
+		}
+	}
+}
+
+func (p *StaticProvider) Receive(c *actor.Context) {
+	switch c.Message().(type) {
+	case actor.Started:
+		self := p.cluster.Member()
+		members := NewMemberSet(p.config.members...)
+		members.Add(self)
+		c.Send(p.cluster.PID(), &Members{Members: members.Slice()})
+	}
+}
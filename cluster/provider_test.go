@@ -0,0 +1,281 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// providerSuite describes a provider for the shared provider tests.
+type providerSuite struct {
+	// provider returns the provider of the member at index i of the given
+	// members.
+	provider func(i int, members []*Member) Producer
+	// leave makes the member at index i leave the cluster after it was
+	// stopped, nil when the provider does not remove members.
+	leave func(i int, members []*Member)
+}
+
+// testProvider starts a cluster of three members with the provider of the
+// given suite and checks the members the agents are told about.
+func testProvider(t *testing.T, suite providerSuite) {
+	members := make([]*Member, 3)
+	for i := range members {
+		members[i] = &Member{
+			ID:     "member_" + strconv.Itoa(i),
+			Host:   getRandomLocalhostAddr(),
+			Kinds:  []string{"player"},
+			Region: "eu-west",
+		}
+	}
+	clusters := make([]*Cluster, len(members))
+	for i, member := range members {
+		c, err := New(NewConfig().
+			WithID(member.ID).
+			WithListenAddr(member.Host).
+			WithRegion(member.Region).
+			WithProvider(suite.provider(i, members)))
+		require.NoError(t, err)
+		c.RegisterKind("player", NewPlayer, NewKindConfig())
+		clusters[i] = c
+	}
+	for _, c := range clusters {
+		c.Start()
+	}
+	defer func() {
+		for _, c := range clusters {
+			c.Stop()
+		}
+	}()
+
+	for _, c := range clusters {
+		assert.Eventually(t, func() bool {
+			return sameMembers(c.Members(), members)
+		}, 5*time.Second, 20*time.Millisecond, "members of %s", c.ID())
+		for _, member := range c.Members() {
+			assert.True(t, member.HasKind("player"))
+			assert.Equal(t, "eu-west", member.Region)
+		}
+		assert.True(t, c.HasKind("player"))
+	}
+
+	if suite.leave == nil {
+		return
+	}
+	last := len(clusters) - 1
+	clusters[last].Stop()
+	clusters = clusters[:last]
+	suite.leave(last, members)
+	for _, c := range clusters {
+		assert.Eventually(t, func() bool {
+			return sameMembers(c.Members(), members[:last])
+		}, 5*time.Second, 20*time.Millisecond, "members of %s after leave", c.ID())
+	}
+}
+
+// sameMembers returns true if the given members have the IDs and hosts of
+// the expected members.
+func sameMembers(members, expected []*Member) bool {
+	if len(members) != len(expected) {
+		return false
+	}
+	for _, m := range expected {
+		if !slices.ContainsFunc(members, m.Equals) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStaticProvider(t *testing.T) {
+	testProvider(t, providerSuite{
+		provider: func(i int, members []*Member) Producer {
+			return NewStaticProvider(NewStaticProviderConfig().WithMembers(members...))
+		},
+	})
+}
+
+func TestKVProvider(t *testing.T) {
+	kv := NewMemoryKV()
+	testProvider(t, providerSuite{
+		provider: func(i int, members []*Member) Producer {
+			return NewKVProvider(NewKVProviderConfig(kv).WithInterval(50 * time.Millisecond))
+		},
+		// stopping the member deletes its key.
+		leave: func(i int, members []*Member) {},
+	})
+}
+
+func TestKVProviderExpiresCrashedMembers(t *testing.T) {
+	kv := NewMemoryKV()
+	testProvider(t, providerSuite{
+		provider: func(i int, members []*Member) Producer {
+			return NewKVProvider(NewKVProviderConfig(kv).
+				WithInterval(50 * time.Millisecond).
+				WithTTL(200 * time.Millisecond))
+		},
+		// simulate a crash by putting the key back, it has to expire.
+		leave: func(i int, members []*Member) {
+			b, err := members[i].MarshalVT()
+			require.NoError(t, err)
+			require.NoError(t, kv.Put(context.Background(), "goactors/members/"+members[i].ID, b, 200*time.Millisecond))
+		},
+	})
+}
+
+func TestKVProviderUpdatesMembers(t *testing.T) {
+	kv := NewMemoryKV()
+	c, err := New(NewConfig().
+		WithID("A").
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithProvider(NewKVProvider(NewKVProviderConfig(kv).WithInterval(20 * time.Millisecond))))
+	require.NoError(t, err)
+	c.Start()
+	defer c.Stop()
+
+	regionOf := func(id string) string {
+		for _, member := range c.Members() {
+			if member.ID == id {
+				return member.Region
+			}
+		}
+		return ""
+	}
+	put := func(member *Member) {
+		b, err := member.MarshalVT()
+		require.NoError(t, err)
+		require.NoError(t, kv.Put(context.Background(), "goactors/members/"+member.ID, b, time.Minute))
+	}
+	// the ID and host of the member stay the same, only its region changes.
+	host := getRandomLocalhostAddr()
+	put(&Member{ID: "B", Host: host, Region: "eu"})
+	require.Eventually(t, func() bool { return regionOf("B") == "eu" }, time.Second, 10*time.Millisecond)
+	put(&Member{ID: "B", Host: host, Region: "us"})
+	require.Eventually(t, func() bool { return regionOf("B") == "us" }, time.Second, 10*time.Millisecond)
+}
+
+func TestMemoryKV(t *testing.T) {
+	var (
+		kv  = NewMemoryKV()
+		ctx = context.Background()
+	)
+	require.NoError(t, kv.Put(ctx, "a/1", []byte("1"), time.Minute))
+	require.NoError(t, kv.Put(ctx, "a/2", []byte("2"), time.Millisecond))
+	require.NoError(t, kv.Put(ctx, "b/1", []byte("3"), time.Minute))
+	time.Sleep(5 * time.Millisecond)
+
+	values, err := kv.List(ctx, "a/")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a/1": []byte("1")}, values)
+
+	require.NoError(t, kv.Delete(ctx, "a/1"))
+	values, err = kv.List(ctx, "a/")
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+type fakeKubernetesClient struct {
+	mu        sync.Mutex
+	endpoints *KubernetesEndpoints
+	err       error
+}
+
+func (f *fakeKubernetesClient) Endpoints(_ context.Context, namespace, service string) (*KubernetesEndpoints, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.endpoints, nil
+}
+
+// setMembers sets the members as the ready endpoints. Every member gets a
+// subset of its own since they all listen on different ports.
+func (f *fakeKubernetesClient) setMembers(members []*Member) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.endpoints = &KubernetesEndpoints{}
+	for _, member := range members {
+		host, portStr, _ := net.SplitHostPort(member.Host)
+		port, _ := strconv.Atoi(portStr)
+		f.endpoints.Subsets = append(f.endpoints.Subsets, KubernetesEndpointSubset{
+			Addresses: []KubernetesEndpointAddress{{IP: host}},
+			Ports: []KubernetesEndpointPort{
+				{Name: "metrics", Port: 9090},
+				{Name: "cluster", Port: port},
+			},
+		})
+	}
+}
+
+func TestKubernetesProvider(t *testing.T) {
+	client := &fakeKubernetesClient{}
+	testProvider(t, providerSuite{
+		provider: func(i int, members []*Member) Producer {
+			client.setMembers(members)
+			return NewKubernetesProvider(NewKubernetesProviderConfig("goactors").
+				WithPortName("cluster").
+				WithInterval(50 * time.Millisecond).
+				WithClient(client))
+		},
+		leave: func(i int, members []*Member) {
+			client.setMembers(slices.Delete(slices.Clone(members), i, i+1))
+		},
+	})
+}
+
+func TestKubernetesProviderError(t *testing.T) {
+	var (
+		client = &fakeKubernetesClient{err: errors.New("forbidden")}
+		errch  = make(chan ProviderErrorEvent, 1)
+	)
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	sub := e.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(ProviderErrorEvent); ok {
+			select {
+			case errch <- ev:
+			default:
+			}
+		}
+	}, "sub")
+	e.Subscribe(sub)
+
+	c, err := New(NewConfig().
+		WithEngine(e).
+		WithProvider(NewKubernetesProvider(NewKubernetesProviderConfig("goactors").WithClient(client))))
+	require.NoError(t, err)
+	c.Start()
+	defer c.Stop()
+
+	select {
+	case ev := <-errch:
+		assert.Equal(t, "KubernetesProvider", ev.Provider)
+		assert.EqualError(t, ev.Err, "forbidden")
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a ProviderErrorEvent")
+	}
+	// the member itself is still part of the cluster.
+	assert.Len(t, c.Members(), 1)
+}
+
+func TestSelfManagedProviderSuite(t *testing.T) {
+	testProvider(t, providerSuite{
+		provider: func(i int, members []*Member) Producer {
+			config := NewSelfManagedConfig().WithDiscovery()
+			if i > 0 {
+				config = config.WithBootstrapMember(MemberAddr{ListenAddr: members[0].Host, ID: members[0].ID})
+			}
+			return NewSelfManagedProvider(config)
+		},
+	})
+}
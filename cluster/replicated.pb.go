@@ -0,0 +1,1209 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.32.0
+// 	protoc        v3.12.4
+// source: replicated.proto
+
+package cluster
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// GCounter is a counter that only grows.
+type GCounter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the count of each member by its ID.
+	Counts map[string]uint64 `protobuf:"bytes,1,rep,name=counts,proto3" json:"counts,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *GCounter) Reset() {
+	*x = GCounter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GCounter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GCounter) ProtoMessage() {}
+
+func (x *GCounter) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GCounter.ProtoReflect.Descriptor instead.
+func (*GCounter) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GCounter) GetCounts() map[string]uint64 {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+// PNCounter is a counter that can be incremented and decremented.
+type PNCounter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Increments *GCounter `protobuf:"bytes,1,opt,name=increments,proto3" json:"increments,omitempty"`
+	Decrements *GCounter `protobuf:"bytes,2,opt,name=decrements,proto3" json:"decrements,omitempty"`
+}
+
+func (x *PNCounter) Reset() {
+	*x = PNCounter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PNCounter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PNCounter) ProtoMessage() {}
+
+func (x *PNCounter) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PNCounter.ProtoReflect.Descriptor instead.
+func (*PNCounter) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PNCounter) GetIncrements() *GCounter {
+	if x != nil {
+		return x.Increments
+	}
+	return nil
+}
+
+func (x *PNCounter) GetDecrements() *GCounter {
+	if x != nil {
+		return x.Decrements
+	}
+	return nil
+}
+
+type ORSetDots struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the latest add of the element by each member, by its ID.
+	Dots map[string]uint64 `protobuf:"bytes,1,rep,name=dots,proto3" json:"dots,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *ORSetDots) Reset() {
+	*x = ORSetDots{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ORSetDots) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ORSetDots) ProtoMessage() {}
+
+func (x *ORSetDots) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ORSetDots.ProtoReflect.Descriptor instead.
+func (*ORSetDots) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ORSetDots) GetDots() map[string]uint64 {
+	if x != nil {
+		return x.Dots
+	}
+	return nil
+}
+
+// ORSet is an observed-remove set of strings, an add wins over a concurrent
+// remove of the same element.
+type ORSet struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Dots map[string]*ORSetDots `protobuf:"bytes,1,rep,name=dots,proto3" json:"dots,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// the number of adds seen of each member, by its ID.
+	Context map[string]uint64 `protobuf:"bytes,2,rep,name=context,proto3" json:"context,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *ORSet) Reset() {
+	*x = ORSet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ORSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ORSet) ProtoMessage() {}
+
+func (x *ORSet) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ORSet.ProtoReflect.Descriptor instead.
+func (*ORSet) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ORSet) GetDots() map[string]*ORSetDots {
+	if x != nil {
+		return x.Dots
+	}
+	return nil
+}
+
+func (x *ORSet) GetContext() map[string]uint64 {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+type LWWRegister struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value []byte `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	// unix nanoseconds of the moment the value was written.
+	Timestamp int64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// the ID of the member that wrote the value.
+	MemberID string `protobuf:"bytes,3,opt,name=memberID,proto3" json:"memberID,omitempty"`
+	Deleted  bool   `protobuf:"varint,4,opt,name=deleted,proto3" json:"deleted,omitempty"`
+}
+
+func (x *LWWRegister) Reset() {
+	*x = LWWRegister{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LWWRegister) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LWWRegister) ProtoMessage() {}
+
+func (x *LWWRegister) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LWWRegister.ProtoReflect.Descriptor instead.
+func (*LWWRegister) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LWWRegister) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *LWWRegister) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *LWWRegister) GetMemberID() string {
+	if x != nil {
+		return x.MemberID
+	}
+	return ""
+}
+
+func (x *LWWRegister) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+// LWWMap is a map of bytes where the last write of a key wins.
+type LWWMap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Registers map[string]*LWWRegister `protobuf:"bytes,1,rep,name=registers,proto3" json:"registers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *LWWMap) Reset() {
+	*x = LWWMap{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LWWMap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LWWMap) ProtoMessage() {}
+
+func (x *LWWMap) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LWWMap.ProtoReflect.Descriptor instead.
+func (*LWWMap) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *LWWMap) GetRegisters() map[string]*LWWRegister {
+	if x != nil {
+		return x.Registers
+	}
+	return nil
+}
+
+// Flag is a boolean that can only be switched on.
+type Flag struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	On bool `protobuf:"varint,1,opt,name=on,proto3" json:"on,omitempty"`
+}
+
+func (x *Flag) Reset() {
+	*x = Flag{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Flag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Flag) ProtoMessage() {}
+
+func (x *Flag) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Flag.ProtoReflect.Descriptor instead.
+func (*Flag) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Flag) GetOn() bool {
+	if x != nil {
+		return x.On
+	}
+	return false
+}
+
+type DataEnvelope struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Data:
+	//	*DataEnvelope_GCounter
+	//	*DataEnvelope_PnCounter
+	//	*DataEnvelope_OrSet
+	//	*DataEnvelope_LwwMap
+	//	*DataEnvelope_Flag
+	Data isDataEnvelope_Data `protobuf_oneof:"data"`
+}
+
+func (x *DataEnvelope) Reset() {
+	*x = DataEnvelope{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DataEnvelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataEnvelope) ProtoMessage() {}
+
+func (x *DataEnvelope) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataEnvelope.ProtoReflect.Descriptor instead.
+func (*DataEnvelope) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{7}
+}
+
+func (m *DataEnvelope) GetData() isDataEnvelope_Data {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (x *DataEnvelope) GetGCounter() *GCounter {
+	if x, ok := x.GetData().(*DataEnvelope_GCounter); ok {
+		return x.GCounter
+	}
+	return nil
+}
+
+func (x *DataEnvelope) GetPnCounter() *PNCounter {
+	if x, ok := x.GetData().(*DataEnvelope_PnCounter); ok {
+		return x.PnCounter
+	}
+	return nil
+}
+
+func (x *DataEnvelope) GetOrSet() *ORSet {
+	if x, ok := x.GetData().(*DataEnvelope_OrSet); ok {
+		return x.OrSet
+	}
+	return nil
+}
+
+func (x *DataEnvelope) GetLwwMap() *LWWMap {
+	if x, ok := x.GetData().(*DataEnvelope_LwwMap); ok {
+		return x.LwwMap
+	}
+	return nil
+}
+
+func (x *DataEnvelope) GetFlag() *Flag {
+	if x, ok := x.GetData().(*DataEnvelope_Flag); ok {
+		return x.Flag
+	}
+	return nil
+}
+
+type isDataEnvelope_Data interface {
+	isDataEnvelope_Data()
+}
+
+type DataEnvelope_GCounter struct {
+	GCounter *GCounter `protobuf:"bytes,1,opt,name=gCounter,proto3,oneof"`
+}
+
+type DataEnvelope_PnCounter struct {
+	PnCounter *PNCounter `protobuf:"bytes,2,opt,name=pnCounter,proto3,oneof"`
+}
+
+type DataEnvelope_OrSet struct {
+	OrSet *ORSet `protobuf:"bytes,3,opt,name=orSet,proto3,oneof"`
+}
+
+type DataEnvelope_LwwMap struct {
+	LwwMap *LWWMap `protobuf:"bytes,4,opt,name=lwwMap,proto3,oneof"`
+}
+
+type DataEnvelope_Flag struct {
+	Flag *Flag `protobuf:"bytes,5,opt,name=flag,proto3,oneof"`
+}
+
+func (*DataEnvelope_GCounter) isDataEnvelope_Data() {}
+
+func (*DataEnvelope_PnCounter) isDataEnvelope_Data() {}
+
+func (*DataEnvelope_OrSet) isDataEnvelope_Data() {}
+
+func (*DataEnvelope_LwwMap) isDataEnvelope_Data() {}
+
+func (*DataEnvelope_Flag) isDataEnvelope_Data() {}
+
+// DataWrite merges the data into the replica of a member.
+type DataWrite struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key  string        `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Data *DataEnvelope `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *DataWrite) Reset() {
+	*x = DataWrite{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DataWrite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataWrite) ProtoMessage() {}
+
+func (x *DataWrite) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataWrite.ProtoReflect.Descriptor instead.
+func (*DataWrite) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DataWrite) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *DataWrite) GetData() *DataEnvelope {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type DataWriteAck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// set when the member could not merge the data into its replica, for
+	// example because its replica is of another type.
+	Error string `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *DataWriteAck) Reset() {
+	*x = DataWriteAck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DataWriteAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataWriteAck) ProtoMessage() {}
+
+func (x *DataWriteAck) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataWriteAck.ProtoReflect.Descriptor instead.
+func (*DataWriteAck) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DataWriteAck) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// DataRead asks a member for its replica of the data.
+type DataRead struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (x *DataRead) Reset() {
+	*x = DataRead{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DataRead) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataRead) ProtoMessage() {}
+
+func (x *DataRead) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataRead.ProtoReflect.Descriptor instead.
+func (*DataRead) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DataRead) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type DataReadResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// unset when the member has no replica of the data.
+	Data *DataEnvelope `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *DataReadResult) Reset() {
+	*x = DataReadResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DataReadResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataReadResult) ProtoMessage() {}
+
+func (x *DataReadResult) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataReadResult.ProtoReflect.Descriptor instead.
+func (*DataReadResult) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DataReadResult) GetData() *DataEnvelope {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// DataStatus is gossiped to a member, which responds with the replicas that
+// differ from the digests.
+type DataStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Digests map[string]uint64 `protobuf:"bytes,1,rep,name=digests,proto3" json:"digests,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	// set on the status a member sends back, which is not answered with a
+	// status again.
+	Reply bool `protobuf:"varint,2,opt,name=reply,proto3" json:"reply,omitempty"`
+}
+
+func (x *DataStatus) Reset() {
+	*x = DataStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DataStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataStatus) ProtoMessage() {}
+
+func (x *DataStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataStatus.ProtoReflect.Descriptor instead.
+func (*DataStatus) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DataStatus) GetDigests() map[string]uint64 {
+	if x != nil {
+		return x.Digests
+	}
+	return nil
+}
+
+func (x *DataStatus) GetReply() bool {
+	if x != nil {
+		return x.Reply
+	}
+	return false
+}
+
+type DataGossip struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data map[string]*DataEnvelope `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *DataGossip) Reset() {
+	*x = DataGossip{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_replicated_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DataGossip) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DataGossip) ProtoMessage() {}
+
+func (x *DataGossip) ProtoReflect() protoreflect.Message {
+	mi := &file_replicated_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DataGossip.ProtoReflect.Descriptor instead.
+func (*DataGossip) Descriptor() ([]byte, []int) {
+	return file_replicated_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DataGossip) GetData() map[string]*DataEnvelope {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+var File_replicated_proto protoreflect.FileDescriptor
+
+var file_replicated_proto_rawDesc = []byte{
+	0x0a, 0x10, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x22, 0x7c, 0x0a, 0x08, 0x47,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x35, 0x0a, 0x06, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65,
+	0x72, 0x2e, 0x47, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x1a, 0x39,
+	0x0a, 0x0b, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x71, 0x0a, 0x09, 0x50, 0x4e, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x31, 0x0a, 0x0a, 0x69, 0x6e, 0x63, 0x72, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6c, 0x75,
+	0x73, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x52, 0x0a, 0x69,
+	0x6e, 0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x31, 0x0a, 0x0a, 0x64, 0x65, 0x63,
+	0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72,
+	0x52, 0x0a, 0x64, 0x65, 0x63, 0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x76, 0x0a, 0x09,
+	0x4f, 0x52, 0x53, 0x65, 0x74, 0x44, 0x6f, 0x74, 0x73, 0x12, 0x30, 0x0a, 0x04, 0x64, 0x6f, 0x74,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65,
+	0x72, 0x2e, 0x4f, 0x52, 0x53, 0x65, 0x74, 0x44, 0x6f, 0x74, 0x73, 0x2e, 0x44, 0x6f, 0x74, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x64, 0x6f, 0x74, 0x73, 0x1a, 0x37, 0x0a, 0x09, 0x44,
+	0x6f, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x22, 0xf5, 0x01, 0x0a, 0x05, 0x4f, 0x52, 0x53, 0x65, 0x74, 0x12, 0x2c,
+	0x0a, 0x04, 0x64, 0x6f, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x63,
+	0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x4f, 0x52, 0x53, 0x65, 0x74, 0x2e, 0x44, 0x6f, 0x74,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x64, 0x6f, 0x74, 0x73, 0x12, 0x35, 0x0a, 0x07,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
+	0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x4f, 0x52, 0x53, 0x65, 0x74, 0x2e, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x78, 0x74, 0x1a, 0x4b, 0x0a, 0x09, 0x44, 0x6f, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x28, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x4f, 0x52, 0x53, 0x65,
+	0x74, 0x44, 0x6f, 0x74, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x1a, 0x3a, 0x0a, 0x0c, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x77, 0x0a, 0x0b,
+	0x4c, 0x57, 0x57, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12,
+	0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x49, 0x44, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x49, 0x44, 0x12, 0x18, 0x0a, 0x07, 0x64,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x64, 0x22, 0x9a, 0x01, 0x0a, 0x06, 0x4c, 0x57, 0x57, 0x4d, 0x61, 0x70,
+	0x12, 0x3c, 0x0a, 0x09, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x4c, 0x57,
+	0x57, 0x4d, 0x61, 0x70, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x09, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x73, 0x1a, 0x52,
+	0x0a, 0x0e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x2a, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x14, 0x2e, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x4c, 0x57, 0x57, 0x52,
+	0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0x16, 0x0a, 0x04, 0x46, 0x6c, 0x61, 0x67, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x6f, 0x6e, 0x22, 0xf3, 0x01, 0x0a, 0x0c, 0x44,
+	0x61, 0x74, 0x61, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x12, 0x2f, 0x0a, 0x08, 0x67,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72,
+	0x48, 0x00, 0x52, 0x08, 0x67, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x12, 0x32, 0x0a, 0x09,
+	0x70, 0x6e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x50, 0x4e, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x65, 0x72, 0x48, 0x00, 0x52, 0x09, 0x70, 0x6e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72,
+	0x12, 0x26, 0x0a, 0x05, 0x6f, 0x72, 0x53, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0e, 0x2e, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x4f, 0x52, 0x53, 0x65, 0x74, 0x48,
+	0x00, 0x52, 0x05, 0x6f, 0x72, 0x53, 0x65, 0x74, 0x12, 0x29, 0x0a, 0x06, 0x6c, 0x77, 0x77, 0x4d,
+	0x61, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6c, 0x75, 0x73, 0x74,
+	0x65, 0x72, 0x2e, 0x4c, 0x57, 0x57, 0x4d, 0x61, 0x70, 0x48, 0x00, 0x52, 0x06, 0x6c, 0x77, 0x77,
+	0x4d, 0x61, 0x70, 0x12, 0x23, 0x0a, 0x04, 0x66, 0x6c, 0x61, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0d, 0x2e, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x46, 0x6c, 0x61, 0x67,
+	0x48, 0x00, 0x52, 0x04, 0x66, 0x6c, 0x61, 0x67, 0x42, 0x06, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x22, 0x48, 0x0a, 0x09, 0x44, 0x61, 0x74, 0x61, 0x57, 0x72, 0x69, 0x74, 0x65, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x29, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
+	0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x76, 0x65,
+	0x6c, 0x6f, 0x70, 0x65, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x24, 0x0a, 0x0c, 0x44, 0x61,
+	0x74, 0x61, 0x57, 0x72, 0x69, 0x74, 0x65, 0x41, 0x63, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x22, 0x1c, 0x0a, 0x08, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x61, 0x64, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x3b,
+	0x0a, 0x0e, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x29, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15,
+	0x2e, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x76,
+	0x65, 0x6c, 0x6f, 0x70, 0x65, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x9a, 0x01, 0x0a, 0x0a,
+	0x44, 0x61, 0x74, 0x61, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x3a, 0x0a, 0x07, 0x64, 0x69,
+	0x67, 0x65, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x63, 0x6c,
+	0x75, 0x73, 0x74, 0x65, 0x72, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x2e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x64,
+	0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x1a, 0x3a, 0x0a, 0x0c,
+	0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x8f, 0x01, 0x0a, 0x0a, 0x44, 0x61, 0x74,
+	0x61, 0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x12, 0x31, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2e,
+	0x44, 0x61, 0x74, 0x61, 0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x4e, 0x0a, 0x09, 0x44, 0x61,
+	0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2b, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x63, 0x6c, 0x75, 0x73, 0x74,
+	0x65, 0x72, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x28, 0x5a, 0x26, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b, 0x68, 0x75, 0x6c, 0x6e, 0x61, 0x73,
+	0x6f, 0x66, 0x74, 0x2f, 0x67, 0x6f, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x2f, 0x63, 0x6c, 0x75,
+	0x73, 0x74, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_replicated_proto_rawDescOnce sync.Once
+	file_replicated_proto_rawDescData = file_replicated_proto_rawDesc
+)
+
+func file_replicated_proto_rawDescGZIP() []byte {
+	file_replicated_proto_rawDescOnce.Do(func() {
+		file_replicated_proto_rawDescData = protoimpl.X.CompressGZIP(file_replicated_proto_rawDescData)
+	})
+	return file_replicated_proto_rawDescData
+}
+
+var file_replicated_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_replicated_proto_goTypes = []interface{}{
+	(*GCounter)(nil),       // 0: cluster.GCounter
+	(*PNCounter)(nil),      // 1: cluster.PNCounter
+	(*ORSetDots)(nil),      // 2: cluster.ORSetDots
+	(*ORSet)(nil),          // 3: cluster.ORSet
+	(*LWWRegister)(nil),    // 4: cluster.LWWRegister
+	(*LWWMap)(nil),         // 5: cluster.LWWMap
+	(*Flag)(nil),           // 6: cluster.Flag
+	(*DataEnvelope)(nil),   // 7: cluster.DataEnvelope
+	(*DataWrite)(nil),      // 8: cluster.DataWrite
+	(*DataWriteAck)(nil),   // 9: cluster.DataWriteAck
+	(*DataRead)(nil),       // 10: cluster.DataRead
+	(*DataReadResult)(nil), // 11: cluster.DataReadResult
+	(*DataStatus)(nil),     // 12: cluster.DataStatus
+	(*DataGossip)(nil),     // 13: cluster.DataGossip
+	nil,                    // 14: cluster.GCounter.CountsEntry
+	nil,                    // 15: cluster.ORSetDots.DotsEntry
+	nil,                    // 16: cluster.ORSet.DotsEntry
+	nil,                    // 17: cluster.ORSet.ContextEntry
+	nil,                    // 18: cluster.LWWMap.RegistersEntry
+	nil,                    // 19: cluster.DataStatus.DigestsEntry
+	nil,                    // 20: cluster.DataGossip.DataEntry
+}
+var file_replicated_proto_depIdxs = []int32{
+	14, // 0: cluster.GCounter.counts:type_name -> cluster.GCounter.CountsEntry
+	0,  // 1: cluster.PNCounter.increments:type_name -> cluster.GCounter
+	0,  // 2: cluster.PNCounter.decrements:type_name -> cluster.GCounter
+	15, // 3: cluster.ORSetDots.dots:type_name -> cluster.ORSetDots.DotsEntry
+	16, // 4: cluster.ORSet.dots:type_name -> cluster.ORSet.DotsEntry
+	17, // 5: cluster.ORSet.context:type_name -> cluster.ORSet.ContextEntry
+	18, // 6: cluster.LWWMap.registers:type_name -> cluster.LWWMap.RegistersEntry
+	0,  // 7: cluster.DataEnvelope.gCounter:type_name -> cluster.GCounter
+	1,  // 8: cluster.DataEnvelope.pnCounter:type_name -> cluster.PNCounter
+	3,  // 9: cluster.DataEnvelope.orSet:type_name -> cluster.ORSet
+	5,  // 10: cluster.DataEnvelope.lwwMap:type_name -> cluster.LWWMap
+	6,  // 11: cluster.DataEnvelope.flag:type_name -> cluster.Flag
+	7,  // 12: cluster.DataWrite.data:type_name -> cluster.DataEnvelope
+	7,  // 13: cluster.DataReadResult.data:type_name -> cluster.DataEnvelope
+	19, // 14: cluster.DataStatus.digests:type_name -> cluster.DataStatus.DigestsEntry
+	20, // 15: cluster.DataGossip.data:type_name -> cluster.DataGossip.DataEntry
+	2,  // 16: cluster.ORSet.DotsEntry.value:type_name -> cluster.ORSetDots
+	4,  // 17: cluster.LWWMap.RegistersEntry.value:type_name -> cluster.LWWRegister
+	7,  // 18: cluster.DataGossip.DataEntry.value:type_name -> cluster.DataEnvelope
+	19, // [19:19] is the sub-list for method output_type
+	19, // [19:19] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
+}
+
+func init() { file_replicated_proto_init() }
+func file_replicated_proto_init() {
+	if File_replicated_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_replicated_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GCounter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PNCounter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ORSetDots); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ORSet); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LWWRegister); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LWWMap); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Flag); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DataEnvelope); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DataWrite); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DataWriteAck); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DataRead); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DataReadResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DataStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_replicated_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DataGossip); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_replicated_proto_msgTypes[7].OneofWrappers = []interface{}{
+		(*DataEnvelope_GCounter)(nil),
+		(*DataEnvelope_PnCounter)(nil),
+		(*DataEnvelope_OrSet)(nil),
+		(*DataEnvelope_LwwMap)(nil),
+		(*DataEnvelope_Flag)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_replicated_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   21,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_replicated_proto_goTypes,
+		DependencyIndexes: file_replicated_proto_depIdxs,
+		MessageInfos:      file_replicated_proto_msgTypes,
+	}.Build()
+	File_replicated_proto = out.File
+	file_replicated_proto_rawDesc = nil
+	file_replicated_proto_goTypes = nil
+	file_replicated_proto_depIdxs = nil
+}
@@ -0,0 +1,413 @@
+package cluster
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/zeebo/xxh3"
+	"google.golang.org/protobuf/proto"
+)
+
+var defaultDataGossipInterval = time.Second * 2
+
+// Consistency is the number of members a read or a write of replicated data
+// waits for.
+type Consistency int
+
+const (
+	// ConsistencyLocal only reads or writes the replica of our member, the
+	// other members receive the data by gossip.
+	ConsistencyLocal Consistency = iota
+	// ConsistencyMajority reads from, or writes to, a majority of the
+	// members.
+	ConsistencyMajority
+	// ConsistencyAll reads from, or writes to, all the members.
+	ConsistencyAll
+)
+
+// required returns the number of members out of the given number of members
+// that need to take part, including ours.
+func (c Consistency) required(members int) int {
+	switch c {
+	case ConsistencyMajority:
+		return members/2 + 1
+	case ConsistencyAll:
+		return members
+	}
+	return 1
+}
+
+// DataChanged is sent to the subscribers of a key when the replicated data of
+// the key changed on our member.
+type DataChanged struct {
+	Key  string
+	Data ReplicatedData
+}
+
+type (
+	updateData struct {
+		key     string
+		initial ReplicatedData
+		modify  func(ReplicatedData, Writer)
+	}
+	getData struct {
+		key string
+	}
+	dataResult struct {
+		data ReplicatedData
+		err  error
+	}
+	// dataResponse is the response of the replicator of another member, the
+	// response is nil if it didn't respond in time.
+	dataResponse struct {
+		resp any
+		err  error
+	}
+	subscribeData struct {
+		key string
+		pid *actor.PID
+	}
+	unsubscribeData struct {
+		key string
+		pid *actor.PID
+	}
+	// dataGossipTick triggers the replicator to gossip with a random member.
+	dataGossipTick struct{}
+)
+
+// UpdateData modifies the replicated data with the given key. The modify
+// function is called with the replica of our member, or a copy of initial if
+// there is none yet, and must not hold on to it. It's also given the Writer
+// of our member, which the data types take to record the update. The write
+// consistency tells how many members need to have applied the update before
+// UpdateData returns. When the consistency is not reached an error is
+// returned, the update is still applied on our member and gossiped to the
+// others.
+//
+//	err := c.UpdateData("visits", &cluster.GCounter{}, cluster.ConsistencyMajority, func(data cluster.ReplicatedData, w cluster.Writer) {
+//		data.(*cluster.GCounter).Increment(w, 1)
+//	})
+func (c *Cluster) UpdateData(key string, initial ReplicatedData, write Consistency, modify func(ReplicatedData, Writer)) error {
+	resp, err := c.engine.Request(c.replicatorPID, updateData{
+		key:     key,
+		initial: initial,
+		modify:  modify,
+	}, c.config.requestTimeout).Result()
+	if err != nil {
+		return fmt.Errorf("update %s: %w", key, err)
+	}
+	result, ok := resp.(dataResult)
+	if !ok {
+		return fmt.Errorf("update %s: unexpected response %T", key, resp)
+	}
+	if result.err != nil {
+		return fmt.Errorf("update %s: %w", key, result.err)
+	}
+	if _, err := c.askReplicators(&DataWrite{Key: key, Data: wrapData(result.data)}, write); err != nil {
+		return fmt.Errorf("update %s: %w", key, err)
+	}
+	return nil
+}
+
+// GetData returns the replicated data with the given key, merged from the
+// replicas of as many members as the read consistency requires. Nil is
+// returned if none of them has the data.
+//
+//	data, err := c.GetData("visits", cluster.ConsistencyLocal)
+//	if data != nil {
+//		fmt.Println(data.(*cluster.GCounter).Value())
+//	}
+func (c *Cluster) GetData(key string, read Consistency) (ReplicatedData, error) {
+	resp, err := c.engine.Request(c.replicatorPID, getData{key: key}, c.config.requestTimeout).Result()
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", key, err)
+	}
+	result, ok := resp.(dataResult)
+	if !ok {
+		return nil, fmt.Errorf("get %s: unexpected response %T", key, resp)
+	}
+	data := result.data
+	replicas, err := c.askReplicators(&DataRead{Key: key}, read)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", key, err)
+	}
+	var repaired bool
+	for _, resp := range replicas {
+		replica := resp.(*DataReadResult).GetData().unwrap()
+		switch {
+		case replica == nil:
+		case data == nil:
+			data, repaired = replica, true
+		case proto.MessageName(data) == proto.MessageName(replica):
+			if digestData(replica) != digestData(data) {
+				data.merge(replica)
+				repaired = true
+			}
+		}
+	}
+	// Our member learns about what the others know.
+	if repaired {
+		c.engine.Send(c.replicatorPID, &DataWrite{Key: key, Data: wrapData(proto.Clone(data).(ReplicatedData))})
+	}
+	return data, nil
+}
+
+// SubscribeData subscribes the given actor to the changes of the replicated
+// data with the given key. The actor receives a DataChanged message with the
+// current data right away if it exists, and every time it changes.
+func (c *Cluster) SubscribeData(key string, pid *actor.PID) {
+	c.engine.Send(c.replicatorPID, subscribeData{key: key, pid: pid})
+}
+
+// UnsubscribeData unsubscribes the given actor from the changes of the
+// replicated data with the given key.
+func (c *Cluster) UnsubscribeData(key string, pid *actor.PID) {
+	c.engine.Send(c.replicatorPID, unsubscribeData{key: key, pid: pid})
+}
+
+// askReplicators sends the given message to the replicators of the other
+// members, and returns the responses once as many members responded as the
+// consistency requires. A write a member acks with an error doesn't count as
+// a response.
+func (c *Cluster) askReplicators(msg any, consistency Consistency) ([]any, error) {
+	if consistency == ConsistencyLocal {
+		return nil, nil
+	}
+	var (
+		members = c.otherMembers()
+		need    = consistency.required(len(members)+1) - 1
+		results = make(chan dataResponse, len(members))
+	)
+	for _, member := range members {
+		go func() {
+			resp, err := c.engine.Request(replicatorPID(member), msg, c.config.requestTimeout).Result()
+			if err != nil {
+				results <- dataResponse{}
+				return
+			}
+			if ack, ok := resp.(*DataWriteAck); ok && len(ack.Error) > 0 {
+				results <- dataResponse{err: fmt.Errorf("member %s: %s", member.ID, ack.Error)}
+				return
+			}
+			results <- dataResponse{resp: resp}
+		}()
+	}
+	var (
+		responses = make([]any, 0, need)
+		rejected  error
+	)
+	for range members {
+		if len(responses) >= need {
+			break
+		}
+		result := <-results
+		switch {
+		case result.err != nil:
+			rejected = result.err
+		case result.resp != nil:
+			responses = append(responses, result.resp)
+		}
+	}
+	if len(responses) < need {
+		err := fmt.Errorf("%d of %d members responded", len(responses)+1, need+1)
+		if rejected != nil {
+			err = fmt.Errorf("%w: %w", err, rejected)
+		}
+		return nil, err
+	}
+	return responses, nil
+}
+
+// otherMembers returns the members of the cluster except ours.
+func (c *Cluster) otherMembers() []*Member {
+	return slices.DeleteFunc(c.Members(), func(m *Member) bool {
+		return m.ID == c.config.id
+	})
+}
+
+func replicatorPID(member *Member) *actor.PID {
+	return actor.NewPID(member.Host, "replicator/"+member.ID)
+}
+
+// digestData returns a hash of the given data, replicas with the same hash
+// hold the same data.
+func digestData(data ReplicatedData) uint64 {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(data)
+	if err != nil {
+		return 0
+	}
+	return xxh3.Hash(b)
+}
+
+// replicator holds the replicas of the replicated data on a member. It
+// gossips with a random member at an interval, to exchange the data that
+// differs between them.
+type replicator struct {
+	cluster     *Cluster
+	data        map[string]ReplicatedData
+	subscribers map[string][]*actor.PID
+	repeater    actor.SendRepeater
+}
+
+func newReplicator(c *Cluster) actor.Producer {
+	return func() actor.Receiver {
+		return &replicator{
+			cluster:     c,
+			data:        make(map[string]ReplicatedData),
+			subscribers: make(map[string][]*actor.PID),
+		}
+	}
+}
+
+func (r *replicator) Receive(c *actor.Context) {
+	switch msg := c.Message().(type) {
+	case actor.Started:
+		r.repeater = c.SendRepeat(c.PID(), dataGossipTick{}, r.cluster.config.dataGossipInterval)
+	case actor.Stopped:
+		r.repeater.Stop()
+	case updateData:
+		c.Respond(r.handleUpdate(c, msg))
+	case getData:
+		c.Respond(dataResult{data: r.get(msg.key)})
+	case *DataWrite:
+		err := r.merge(c, msg.Key, msg.Data.unwrap())
+		// Reads repair our replica without waiting for the ack.
+		if c.Sender() == nil {
+			r.logMismatch(msg.Key, err)
+			return
+		}
+		ack := &DataWriteAck{}
+		if err != nil {
+			ack.Error = err.Error()
+		}
+		c.Respond(ack)
+	case *DataRead:
+		resp := &DataReadResult{}
+		if data := r.get(msg.Key); data != nil {
+			resp.Data = wrapData(data)
+		}
+		c.Respond(resp)
+	case dataGossipTick:
+		r.gossip(c)
+	case *DataStatus:
+		r.handleStatus(c, msg)
+	case *DataGossip:
+		for key, data := range msg.Data {
+			r.logMismatch(key, r.merge(c, key, data.unwrap()))
+		}
+	case subscribeData:
+		r.subscribers[msg.key] = append(r.subscribers[msg.key], msg.pid)
+		if data := r.get(msg.key); data != nil {
+			c.Send(msg.pid, DataChanged{Key: msg.key, Data: data})
+		}
+	case unsubscribeData:
+		r.subscribers[msg.key] = slices.DeleteFunc(r.subscribers[msg.key], msg.pid.Equals)
+		if len(r.subscribers[msg.key]) == 0 {
+			delete(r.subscribers, msg.key)
+		}
+	}
+}
+
+// get returns a copy of our replica of the data.
+func (r *replicator) get(key string) ReplicatedData {
+	data, ok := r.data[key]
+	if !ok {
+		return nil
+	}
+	return proto.Clone(data).(ReplicatedData)
+}
+
+func (r *replicator) handleUpdate(c *actor.Context, msg updateData) dataResult {
+	data, ok := r.data[msg.key]
+	if !ok {
+		data = proto.Clone(msg.initial).(ReplicatedData)
+	} else if proto.MessageName(data) != proto.MessageName(msg.initial) {
+		return dataResult{err: fmt.Errorf("data is a %s", proto.MessageName(data))}
+	}
+	msg.modify(data, Writer{memberID: r.cluster.config.id})
+	r.data[msg.key] = data
+	r.notify(c, msg.key)
+	return dataResult{data: r.get(msg.key)}
+}
+
+// merge merges the given replica into ours, the subscribers are notified if
+// our replica changed. An error is returned when our replica is of another
+// type.
+func (r *replicator) merge(c *actor.Context, key string, replica ReplicatedData) error {
+	if replica == nil {
+		return nil
+	}
+	data, ok := r.data[key]
+	if !ok {
+		r.data[key] = proto.Clone(replica).(ReplicatedData)
+		r.notify(c, key)
+		return nil
+	}
+	if proto.MessageName(data) != proto.MessageName(replica) {
+		return fmt.Errorf("data is a %s, not a %s", proto.MessageName(data), proto.MessageName(replica))
+	}
+	digest := digestData(data)
+	data.merge(replica)
+	if digestData(data) != digest {
+		r.notify(c, key)
+	}
+	return nil
+}
+
+// logMismatch logs the error of a merge nobody waits for.
+func (r *replicator) logMismatch(key string, err error) {
+	if err != nil {
+		slog.Warn("[CLUSTER] replicated data type mismatch", "key", key, "err", err)
+	}
+}
+
+func (r *replicator) notify(c *actor.Context, key string) {
+	for _, pid := range r.subscribers[key] {
+		c.Send(pid, DataChanged{Key: key, Data: r.get(key)})
+	}
+}
+
+func (r *replicator) status(reply bool) *DataStatus {
+	status := &DataStatus{Digests: make(map[string]uint64, len(r.data)), Reply: reply}
+	for key, data := range r.data {
+		status.Digests[key] = digestData(data)
+	}
+	return status
+}
+
+// gossip sends the digests of our replicas to a random member.
+func (r *replicator) gossip(c *actor.Context) {
+	members := r.cluster.otherMembers()
+	if len(members) == 0 {
+		return
+	}
+	c.Send(replicatorPID(members[rand.Intn(len(members))]), r.status(false))
+}
+
+// handleStatus sends the replicas that differ from the digests in the status
+// back to the sender. If the sender has data we don't, it gets our digests
+// too, so it can send it to us.
+func (r *replicator) handleStatus(c *actor.Context, status *DataStatus) {
+	gossip := &DataGossip{Data: make(map[string]*DataEnvelope)}
+	for key, data := range r.data {
+		if digest, ok := status.Digests[key]; !ok || digest != digestData(data) {
+			gossip.Data[key] = wrapData(r.get(key))
+		}
+	}
+	if len(gossip.Data) > 0 {
+		c.Send(c.Sender(), gossip)
+	}
+	if status.Reply {
+		return
+	}
+	for key, digest := range status.Digests {
+		if data, ok := r.data[key]; !ok || digest != digestData(data) {
+			c.Send(c.Sender(), r.status(true))
+			return
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withGossipInterval sets the data gossip interval of the members started by
+// makeLeaveClusters.
+func withGossipInterval(d time.Duration) func(Config) Config {
+	return func(config Config) Config {
+		return config.WithDataGossipInterval(d)
+	}
+}
+
+func increment(c *Cluster, write Consistency) error {
+	return c.UpdateData("visits", &GCounter{}, write, func(data ReplicatedData, w Writer) {
+		data.(*GCounter).Increment(w, 1)
+	})
+}
+
+// counterValue doesn't fail the test right away, it's called from within
+// Eventually.
+func counterValue(t *testing.T, c *Cluster, read Consistency) uint64 {
+	data, err := c.GetData("visits", read)
+	if !assert.NoError(t, err) || data == nil {
+		return 0
+	}
+	return data.(*GCounter).Value()
+}
+
+func TestReplicatorConsistency(t *testing.T) {
+	// Gossip is too slow to play a role.
+	clusters := makeLeaveClusters(t, newCounter, NewKindConfig(), withGossipInterval(time.Minute))
+
+	require.NoError(t, increment(clusters[0], ConsistencyLocal))
+	assert.Equal(t, uint64(1), counterValue(t, clusters[0], ConsistencyLocal))
+	assert.Equal(t, uint64(0), counterValue(t, clusters[1], ConsistencyLocal))
+	// A majority read includes at least one other member, which may or may
+	// not have the data, a read from all does.
+	assert.Equal(t, uint64(1), counterValue(t, clusters[1], ConsistencyAll))
+	// The read repaired the replica of B.
+	require.Eventually(t, func() bool {
+		return counterValue(t, clusters[1], ConsistencyLocal) == 1
+	}, time.Second, time.Millisecond*10)
+
+	require.NoError(t, increment(clusters[1], ConsistencyAll))
+	for _, c := range clusters {
+		assert.Equal(t, uint64(2), counterValue(t, c, ConsistencyLocal))
+	}
+
+	// A majority write and a majority read always overlap.
+	require.NoError(t, increment(clusters[2], ConsistencyMajority))
+	for _, c := range clusters {
+		assert.Equal(t, uint64(3), counterValue(t, c, ConsistencyMajority))
+	}
+
+	err := clusters[0].UpdateData("visits", &Flag{}, ConsistencyLocal, func(ReplicatedData, Writer) {})
+	assert.ErrorContains(t, err, "cluster.GCounter")
+	data, err := clusters[0].GetData("unknown", ConsistencyAll)
+	require.NoError(t, err)
+	assert.Nil(t, data)
+}
+
+func TestReplicatorWriteTypeMismatch(t *testing.T) {
+	clusters := makeLeaveClusters(t, newCounter, NewKindConfig(), withGossipInterval(time.Minute))
+	require.NoError(t, clusters[1].UpdateData("visits", &Flag{}, ConsistencyLocal, func(data ReplicatedData, _ Writer) {
+		data.(*Flag).SwitchOn()
+	}))
+
+	// B can't apply the write, so it doesn't count towards the consistency.
+	err := increment(clusters[0], ConsistencyAll)
+	assert.ErrorContains(t, err, "2 of 3 members responded")
+	assert.ErrorContains(t, err, "member B: data is a cluster.Flag")
+	require.NoError(t, increment(clusters[0], ConsistencyMajority))
+}
+
+func TestReplicatorGossip(t *testing.T) {
+	clusters := makeLeaveClusters(t, newCounter, NewKindConfig(), withGossipInterval(time.Millisecond*20))
+	for _, c := range clusters {
+		require.NoError(t, increment(c, ConsistencyLocal))
+		require.NoError(t, c.UpdateData("members", &ORSet{}, ConsistencyLocal, func(data ReplicatedData, w Writer) {
+			data.(*ORSet).Add(w, c.ID())
+		}))
+	}
+	for _, c := range clusters {
+		require.Eventually(t, func() bool {
+			data, err := c.GetData("members", ConsistencyLocal)
+			if err != nil {
+				return false
+			}
+			return data != nil && data.(*ORSet).Len() == 3 &&
+				counterValue(t, c, ConsistencyLocal) == 3
+		}, time.Second*2, time.Millisecond*10)
+	}
+}
+
+func TestReplicatorSubscribe(t *testing.T) {
+	clusters := makeLeaveClusters(t, newCounter, NewKindConfig(), withGossipInterval(time.Millisecond*20))
+	changes := make(chan uint64, 10)
+	pid := clusters[1].Engine().SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(DataChanged); ok {
+			assert.Equal(t, "visits", msg.Key)
+			changes <- msg.Data.(*GCounter).Value()
+		}
+	}, "subscriber")
+	clusters[1].SubscribeData("visits", pid)
+
+	require.NoError(t, increment(clusters[0], ConsistencyLocal))
+	select {
+	case n := <-changes:
+		assert.Equal(t, uint64(1), n)
+	case <-time.After(time.Second):
+		t.Fatal("no change received")
+	}
+	require.NoError(t, increment(clusters[1], ConsistencyLocal))
+	assert.Equal(t, uint64(2), <-changes)
+
+	// Gossip that doesn't change the data is not notified.
+	time.Sleep(time.Millisecond * 100)
+	assert.Empty(t, changes)
+
+	clusters[1].UnsubscribeData("visits", pid)
+	require.NoError(t, increment(clusters[1], ConsistencyAll))
+	time.Sleep(time.Millisecond * 50)
+	assert.Empty(t, changes)
+}
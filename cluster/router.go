@@ -0,0 +1,246 @@
+package cluster
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/zeebo/xxh3"
+)
+
+// RoutingStrategy selects the routees of a router a message is sent to.
+// Strategies can be shared between routers, so they must be safe for
+// concurrent use.
+type RoutingStrategy interface {
+	// Route returns the routees the given message is sent to. The routees
+	// are sorted by their ID and never empty.
+	Route(msg any, routees []*actor.PID) []*actor.PID
+}
+
+type roundRobinRouting struct {
+	next atomic.Uint64
+}
+
+// RoundRobinRouting returns a strategy that sends each message to the next
+// routee.
+func RoundRobinRouting() RoutingStrategy {
+	return &roundRobinRouting{}
+}
+
+func (s *roundRobinRouting) Route(_ any, routees []*actor.PID) []*actor.PID {
+	i := s.next.Add(1) - 1
+	return routees[i%uint64(len(routees)) : i%uint64(len(routees))+1]
+}
+
+type randomRouting struct{}
+
+// RandomRouting returns a strategy that sends each message to a random
+// routee.
+func RandomRouting() RoutingStrategy {
+	return randomRouting{}
+}
+
+func (randomRouting) Route(_ any, routees []*actor.PID) []*actor.PID {
+	i := rand.Intn(len(routees))
+	return routees[i : i+1]
+}
+
+type consistentHashRouting struct {
+	key func(msg any) string
+}
+
+// ConsistentHashRouting returns a strategy that sends the messages with the
+// same key to the same routee, by rendezvous hashing of the key. When a
+// routee is added or removed, only the keys of that routee move. Messages
+// without a key are dropped.
+//
+//	cluster.ConsistentHashRouting(func(msg any) string {
+//		return msg.(*Order).CustomerId
+//	})
+func ConsistentHashRouting(key func(msg any) string) RoutingStrategy {
+	return consistentHashRouting{key: key}
+}
+
+func (s consistentHashRouting) Route(msg any, routees []*actor.PID) []*actor.PID {
+	key := s.key(msg)
+	if len(key) == 0 {
+		return nil
+	}
+	var (
+		selected int
+		max      uint64
+	)
+	for i, routee := range routees {
+		weight := xxh3.HashString(routee.String() + "/" + key)
+		if i == 0 || weight > max {
+			selected = i
+			max = weight
+		}
+	}
+	return routees[selected : selected+1]
+}
+
+type broadcastRouting struct{}
+
+// BroadcastRouting returns a strategy that sends each message to all the
+// routees.
+func BroadcastRouting() RoutingStrategy {
+	return broadcastRouting{}
+}
+
+func (broadcastRouting) Route(_ any, routees []*actor.PID) []*actor.PID {
+	return routees
+}
+
+type (
+	routeeAdded   struct{ pid *actor.PID }
+	routeeRemoved struct{ pid *actor.PID }
+	memberRemoved struct{ host string }
+	getRoutees    struct{}
+)
+
+// Router spawns a router for the activations of the given kind across the
+// cluster and returns its PID. The messages sent to the router are sent to
+// the activations the strategy selects, with the sender of the message, so
+// routees can respond to requests. The routees follow the activations and
+// deactivations of the kind, and the members that leave the cluster.
+//
+//	router := c.Router("player", cluster.RoundRobinRouting())
+//	c.Engine().Send(router, &Tick{})
+//
+// The router is stopped by poisoning its PID.
+func (c *Cluster) Router(kind string, strategy RoutingStrategy) *actor.PID {
+	return c.engine.Spawn(newRouter(c, kind, strategy), "router", actor.WithID(fmt.Sprintf("%s/%d", kind, rand.Intn(math.MaxInt))))
+}
+
+// Routees returns the routees the given router currently routes to.
+func (c *Cluster) Routees(router *actor.PID) []*actor.PID {
+	resp, err := c.engine.Request(router, getRoutees{}, c.config.requestTimeout).Result()
+	if err != nil {
+		return nil
+	}
+	routees, _ := resp.([]*actor.PID)
+	return routees
+}
+
+type router struct {
+	cluster  *Cluster
+	kind     string
+	strategy RoutingStrategy
+	// the routees by their ID.
+	routees map[string]*actor.PID
+	// the routees sorted by their ID.
+	sorted      []*actor.PID
+	eventSubPID *actor.PID
+}
+
+func newRouter(c *Cluster, kind string, strategy RoutingStrategy) actor.Producer {
+	return func() actor.Receiver {
+		return &router{
+			cluster:  c,
+			kind:     kind,
+			strategy: strategy,
+			routees:  make(map[string]*actor.PID),
+		}
+	}
+}
+
+func (r *router) Receive(c *actor.Context) {
+	switch msg := c.Message().(type) {
+	case actor.Started:
+		// Subscribe before taking the snapshot, so we don't miss anything.
+		r.eventSubPID = c.SpawnChildFunc(r.handleEventStream, "event")
+		r.cluster.engine.Subscribe(r.eventSubPID)
+		resp, err := c.Request(r.cluster.PID(), getActive{kind: r.kind}, r.cluster.config.requestTimeout).Result()
+		if err != nil {
+			slog.Warn("[CLUSTER] router without initial routees", "kind", r.kind, "err", err)
+		}
+		pids, _ := resp.([]*actor.PID)
+		for _, pid := range pids {
+			r.add(pid)
+		}
+	case actor.Stopped:
+		r.cluster.engine.Unsubscribe(r.eventSubPID)
+	case actor.Initialized:
+	case routeeAdded:
+		r.add(msg.pid)
+	case routeeRemoved:
+		r.remove(msg.pid)
+	case memberRemoved:
+		for _, pid := range r.routees {
+			if pid.Address == msg.host {
+				r.remove(pid)
+			}
+		}
+	case getRoutees:
+		c.Respond(slices.Clone(r.sorted))
+	default:
+		r.route(c, msg)
+	}
+}
+
+func (r *router) route(c *actor.Context, msg any) {
+	if len(r.sorted) == 0 {
+		slog.Warn("[CLUSTER] router dropped message without routees", "kind", r.kind, "msg", msg)
+		return
+	}
+	routees := r.strategy.Route(msg, r.sorted)
+	if len(routees) == 0 {
+		slog.Warn("[CLUSTER] router dropped message without routee", "kind", r.kind, "msg", msg)
+		return
+	}
+	// The routees get the header and deadline of the message as well.
+	env := c.Envelope()
+	for _, pid := range routees {
+		c.Engine().SendEnvelope(pid, env)
+	}
+}
+
+func (r *router) add(pid *actor.PID) {
+	if pid == nil || actor.KindOf(pid) != r.kind {
+		return
+	}
+	if known, ok := r.routees[pid.ID]; ok && known.Equals(pid) {
+		return
+	}
+	r.routees[pid.ID] = pid
+	r.sort()
+}
+
+func (r *router) remove(pid *actor.PID) {
+	if known, ok := r.routees[pid.ID]; ok && known.Equals(pid) {
+		delete(r.routees, pid.ID)
+		r.sort()
+	}
+}
+
+func (r *router) sort() {
+	r.sorted = r.sorted[:0]
+	for _, pid := range r.routees {
+		r.sorted = append(r.sorted, pid)
+	}
+	slices.SortFunc(r.sorted, func(a, b *actor.PID) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+// handleEventStream turns the cluster events into changes of the routees.
+func (r *router) handleEventStream(c *actor.Context) {
+	parent := c.Parent()
+	switch msg := c.Message().(type) {
+	case ActivationEvent:
+		c.Send(parent, routeeAdded{pid: msg.PID})
+	case DeactivationEvent:
+		c.Send(parent, routeeRemoved{pid: msg.PID})
+	case ActivationConflictEvent:
+		c.Send(parent, routeeRemoved{pid: msg.Loser})
+		c.Send(parent, routeeAdded{pid: msg.Winner})
+	case MemberLeaveEvent:
+		c.Send(parent, memberRemoved{host: msg.Member.Host})
+	}
+}
@@ -0,0 +1,177 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/khulnasoft/goactors/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeRoutees(n int) []*actor.PID {
+	routees := make([]*actor.PID, n)
+	for i := range routees {
+		routees[i] = actor.NewPID("127.0.0.1:4000", fmt.Sprintf("counter/%d", i))
+	}
+	return routees
+}
+
+func TestRoutingStrategies(t *testing.T) {
+	routees := makeRoutees(3)
+
+	rr := RoundRobinRouting()
+	for i := range 6 {
+		assert.Equal(t, []*actor.PID{routees[i%3]}, rr.Route(nil, routees))
+	}
+
+	random := RandomRouting()
+	for range 10 {
+		selected := random.Route(nil, routees)
+		require.Len(t, selected, 1)
+		assert.Contains(t, routees, selected[0])
+	}
+
+	assert.Equal(t, routees, BroadcastRouting().Route(nil, routees))
+}
+
+func TestConsistentHashRouting(t *testing.T) {
+	var (
+		routees = makeRoutees(5)
+		hash    = ConsistentHashRouting(func(msg any) string { return msg.(string) })
+		keys    = make(map[string]*actor.PID)
+	)
+	for i := range 100 {
+		key := fmt.Sprint(i)
+		selected := hash.Route(key, routees)
+		require.Len(t, selected, 1)
+		assert.Equal(t, selected, hash.Route(key, routees))
+		keys[key] = selected[0]
+	}
+	assert.Nil(t, hash.Route("", routees))
+
+	// Only the keys of the removed routee move.
+	for key, pid := range keys {
+		selected := hash.Route(key, routees[1:])
+		if pid.Equals(routees[0]) {
+			assert.NotEqual(t, routees[0], selected[0])
+		} else {
+			assert.Equal(t, pid, selected[0])
+		}
+	}
+}
+
+func TestRouter(t *testing.T) {
+	clusters := makeLeaveClusters(t, newCounter, NewKindConfig())
+	pids := make([]*actor.PID, 3)
+	for i, c := range clusters {
+		pids[i] = c.Activate("counter", NewActivationConfig().
+			WithID(fmt.Sprint(i)).
+			WithSelectMemberFunc(func(ActivationDetails) *Member { return c.Member() }))
+		require.NotNil(t, pids[i])
+	}
+
+	c := clusters[0]
+	router := c.Router("counter", RoundRobinRouting())
+	require.Eventually(t, func() bool {
+		return len(c.Routees(router)) == 3
+	}, time.Second, time.Millisecond*10)
+
+	// The routees respond to the sender of the message.
+	for range 6 {
+		_, err := c.Engine().Request(router, &remote.TestMessage{}, time.Second).Result()
+		require.NoError(t, err)
+	}
+	for _, pid := range pids {
+		assert.Equal(t, 3, requestCount(t, c, pid))
+	}
+
+	// A new activation is added to the routees.
+	pid := clusters[1].Activate("counter", NewActivationConfig().WithID("3"))
+	require.NotNil(t, pid)
+	require.Eventually(t, func() bool {
+		return fmt.Sprint(c.Routees(router)) == fmt.Sprint(append(pids, pid))
+	}, time.Second, time.Millisecond*10)
+
+	// A deactivation removes it again.
+	clusters[1].Deactivate(pid)
+	require.Eventually(t, func() bool {
+		return fmt.Sprint(c.Routees(router)) == fmt.Sprint(pids)
+	}, time.Second, time.Millisecond*10)
+
+	// The activations of a member that leaves are removed.
+	require.NoError(t, clusters[2].Leave(context.Background()))
+	require.Eventually(t, func() bool {
+		for _, routee := range c.Routees(router) {
+			if routee.Address == clusters[2].Address() {
+				return false
+			}
+		}
+		return true
+	}, time.Second*2, time.Millisecond*10)
+
+	<-c.Engine().Poison(router).Done()
+	assert.Nil(t, c.Routees(router))
+}
+
+func TestRouterBroadcast(t *testing.T) {
+	clusters := makeLeaveClusters(t, newCounter, NewKindConfig())
+	pids := make([]*actor.PID, 3)
+	for i := range pids {
+		pids[i] = clusters[i].Activate("counter", NewActivationConfig().WithID(fmt.Sprint(i)))
+		require.NotNil(t, pids[i])
+	}
+
+	c := clusters[1]
+	router := c.Router("counter", BroadcastRouting())
+	require.Eventually(t, func() bool {
+		return len(c.Routees(router)) == 3
+	}, time.Second, time.Millisecond*10)
+	// Each routee responds to the sender of the message.
+	responses := make(chan string, len(pids))
+	sender := c.Engine().SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*remote.TestMessage); ok {
+			responses <- string(msg.Data)
+		}
+	}, "sender")
+	c.Engine().SendWithSender(router, &remote.TestMessage{}, sender)
+	for range pids {
+		select {
+		case n := <-responses:
+			assert.Equal(t, "1", n)
+		case <-time.After(time.Second):
+			t.Fatal("missing response of a routee")
+		}
+	}
+	for _, pid := range pids {
+		assert.Equal(t, 2, requestCount(t, c, pid))
+	}
+}
+
+func TestRouterForwardsEnvelope(t *testing.T) {
+	results := make(chan envelopeResult, 1)
+	c := makeCluster(t, getRandomLocalhostAddr(), "A", "eu")
+	c.RegisterKind("probe", func() actor.Receiver {
+		return envelopeProbe{results: results}
+	}, NewKindConfig())
+	c.Start()
+	defer c.Stop()
+	require.Eventually(t, func() bool {
+		return len(c.Members()) == 1
+	}, time.Second, time.Millisecond*10)
+	require.NotNil(t, c.Activate("probe", NewActivationConfig().WithID("1")))
+
+	router := c.Router("probe", RoundRobinRouting())
+	require.Eventually(t, func() bool {
+		return len(c.Routees(router)) == 1
+	}, time.Second, time.Millisecond*10)
+
+	c.Engine().SendWithHeaders(router, &remote.TestMessage{}, actor.Header{"id": "1"})
+	assert.Equal(t, envelopeResult{header: "1"}, <-results)
+	_, err := c.Engine().Request(router, &remote.TestMessage{}, time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, envelopeResult{hasDeadline: true}, <-results)
+}
@@ -2,16 +2,11 @@ package cluster
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"log/slog"
-	"net"
 	"reflect"
-	"strconv"
 	"time"
 
 	"github.com/khulnasoft/goactors/actor"
-	"github.com/grandcat/zeroconf"
 )
 
 const (
@@ -31,15 +26,24 @@ type (
 		ListenAddr string
 	}
 	memberPing struct{}
+	gossipTick struct{}
 )
 
 type SelfManagedConfig struct {
 	bootstrapMembers []MemberAddr
+	discovery        []Discovery
+	failureDetector  FailureDetectorConfig
+	gossipInterval   time.Duration
+	gossipFanout     int
+	splitBrain       *SplitBrainConfig
 }
 
 func NewSelfManagedConfig() SelfManagedConfig {
 	return SelfManagedConfig{
 		bootstrapMembers: make([]MemberAddr, 0),
+		failureDetector:  NewFailureDetectorConfig(),
+		gossipInterval:   defaultGossipInterval,
+		gossipFanout:     defaultGossipFanout,
 	}
 }
 
@@ -48,19 +52,83 @@ func (c SelfManagedConfig) WithBootstrapMember(member MemberAddr) SelfManagedCon
 	return c
 }
 
+// WithDiscovery set's the discoveries that find the members to handshake
+// with, next to the bootstrap members. Multicast discovery is opt-in, as
+// multicast is often not available in CI and containers.
+//
+//	NewSelfManagedConfig().WithDiscovery(cluster.NewMDNSDiscovery())
+//
+// Defaults to no discovery, only the bootstrap members are contacted.
+func (c SelfManagedConfig) WithDiscovery(discovery ...Discovery) SelfManagedConfig {
+	c.discovery = discovery
+	return c
+}
+
+// WithFailureDetector set's the configuration of the failure detector that
+// decides when members are suspect or dead.
+//
+// Defaults to NewFailureDetectorConfig().
+func (c SelfManagedConfig) WithFailureDetector(config FailureDetectorConfig) SelfManagedConfig {
+	c.failureDetector = config
+	return c
+}
+
+// WithGossipInterval set's the interval of the gossip rounds in which the
+// member sends its view of the topology to random peers.
+//
+// Defaults to 1 second.
+func (c SelfManagedConfig) WithGossipInterval(d time.Duration) SelfManagedConfig {
+	c.gossipInterval = d
+	return c
+}
+
+// WithGossipFanout set's the number of random peers the topology is sent to
+// each gossip round.
+//
+// Defaults to 3.
+func (c SelfManagedConfig) WithGossipFanout(n int) SelfManagedConfig {
+	c.gossipFanout = n
+	return c
+}
+
+// WithSplitBrainResolver set's the split brain resolver that decides which
+// side of a network partition keeps running. With a resolver, unreachable
+// members are not removed by the failure detector, they are downed by the
+// side that survives once the partition is stable.
+//
+//	NewSelfManagedConfig().WithSplitBrainResolver(cluster.NewSplitBrainConfig(cluster.KeepMajority()))
+//
+// Defaults to no resolver, both sides of a partition keep running.
+func (c SelfManagedConfig) WithSplitBrainResolver(config SplitBrainConfig) SelfManagedConfig {
+	c.splitBrain = &config
+	return c
+}
+
 type SelfManaged struct {
 	config       SelfManagedConfig
 	cluster      *Cluster
 	members      *MemberSet
 	memberPinger actor.SendRepeater
+	gossiper     actor.SendRepeater
 	eventSubPID  *actor.PID
 
 	pid *actor.PID
 
 	membersAlive *MemberSet
 
-	resolver  *zeroconf.Resolver
-	announcer *zeroconf.Server
+	detector *phiAccrual
+	// the status of the members by their ID.
+	status map[string]MemberStatus
+	// the members that left the cluster by their ID, so we don't add them
+	// back when we learn about them from a peer that did not notice yet.
+	left map[string]tombstone
+
+	handshakePID *actor.PID
+
+	// nil when there is no split brain resolver configured.
+	resolver *splitBrainResolver
+	// true once the resolver decided our side of a partition goes down.
+	downed bool
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -68,12 +136,20 @@ type SelfManaged struct {
 
 func NewSelfManagedProvider(config SelfManagedConfig) Producer {
 	return func(c *Cluster) actor.Producer {
+		var resolver *splitBrainResolver
+		if config.splitBrain != nil {
+			resolver = newSplitBrainResolver(*config.splitBrain)
+		}
 		return func() actor.Receiver {
 			return &SelfManaged{
 				config:       config,
 				cluster:      c,
 				members:      NewMemberSet(),
 				membersAlive: NewMemberSet(),
+				detector:     newPhiAccrual(config.failureDetector),
+				status:       make(map[string]MemberStatus),
+				left:         make(map[string]tombstone),
+				resolver:     resolver,
 			}
 		}
 	}
@@ -88,13 +164,20 @@ func (s *SelfManaged) Receive(c *actor.Context) {
 		s.members.Add(s.cluster.Member())
 		s.sendMembersToAgent()
 
-		s.memberPinger = c.SendRepeat(c.PID(), memberPing{}, memberPingInterval)
+		s.memberPinger = c.SendRepeat(c.PID(), memberPing{}, s.config.failureDetector.heartbeatInterval)
+		s.gossiper = c.SendRepeat(c.PID(), gossipTick{}, s.config.gossipInterval)
 		s.start(c)
 	case actor.Stopped:
 		s.memberPinger.Stop()
+		s.gossiper.Stop()
 		s.cluster.engine.Unsubscribe(s.eventSubPID)
-		s.announcer.Shutdown()
+		s.cluster.engine.Poison(s.handshakePID)
 		s.cancel()
+		for _, d := range s.config.discovery {
+			if err := d.Stop(); err != nil {
+				newDiscoveryNotifier(s.cluster.engine, c.PID(), d).Failed(err)
+			}
+		}
 	case *Handshake:
 		s.addMembers(msg.Member)
 		members := s.members.Slice()
@@ -103,12 +186,31 @@ func (s *SelfManaged) Receive(c *actor.Context) {
 		})
 	case *Members:
 		s.addMembers(msg.Members...)
+	case discovered:
+		s.handleDiscovered(c, msg.member)
+	case *MembersLeave:
+		for _, member := range msg.Members {
+			if member.Host != s.cluster.agentPID.Address {
+				s.memberLeft(member)
+			}
+		}
+	case gossipTick:
+		s.gossip(c)
+	case *Topology:
+		s.handleTopology(c, msg)
 	case memberPing:
 		s.handleMemberPing(c)
+		s.detectFailures(c)
 	case memberLeave:
-		member := s.members.GetByHost(msg.ListenAddr)
-		s.removeMember(member)
+		// The split brain resolver decides about unreachable members.
+		if s.resolver != nil {
+			return
+		}
+		if member := s.members.GetByHost(msg.ListenAddr); member != nil {
+			s.memberDead(c, member, 0)
+		}
 	case *actor.Ping:
+		s.handleHeartbeat(msg)
 	case actor.Initialized:
 		_ = msg
 	default:
@@ -128,20 +230,136 @@ func (s *SelfManaged) handleMemberPing(c *actor.Context) {
 	})
 }
 
-func (s *SelfManaged) addMembers(members ...*Member) {
-	for _, member := range members {
-		if !s.members.Contains(member) {
-			s.members.Add(member)
+// handleHeartbeat records the ping of another member as a heartbeat.
+func (s *SelfManaged) handleHeartbeat(ping *actor.Ping) {
+	if ping.From == nil {
+		return
+	}
+	member := s.members.GetByHost(ping.From.Address)
+	if member == nil {
+		return
+	}
+	s.detector.heartbeat(member.ID, time.Now())
+	if s.status[member.ID] == MemberSuspect {
+		s.status[member.ID] = MemberAlive
+		s.cluster.engine.BroadcastEvent(MemberAliveEvent{Member: member})
+	}
+}
+
+// detectFailures moves the members through the alive, suspect and dead
+// statuses based on the suspicion level of the failure detector.
+func (s *SelfManaged) detectFailures(c *actor.Context) {
+	var (
+		now    = time.Now()
+		config = s.config.failureDetector
+	)
+	if s.downed {
+		return
+	}
+	for _, member := range s.members.Slice() {
+		if member.Host == s.cluster.agentPID.Address {
+			continue
+		}
+		phi := s.detector.phi(member.ID, now)
+		// A member always becomes suspect before it's dead, even when its
+		// phi passed both thresholds since the last check.
+		if phi >= config.suspectThreshold && s.status[member.ID] == MemberAlive {
+			s.status[member.ID] = MemberSuspect
+			s.cluster.engine.BroadcastEvent(MemberSuspectEvent{Member: member, Phi: phi})
+			slog.Debug("[CLUSTER] member suspect", "id", member.ID, "host", member.Host, "phi", phi)
+		}
+		if phi >= config.deadThreshold && s.resolver == nil {
+			s.memberDead(c, member, phi)
 		}
 	}
-	s.sendMembersToAgent()
+	if s.resolver != nil {
+		s.resolveSplitBrain(c, now)
+	}
 }
 
-func (s *SelfManaged) removeMember(member *Member) {
-	if s.members.Contains(member) {
-		s.members.Remove(member)
+// resolveSplitBrain lets the strategy decide about the suspect members once
+// the reachability is stable. Either they are downed, or we are.
+func (s *SelfManaged) resolveSplitBrain(c *actor.Context, now time.Time) {
+	var reachable, unreachable []*Member
+	for _, member := range s.members.Slice() {
+		if s.status[member.ID] == MemberSuspect {
+			unreachable = append(unreachable, member)
+		} else {
+			reachable = append(reachable, member)
+		}
+	}
+	if !s.resolver.stable(reachable, unreachable, now) {
+		return
+	}
+	survived := s.resolver.config.strategy.Survives(s.cluster.Member(), reachable, unreachable)
+	s.cluster.engine.BroadcastEvent(SplitBrainDecisionEvent{
+		Reachable:   reachable,
+		Unreachable: unreachable,
+		Survived:    survived,
+	})
+	if !survived {
+		slog.Warn("[CLUSTER] downing member, the other side of the partition survives",
+			"reachable", len(reachable),
+			"unreachable", len(unreachable))
+		s.downed = true
+		// Stopping the cluster waits for us to stop.
+		go s.cluster.down()
+		return
+	}
+	for _, member := range unreachable {
+		s.memberDead(c, member, s.detector.phi(member.ID, now))
 	}
-	s.sendMembersToAgent()
+}
+
+// memberDead removes the given member and lets the other members know, so
+// they don't have to wait for their own detector to come to the same
+// conclusion.
+func (s *SelfManaged) memberDead(c *actor.Context, member *Member, phi float64) {
+	if !s.members.Contains(member) {
+		return
+	}
+	s.memberLeft(member)
+	s.cluster.engine.BroadcastEvent(MemberDeadEvent{Member: member, Phi: phi})
+	slog.Debug("[CLUSTER] member dead", "id", member.ID, "host", member.Host, "phi", phi)
+
+	leave := &MembersLeave{Members: []*Member{member}}
+	s.members.ForEach(func(m *Member) bool {
+		if m.Host != s.cluster.agentPID.Address {
+			c.Send(memberToProviderPID(m), leave)
+		}
+		return true
+	})
+}
+
+func (s *SelfManaged) addMembers(members ...*Member) {
+	if s.mergeMembers(members...) {
+		s.sendMembersToAgent()
+	}
+}
+
+// mergeMembers adds the members we don't know yet, or that restarted since
+// we last saw them, and returns true if anything changed. The time a member
+// was started acts as its version: members that left are only added back
+// when they were restarted after leaving.
+func (s *SelfManaged) mergeMembers(members ...*Member) bool {
+	var (
+		now     = time.Now()
+		changed = false
+	)
+	for _, member := range members {
+		if t, ok := s.left[member.ID]; ok && member.Started <= t.member.Started {
+			continue
+		}
+		if known := s.members.GetByID(member.ID); known != nil && known.Started >= member.Started {
+			continue
+		}
+		delete(s.left, member.ID)
+		s.members.Add(member)
+		s.detector.heartbeat(member.ID, now)
+		s.status[member.ID] = MemberAlive
+		changed = true
+	}
+	return changed
 }
 
 // send all the current members to the local cluster agent.
@@ -156,71 +374,43 @@ func (s *SelfManaged) start(c *actor.Context) {
 	s.eventSubPID = c.SpawnChildFunc(s.handleEventStream, "event")
 	s.cluster.engine.Subscribe(s.eventSubPID)
 
-	// send handshake to all bootstrap members if any.
-	for _, member := range s.config.bootstrapMembers {
-		memberPID := actor.NewPID(member.ListenAddr, "provider/"+member.ID)
-		s.cluster.engine.SendWithSender(memberPID, &Handshake{
-			Member: s.cluster.Member(),
-		}, c.PID())
-	}
-
-	s.initAutoDiscovery()
-	s.startAutoDiscovery()
-}
-
-func (s *SelfManaged) initAutoDiscovery() {
-	resolver, err := zeroconf.NewResolver()
-	if err != nil {
-		log.Fatal(err)
-	}
-	s.resolver = resolver
-
-	host, portstr, err := net.SplitHostPort(s.cluster.agentPID.Address)
-	if err != nil {
-		log.Fatal(err)
-	}
-	port, err := strconv.Atoi(portstr)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	server, err := zeroconf.RegisterProxy(
-		s.cluster.ID(),
-		serviceName,
-		domain,
-		port,
-		fmt.Sprintf("member_%s", s.cluster.ID()),
-		[]string{host},
-		[]string{"txtv=0", "lo=1", "la=2"}, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	s.announcer = server
-}
-
-func (s *SelfManaged) startAutoDiscovery() {
-	entries := make(chan *zeroconf.ServiceEntry)
-	go func(results <-chan *zeroconf.ServiceEntry) {
-		for entry := range results {
-			if entry.Instance != s.cluster.ID() {
-				host := fmt.Sprintf("%s:%d", entry.AddrIPv4[0], entry.Port)
-				hs := &Handshake{
-					Member: s.cluster.Member(),
-				}
-				// create the reachable PID for this member.
-				memberPID := actor.NewPID(host, "provider/"+entry.Instance)
-				self := actor.NewPID(s.cluster.agentPID.Address, "provider/"+s.cluster.ID())
-				s.cluster.engine.SendWithSender(memberPID, hs, self)
-			}
+	// Members that only know our address send their handshake to our
+	// well-known endpoint.
+	s.handshakePID = spawnHandshakeEndpoint(s.cluster.engine, s.pid)
+
+	self := s.cluster.Member()
+	for _, d := range s.discoveries() {
+		notifier := newDiscoveryNotifier(s.cluster.engine, c.PID(), d)
+		if err := d.Start(s.ctx, self, notifier); err != nil {
+			notifier.Failed(err)
 		}
-		slog.Debug("[CLUSTER] stopping discovery", "id", s.cluster.ID())
-	}(entries)
+	}
+}
+
+// discoveries returns the configured discoveries, the bootstrap members go first.
+func (s *SelfManaged) discoveries() []Discovery {
+	discoveries := make([]Discovery, 0, len(s.config.discovery)+1)
+	if len(s.config.bootstrapMembers) > 0 {
+		discoveries = append(discoveries, NewStaticDiscovery(s.config.bootstrapMembers...))
+	}
+	return append(discoveries, s.config.discovery...)
+}
 
-	err := s.resolver.Browse(s.ctx, serviceName, domain, entries)
-	if err != nil {
-		slog.Error("[CLUSTER] discovery failed", "err", err)
-		panic(err)
+// handleDiscovered sends a handshake to the discovered member, unless it's
+// already part of the cluster.
+func (s *SelfManaged) handleDiscovered(c *actor.Context, member MemberAddr) {
+	if member.ListenAddr == s.cluster.agentPID.Address {
+		return
+	}
+	if len(member.ID) > 0 && s.members.GetByID(member.ID) != nil {
+		return
+	}
+	if len(member.ID) == 0 && s.members.GetByHost(member.ListenAddr) != nil {
+		return
 	}
+	s.cluster.engine.SendWithSender(memberAddrToProviderPID(member), &Handshake{
+		Member: s.cluster.Member(),
+	}, c.PID())
 }
 
 func (s *SelfManaged) handleEventStream(c *actor.Context) {
@@ -0,0 +1,806 @@
+package cluster
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/zeebo/xxh3"
+)
+
+// EntityMessage is implemented by messages that carry the ID of the entity
+// they are sent to. Protobuf messages with an `entity_id` string field
+// implement it out of the box.
+type EntityMessage interface {
+	GetEntityId() string
+}
+
+// EntityIDFunc extracts the ID of the entity the given message is sent to.
+// An empty ID drops the message.
+type EntityIDFunc func(msg any) string
+
+func entityIDOf(msg any) string {
+	if m, ok := msg.(EntityMessage); ok {
+		return m.GetEntityId()
+	}
+	return ""
+}
+
+// ShardingConfig holds the configuration of a sharded kind.
+type ShardingConfig struct {
+	numShards          int
+	entityID           EntityIDFunc
+	rebalanceInterval  time.Duration
+	rebalanceThreshold int
+	maxRebalance       int
+}
+
+// NewShardingConfig returns a new default ShardingConfig.
+func NewShardingConfig() ShardingConfig {
+	return ShardingConfig{
+		numShards:          100,
+		entityID:           entityIDOf,
+		rebalanceInterval:  2 * time.Second,
+		rebalanceThreshold: 1,
+		maxRebalance:       3,
+	}
+}
+
+// WithNumShards set's the number of shards the entities are divided into.
+// It must be the same on all the members and never change while the cluster
+// is running. A good number is ten times the maximum number of members.
+//
+// Defaults to 100.
+func (config ShardingConfig) WithNumShards(n int) ShardingConfig {
+	config.numShards = n
+	return config
+}
+
+// WithEntityID set's the function that extracts the ID of the entity from
+// the messages sent to the shard region.
+//
+// Defaults to the ID of messages implementing EntityMessage.
+func (config ShardingConfig) WithEntityID(fn EntityIDFunc) ShardingConfig {
+	config.entityID = fn
+	return config
+}
+
+// WithRebalanceInterval set's the interval in which the coordinator
+// rebalances the shards and the regions register with the coordinator.
+//
+// Defaults to 2 seconds.
+func (config ShardingConfig) WithRebalanceInterval(d time.Duration) ShardingConfig {
+	config.rebalanceInterval = d
+	return config
+}
+
+// WithRebalanceThreshold set's the difference in the number of shards
+// between the most and the least loaded region above which shards are moved.
+//
+// Defaults to 1.
+func (config ShardingConfig) WithRebalanceThreshold(n int) ShardingConfig {
+	config.rebalanceThreshold = n
+	return config
+}
+
+// WithMaxSimultaneousRebalance set's the maximum number of shards that are
+// handed off in a single rebalance round.
+//
+// Defaults to 3.
+func (config ShardingConfig) WithMaxSimultaneousRebalance(n int) ShardingConfig {
+	config.maxRebalance = n
+	return config
+}
+
+// shardOf returns the shard of the given entity.
+func (config ShardingConfig) shardOf(id string) int32 {
+	return int32(xxh3.HashString(id) % uint64(config.numShards))
+}
+
+type shardedKind struct {
+	name     string
+	producer actor.Producer
+	config   ShardingConfig
+}
+
+func coordinatorKind(kind string) string {
+	return kind + "-coordinator"
+}
+
+// RegisterShardedKind registers a kind of which the entities are divided
+// into shards. Each member that registers the kind runs a shard region,
+// which hosts the shards allocated to it and routes the messages of the
+// other shards to the region hosting them. The shards are allocated by a
+// coordinator, a cluster singleton, which rebalances them when members join
+// or leave. While a shard moves, the messages sent to its entities are
+// buffered, with their header and deadline. A member that leaves or stops
+// hands its shards off to the coordinator first.
+//
+// Entities implementing the Handoff interface carry their state over when
+// their shard moves.
+//
+//	c.RegisterShardedKind("cart", NewCart, cluster.NewShardingConfig())
+//	c.Start()
+//	c.Engine().Send(c.ShardRegion("cart"), &AddItem{EntityId: "cart-1"})
+//
+// NOTE: Sharded kinds can only be registered before the cluster is started.
+func (c *Cluster) RegisterShardedKind(kind string, producer actor.Producer, config ShardingConfig) {
+	if c.isStarted {
+		slog.Warn("failed to register sharded kind", "reason", "cluster already started", "kind", kind)
+		return
+	}
+	c.sharded = append(c.sharded, shardedKind{
+		name:     kind,
+		producer: producer,
+		config:   config,
+	})
+	c.RegisterKind(coordinatorKind(kind), newShardCoordinator(c, config), NewKindConfig().WithManagedSingleton(true))
+}
+
+// ShardRegion returns the PID of the local shard region of the given sharded
+// kind. Messages sent to it are delivered to their entity, sender included,
+// so requests work as expected.
+func (c *Cluster) ShardRegion(kind string) *actor.PID {
+	return actor.NewPID(c.engine.Address(), "shardregion/"+kind)
+}
+
+// stopRegions makes the local shard regions hand off their shards to the
+// coordinator, before the member stops hosting them.
+func (c *Cluster) stopRegions(timeout time.Duration) {
+	responses := make([]*actor.Response, len(c.regions))
+	for i, pid := range c.regions {
+		responses[i] = c.engine.Request(pid, shardRegionStop{}, timeout)
+	}
+	for i, resp := range responses {
+		if _, err := resp.Result(); err != nil {
+			slog.Warn("shard region handoff failed", "pid", c.regions[i], "err", err)
+		}
+	}
+}
+
+type (
+	// shardTick makes the regions register with the coordinator and the
+	// coordinator rebalance the shards.
+	shardTick struct{}
+	// shardEntitiesStopped is sent to the region once the entities of a
+	// shard are stopped, with the state they handed off.
+	shardEntitiesStopped struct {
+		shard  int32
+		states map[string][]byte
+		// true when the state goes to the coordinator, false when the shard
+		// is hosted elsewhere already.
+		handoff bool
+	}
+	// shardRegionStop makes the region hand off its shards to the
+	// coordinator, it responds with shardRegionStopped once their entities
+	// are stopped.
+	shardRegionStop    struct{}
+	shardRegionStopped struct{}
+	// shardCoordinatorResolved carries the PID of the coordinator the
+	// region resolved in the background.
+	shardCoordinatorResolved struct {
+		pid *actor.PID
+	}
+)
+
+// shardRegion hosts the entities of the shards allocated to it and routes the
+// messages of the other shards to their home.
+type shardRegion struct {
+	cluster *Cluster
+	kind    shardedKind
+	// the coordinator, nil until it's resolved.
+	coordinator *actor.PID
+	members     *memberWatch
+	ticker      actor.SendRepeater
+	// the region hosting the shards we know the home of.
+	homes map[int32]*actor.PID
+	// the messages waiting for the home of their shard, or for its entities
+	// to be stopped.
+	buffers map[int32][]actor.Envelope
+	// the state handed off to the entities of our shards that are not
+	// spawned yet, by entity ID.
+	handoff map[string][]byte
+	// the shards of which the entities are being stopped.
+	stopping map[int32]bool
+	// true once the region hands off its shards because it stops.
+	leaving bool
+	// true once the coordinator told the other regions to buffer the
+	// messages of the shards we hand off.
+	leaveAcked bool
+	// the senders waiting for the region to hand off its shards.
+	stopRequests []*actor.PID
+}
+
+func newShardRegion(c *Cluster, kind shardedKind) actor.Producer {
+	return func() actor.Receiver {
+		return &shardRegion{
+			cluster:  c,
+			kind:     kind,
+			members:  newMemberWatch(),
+			homes:    make(map[int32]*actor.PID),
+			buffers:  make(map[int32][]actor.Envelope),
+			handoff:  make(map[string][]byte),
+			stopping: make(map[int32]bool),
+		}
+	}
+}
+
+func (r *shardRegion) Receive(c *actor.Context) {
+	if r.members.handle(c.Message()) {
+		return
+	}
+	switch msg := c.Message().(type) {
+	case actor.Initialized:
+	case actor.Started:
+		r.members.start(c, r.cluster)
+		r.resolveCoordinator(c)
+		r.ticker = c.SendRepeat(c.PID(), shardTick{}, r.kind.config.rebalanceInterval)
+	case actor.Stopped:
+		r.ticker.Stop()
+		r.members.stop(c)
+		if n := r.buffered(); n > 0 {
+			slog.Warn("dropped buffered messages of stopped shard region", "kind", r.kind.name, "messages", n)
+		}
+	case shardCoordinatorResolved:
+		resolved := r.coordinator == nil
+		r.coordinator = msg.pid
+		if resolved && !r.leaving {
+			r.register(c)
+			r.requestHomes(c)
+		}
+	case shardTick:
+		if r.leaving {
+			return
+		}
+		// The grain of the coordinator could have been stopped.
+		r.resolveCoordinator(c)
+		r.pruneHomes()
+		r.register(c)
+		// The coordinator could have moved while we were waiting.
+		r.requestHomes(c)
+	case *ShardHome:
+		r.handleShardHome(c, msg)
+	case *ShardBeginHandoff:
+		delete(r.homes, msg.Shard)
+	case *ShardHandoff:
+		r.handleHandoff(c, msg.Shard)
+	case shardEntitiesStopped:
+		r.handleEntitiesStopped(c, msg)
+	case shardRegionStop:
+		r.handleStop(c)
+	case *ShardRegionStopped:
+		r.leaveAcked = true
+		r.maybeStopped(c)
+	default:
+		r.route(c, c.Envelope())
+	}
+}
+
+// route delivers the message to its entity, if we host its shard, forwards it
+// to the home of its shard, or buffers it until we know the home. The header
+// and deadline of the message are kept.
+func (r *shardRegion) route(c *actor.Context, env actor.Envelope) {
+	id := r.kind.config.entityID(env.Msg)
+	if len(id) == 0 {
+		slog.Warn("dropped message without entity ID", "kind", r.kind.name, "msg", reflect.TypeOf(env.Msg))
+		return
+	}
+	shard := r.kind.config.shardOf(id)
+	home, ok := r.homes[shard]
+	switch {
+	case ok && !home.Equals(c.PID()):
+		c.Engine().SendEnvelope(home, env)
+	case ok && !r.stopping[shard]:
+		c.Engine().SendEnvelope(r.entity(c, id), env)
+	case !ok && r.leaving && !r.stopping[shard]:
+		r.forwardToPeer(c, env)
+	default:
+		r.buffers[shard] = append(r.buffers[shard], env)
+		if !ok && !r.leaving && len(r.buffers[shard]) == 1 {
+			r.requestHome(c, shard)
+		}
+	}
+}
+
+// flush routes the messages buffered for the given shard again.
+func (r *shardRegion) flush(c *actor.Context, shard int32) {
+	buffered := r.buffers[shard]
+	delete(r.buffers, shard)
+	for _, env := range buffered {
+		r.route(c, env)
+	}
+}
+
+func (r *shardRegion) buffered() int {
+	n := 0
+	for _, buffered := range r.buffers {
+		n += len(buffered)
+	}
+	return n
+}
+
+// forwardToPeer hands the message to the region of another member, which
+// routes it once its shard is allocated again.
+func (r *shardRegion) forwardToPeer(c *actor.Context, env actor.Envelope) {
+	var peer *Member
+	r.members.members.ForEach(func(m *Member) bool {
+		if m.Host != c.PID().Address && m.HasKind(coordinatorKind(r.kind.name)) {
+			peer = m
+			return false
+		}
+		return true
+	})
+	if peer != nil {
+		c.Engine().SendEnvelope(actor.NewPID(peer.Host, c.PID().ID), env)
+		return
+	}
+	slog.Warn("dropped message, no other shard region", "kind", r.kind.name, "msg", reflect.TypeOf(env.Msg))
+}
+
+// entity returns the PID of the given entity, spawning it when needed.
+func (r *shardRegion) entity(c *actor.Context, id string) *actor.PID {
+	if pid := c.Child(r.entityPrefix(c) + id); pid != nil {
+		return pid
+	}
+	state := r.handoff[id]
+	delete(r.handoff, id)
+	return c.SpawnChild(r.kind.producer, r.kind.name,
+		actor.WithID(id),
+		actor.WithMiddleware(withHandoff(state)))
+}
+
+func (r *shardRegion) entityPrefix(c *actor.Context) string {
+	return c.PID().ID + "/" + r.kind.name + "/"
+}
+
+func (r *shardRegion) handleShardHome(c *actor.Context, msg *ShardHome) {
+	if prev, ok := r.homes[msg.Shard]; ok && prev.Equals(c.PID()) && !msg.Region.Equals(c.PID()) {
+		slog.Warn("dropped entities of shard allocated elsewhere", "kind", r.kind.name, "shard", msg.Shard)
+		r.stopShard(c, msg.Shard, false)
+	}
+	r.homes[msg.Shard] = msg.Region
+	for id, state := range msg.Handoff {
+		r.handoff[id] = state
+	}
+	r.flush(c, msg.Shard)
+}
+
+// handleHandoff stops the entities of the given shard, the coordinator gets
+// the state they handed off once they are stopped.
+func (r *shardRegion) handleHandoff(c *actor.Context, shard int32) {
+	delete(r.homes, shard)
+	r.stopShard(c, shard, true)
+}
+
+func (r *shardRegion) handleEntitiesStopped(c *actor.Context, msg shardEntitiesStopped) {
+	delete(r.stopping, msg.shard)
+	if msg.handoff {
+		// The coordinator allocates the shard again, we could have been
+		// told it's still ours in the meantime.
+		delete(r.homes, msg.shard)
+		r.sendCoordinator(c, &ShardStopped{
+			Shard:   msg.shard,
+			Region:  c.PID(),
+			Handoff: msg.states,
+		})
+	}
+	if !r.leaving {
+		r.flush(c, msg.shard)
+		return
+	}
+	r.maybeStopped(c)
+}
+
+// handleStop hands the shards we host off to the coordinator, so they are
+// allocated to the other regions together with the state of their entities.
+// The messages that arrive in the meantime are passed on to another region.
+func (r *shardRegion) handleStop(c *actor.Context) {
+	r.stopRequests = append(r.stopRequests, c.Sender())
+	if r.leaving {
+		r.maybeStopped(c)
+		return
+	}
+	r.leaving = true
+	shards := make([]int32, 0)
+	for shard, home := range r.homes {
+		if home.Equals(c.PID()) {
+			shards = append(shards, shard)
+		}
+	}
+	// The coordinator must not allocate shards to us anymore, and has to
+	// wait for the state of the shards we host. Without a coordinator no
+	// other region knows about our shards.
+	if !r.sendCoordinator(c, &ShardRegionStopped{Region: c.PID(), Shards: shards}) {
+		r.leaveAcked = true
+	}
+	for _, shard := range shards {
+		delete(r.homes, shard)
+		r.stopShard(c, shard, true)
+	}
+}
+
+// maybeStopped passes on the messages that are still buffered and lets the
+// senders of the stop request know our shards are handed off, once the
+// entities are stopped and the other regions no longer send us the messages
+// of our shards.
+func (r *shardRegion) maybeStopped(c *actor.Context) {
+	if !r.leaveAcked || len(r.stopping) > 0 {
+		return
+	}
+	for shard := range r.buffers {
+		r.flush(c, shard)
+	}
+	for _, pid := range r.stopRequests {
+		if pid != nil {
+			c.Send(pid, shardRegionStopped{})
+		}
+	}
+	r.stopRequests = nil
+}
+
+// stopShard stops the entities of the given shard in the background, so the
+// region keeps routing the messages of the other shards. The region gets a
+// shardEntitiesStopped with the state the entities handed off once they are
+// stopped, the messages of the shard are buffered until then.
+func (r *shardRegion) stopShard(c *actor.Context, shard int32, handoff bool) {
+	var (
+		prefix = r.entityPrefix(c)
+		states = make(map[string][]byte)
+		pids   = make([]*actor.PID, 0)
+	)
+	for _, pid := range c.Children() {
+		id, ok := strings.CutPrefix(pid.ID, prefix)
+		if ok && r.kind.config.shardOf(id) == shard {
+			pids = append(pids, pid)
+		}
+	}
+	// Entities that never got spawned here keep the state they got.
+	for id, state := range r.handoff {
+		if r.kind.config.shardOf(id) == shard {
+			states[id] = state
+			delete(r.handoff, id)
+		}
+	}
+	r.stopping[shard] = true
+
+	var (
+		engine  = c.Engine()
+		self    = c.PID()
+		timeout = r.cluster.config.requestTimeout
+	)
+	go func() {
+		responses := make([]*actor.Response, len(pids))
+		for i, pid := range pids {
+			responses[i] = engine.Request(pid, handoffState{}, timeout)
+		}
+		for i, resp := range responses {
+			result, err := resp.Result()
+			if err != nil {
+				slog.Warn("entity handoff without state", "pid", pids[i], "err", err)
+			} else if result, ok := result.(handoffStateResult); ok && result.state != nil {
+				states[strings.TrimPrefix(pids[i].ID, prefix)] = result.state
+			}
+		}
+		done := make([]context.Context, len(pids))
+		for i, pid := range pids {
+			done[i] = engine.Poison(pid)
+		}
+		for _, ctx := range done {
+			<-ctx.Done()
+		}
+		engine.Send(self, shardEntitiesStopped{shard: shard, states: states, handoff: handoff})
+	}()
+}
+
+// pruneHomes forgets the homes on members that left the cluster, the shards
+// are allocated again when they are used.
+func (r *shardRegion) pruneHomes() {
+	// Don't forget the homes before we know the members.
+	if r.members.members.Len() == 0 {
+		return
+	}
+	hosts := r.members.hosts()
+	for shard, home := range r.homes {
+		if !hosts[home.Address] {
+			delete(r.homes, shard)
+		}
+	}
+}
+
+func (r *shardRegion) requestHome(c *actor.Context, shard int32) {
+	r.sendCoordinator(c, &ShardHomeRequest{
+		Shard:  shard,
+		Region: c.PID(),
+	})
+}
+
+// requestHomes requests the homes of the shards we buffer messages for.
+func (r *shardRegion) requestHomes(c *actor.Context) {
+	for shard := range r.buffers {
+		if _, ok := r.homes[shard]; !ok {
+			r.requestHome(c, shard)
+		}
+	}
+}
+
+// resolveCoordinator resolves the coordinator in the background, the region
+// gets a shardCoordinatorResolved.
+func (r *shardRegion) resolveCoordinator(c *actor.Context) {
+	var (
+		engine = c.Engine()
+		self   = c.PID()
+	)
+	go func() {
+		if pid := r.cluster.Singleton(coordinatorKind(r.kind.name)); pid != nil {
+			engine.Send(self, shardCoordinatorResolved{pid: pid})
+		}
+	}()
+}
+
+// sendCoordinator sends the message to the coordinator, it returns false if
+// the coordinator is not resolved yet.
+func (r *shardRegion) sendCoordinator(c *actor.Context, msg any) bool {
+	if r.coordinator == nil {
+		return false
+	}
+	c.Send(r.coordinator, msg)
+	return true
+}
+
+// register tells the coordinator we exist and which shards we host, so a
+// coordinator that moved to another member can rebuild its state.
+func (r *shardRegion) register(c *actor.Context) {
+	shards := make([]int32, 0)
+	for shard, home := range r.homes {
+		if home.Equals(c.PID()) {
+			shards = append(shards, shard)
+		}
+	}
+	r.sendCoordinator(c, &ShardRegionRegister{
+		Region: c.PID(),
+		Shards: shards,
+	})
+}
+
+// rebalance is a shard that is being handed off.
+type rebalance struct {
+	from *actor.PID
+	// the regions that asked for the home of the shard in the mean time.
+	waiting []*actor.PID
+}
+
+// shardCoordinator allocates the shards of a sharded kind to the regions.
+// Shards are allocated to the region hosting the least shards when they are
+// first used, and moved from the region hosting the most shards to the one
+// hosting the least when the difference exceeds the rebalance threshold.
+type shardCoordinator struct {
+	cluster *Cluster
+	config  ShardingConfig
+	members *memberWatch
+	ticker  actor.SendRepeater
+	// the registered regions by their address.
+	regions map[string]*actor.PID
+	// the region hosting each allocated shard.
+	shards      map[int32]*actor.PID
+	rebalancing map[int32]*rebalance
+}
+
+func newShardCoordinator(c *Cluster, config ShardingConfig) actor.Producer {
+	return func() actor.Receiver {
+		return &shardCoordinator{
+			cluster:     c,
+			config:      config,
+			members:     newMemberWatch(),
+			regions:     make(map[string]*actor.PID),
+			shards:      make(map[int32]*actor.PID),
+			rebalancing: make(map[int32]*rebalance),
+		}
+	}
+}
+
+func (sc *shardCoordinator) Receive(c *actor.Context) {
+	if sc.members.handle(c.Message()) {
+		return
+	}
+	switch msg := c.Message().(type) {
+	case actor.Started:
+		sc.members.start(c, sc.cluster)
+		sc.ticker = c.SendRepeat(c.PID(), shardTick{}, sc.config.rebalanceInterval)
+	case actor.Stopped:
+		sc.ticker.Stop()
+		sc.members.stop(c)
+	case *ShardRegionRegister:
+		sc.regions[msg.Region.Address] = msg.Region
+		for _, shard := range msg.Shards {
+			if _, moving := sc.rebalancing[shard]; moving {
+				continue
+			}
+			home, ok := sc.shards[shard]
+			if !ok {
+				sc.shards[shard] = msg.Region
+				continue
+			}
+			// Two coordinators were active while the cluster was forming,
+			// the region has to give up its copy of the shard.
+			if !home.Equals(msg.Region) {
+				c.Send(msg.Region, &ShardHome{Shard: shard, Region: home})
+			}
+		}
+	case *ShardHomeRequest:
+		sc.regions[msg.Region.Address] = msg.Region
+		sc.handleHomeRequest(c, msg)
+	case *ShardStopped:
+		sc.allocate(c, msg.Shard, msg.Handoff)
+	case *ShardRegionStopped:
+		sc.handleRegionStopped(c, msg)
+	case shardTick:
+		sc.prune(c)
+		sc.rebalance(c)
+	}
+}
+
+func (sc *shardCoordinator) handleHomeRequest(c *actor.Context, msg *ShardHomeRequest) {
+	if rb, ok := sc.rebalancing[msg.Shard]; ok {
+		rb.waiting = append(rb.waiting, msg.Region)
+		return
+	}
+	home, ok := sc.shards[msg.Shard]
+	if !ok {
+		home = sc.leastLoaded()
+		sc.shards[msg.Shard] = home
+		// Let the new home know right away, instead of when the first
+		// message arrives.
+		if !home.Equals(msg.Region) {
+			c.Send(home, &ShardHome{Shard: msg.Shard, Region: home})
+		}
+	}
+	c.Send(msg.Region, &ShardHome{Shard: msg.Shard, Region: home})
+}
+
+// handleRegionStopped drops the region that stops. The shards it hands off
+// are allocated again once their state arrives, the regions buffer their
+// messages until then.
+func (sc *shardCoordinator) handleRegionStopped(c *actor.Context, msg *ShardRegionStopped) {
+	delete(sc.regions, msg.Region.Address)
+	for shard, region := range sc.shards {
+		if region.Equals(msg.Region) {
+			delete(sc.shards, shard)
+		}
+	}
+	for _, shard := range msg.Shards {
+		if _, ok := sc.rebalancing[shard]; ok {
+			continue
+		}
+		sc.rebalancing[shard] = &rebalance{from: msg.Region}
+		for _, region := range sc.regions {
+			c.Send(region, &ShardBeginHandoff{Shard: shard})
+		}
+	}
+	// Let the region know the other regions buffer the messages of its
+	// shards from now on.
+	c.Send(msg.Region, msg)
+}
+
+// allocate allocates the given shard that was handed off to the least loaded
+// region, and tells the regions that were waiting for it.
+func (sc *shardCoordinator) allocate(c *actor.Context, shard int32, handoff map[string][]byte) {
+	var waiting []*actor.PID
+	if rb, ok := sc.rebalancing[shard]; ok {
+		waiting = rb.waiting
+		delete(sc.rebalancing, shard)
+	}
+	home := sc.leastLoaded()
+	if home == nil {
+		return
+	}
+	sc.shards[shard] = home
+	c.Send(home, &ShardHome{Shard: shard, Region: home, Handoff: handoff})
+	for _, region := range waiting {
+		if !region.Equals(home) {
+			c.Send(region, &ShardHome{Shard: shard, Region: home})
+		}
+	}
+}
+
+// prune drops the regions of the members that left the cluster. Their shards
+// are allocated again when they are used.
+func (sc *shardCoordinator) prune(c *actor.Context) {
+	// Don't drop the regions before we know the members.
+	if sc.members.members.Len() == 0 {
+		return
+	}
+	hosts := sc.members.hosts()
+	for address := range sc.regions {
+		if !hosts[address] {
+			delete(sc.regions, address)
+		}
+	}
+	for shard, region := range sc.shards {
+		if !hosts[region.Address] {
+			delete(sc.shards, shard)
+		}
+	}
+	for shard, rb := range sc.rebalancing {
+		if !hosts[rb.from.Address] {
+			sc.allocate(c, shard, nil)
+		}
+	}
+}
+
+// rebalance moves shards from the most to the least loaded region, unless the
+// previous round is still in progress.
+func (sc *shardCoordinator) rebalance(c *actor.Context) {
+	if len(sc.rebalancing) > 0 || len(sc.regions) < 2 {
+		return
+	}
+	hosted := make(map[string][]int32)
+	for address := range sc.regions {
+		hosted[address] = []int32{}
+	}
+	for shard, region := range sc.shards {
+		hosted[region.Address] = append(hosted[region.Address], shard)
+	}
+	for range sc.config.maxRebalance {
+		most, least := sc.extremes(hosted)
+		if len(hosted[most])-len(hosted[least]) <= sc.config.rebalanceThreshold {
+			return
+		}
+		slices.Sort(hosted[most])
+		shard := hosted[most][0]
+		hosted[most] = hosted[most][1:]
+		hosted[least] = append(hosted[least], shard)
+
+		from := sc.shards[shard]
+		delete(sc.shards, shard)
+		sc.rebalancing[shard] = &rebalance{from: from}
+		// Make all regions buffer the messages of the shard, before its
+		// entities are stopped.
+		for _, region := range sc.regions {
+			c.Send(region, &ShardBeginHandoff{Shard: shard})
+		}
+		c.Send(from, &ShardHandoff{Shard: shard})
+	}
+}
+
+// extremes returns the address of the region hosting the most and the one
+// hosting the least shards. Ties are broken by address.
+func (sc *shardCoordinator) extremes(hosted map[string][]int32) (string, string) {
+	addresses := make([]string, 0, len(hosted))
+	for address := range hosted {
+		addresses = append(addresses, address)
+	}
+	slices.Sort(addresses)
+	most, least := addresses[0], addresses[0]
+	for _, address := range addresses[1:] {
+		if len(hosted[address]) > len(hosted[most]) {
+			most = address
+		}
+		if len(hosted[address]) < len(hosted[least]) {
+			least = address
+		}
+	}
+	return most, least
+}
+
+// leastLoaded returns the region hosting the least shards, nil if there are
+// no regions.
+func (sc *shardCoordinator) leastLoaded() *actor.PID {
+	hosted := make(map[string][]int32)
+	for address := range sc.regions {
+		hosted[address] = []int32{}
+	}
+	if len(hosted) == 0 {
+		return nil
+	}
+	for shard, region := range sc.shards {
+		if _, ok := hosted[region.Address]; ok {
+			hosted[region.Address] = append(hosted[region.Address], shard)
+		}
+	}
+	_, least := sc.extremes(hosted)
+	return sc.regions[least]
+}
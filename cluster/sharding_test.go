@@ -0,0 +1,258 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/khulnasoft/goactors/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testShardingConfig routes the test messages to the entity in their data.
+func testShardingConfig() ShardingConfig {
+	return NewShardingConfig().
+		WithNumShards(10).
+		WithRebalanceInterval(50 * time.Millisecond).
+		WithEntityID(func(msg any) string {
+			if m, ok := msg.(*remote.TestMessage); ok {
+				return string(m.Data)
+			}
+			return ""
+		})
+}
+
+func makeShardingCluster(t *testing.T, id string, bootstrap ...MemberAddr) *Cluster {
+	c := makeClusterWithBootstrap(t, getRandomLocalhostAddr(), id, "eu", bootstrap...)
+	c.RegisterShardedKind("counter", newHandoffCounter, testShardingConfig())
+	c.Start()
+	t.Cleanup(c.Stop)
+	return c
+}
+
+func requestEntity(t *testing.T, c *Cluster, id string) int {
+	resp, err := c.Engine().Request(c.ShardRegion("counter"), &remote.TestMessage{Data: []byte(id)}, 2*time.Second).Result()
+	require.NoError(t, err)
+	msg, ok := resp.(*remote.TestMessage)
+	require.True(t, ok)
+	n, err := strconv.Atoi(string(msg.Data))
+	require.NoError(t, err)
+	return n
+}
+
+// hostedEntities returns the number of entities the region of the given
+// member hosts.
+func hostedEntities(c *Cluster, ids []string) int {
+	n := 0
+	for _, id := range ids {
+		if c.Engine().Registry.GetPID("shardregion/counter/counter", id) != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func TestShardOf(t *testing.T) {
+	config := NewShardingConfig().WithNumShards(10)
+	for i := range 100 {
+		shard := config.shardOf(strconv.Itoa(i))
+		assert.GreaterOrEqual(t, shard, int32(0))
+		assert.Less(t, shard, int32(10))
+		assert.Equal(t, shard, config.shardOf(strconv.Itoa(i)))
+	}
+}
+
+func TestShardingRoutesToSingleEntity(t *testing.T) {
+	c1 := makeShardingCluster(t, "A")
+	c2 := makeShardingCluster(t, "B", MemberAddr{ListenAddr: c1.Address(), ID: "A"})
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 2 && len(c2.Members()) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	for i := range 10 {
+		id := fmt.Sprintf("entity-%d", i)
+		// every region routes to the same entity.
+		assert.Equal(t, 1, requestEntity(t, c1, id))
+		assert.Equal(t, 2, requestEntity(t, c2, id))
+		assert.Equal(t, 3, requestEntity(t, c1, id))
+	}
+}
+
+func TestShardingDropsMessagesWithoutEntityID(t *testing.T) {
+	c := makeShardingCluster(t, "A")
+	_, err := c.Engine().Request(c.ShardRegion("counter"), &actor.Ping{}, 100*time.Millisecond).Result()
+	assert.Error(t, err)
+	assert.Equal(t, 1, requestEntity(t, c, "1"))
+}
+
+func TestShardingRebalancesOnJoin(t *testing.T) {
+	c1 := makeShardingCluster(t, "A")
+	ids := make([]string, 30)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("entity-%d", i)
+		assert.Equal(t, 1, requestEntity(t, c1, ids[i]))
+	}
+	assert.Equal(t, len(ids), hostedEntities(c1, ids))
+
+	c2 := makeShardingCluster(t, "B", MemberAddr{ListenAddr: c1.Address(), ID: "A"})
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 2 && len(c2.Members()) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Keep talking to the entities while the shards move, none of the
+	// messages may get lost and the entities keep their count.
+	counts := make(map[string]int)
+	for _, id := range ids {
+		counts[id] = 1
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for i, id := range ids {
+			c := c1
+			if i%2 == 0 {
+				c = c2
+			}
+			counts[id]++
+			require.Equal(t, counts[id], requestEntity(t, c, id), "entity %s", id)
+		}
+		if n := hostedEntities(c2, ids); n > 0 && hostedEntities(c1, ids)+n == len(ids) {
+			break
+		}
+	}
+	assert.Greater(t, hostedEntities(c2, ids), 0)
+	assert.Equal(t, len(ids), hostedEntities(c1, ids)+hostedEntities(c2, ids))
+}
+
+func TestShardingReallocatesShardsOfLeavingMember(t *testing.T) {
+	c1 := makeShardingCluster(t, "A")
+	c2 := makeShardingCluster(t, "B", MemberAddr{ListenAddr: c1.Address(), ID: "A"})
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 2 && len(c2.Members()) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	ids := make([]string, 20)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("entity-%d", i)
+	}
+	// B hosts shards once its region registered and the shards got balanced.
+	require.Eventually(t, func() bool {
+		for _, id := range ids {
+			requestEntity(t, c1, id)
+		}
+		return hostedEntities(c2, ids) > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, c2.Leave(context.Background()))
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// The entities of the member that left are started again on the
+	// remaining member.
+	require.Eventually(t, func() bool {
+		for _, id := range ids {
+			_, err := c1.Engine().Request(c1.ShardRegion("counter"), &remote.TestMessage{Data: []byte(id)}, 100*time.Millisecond).Result()
+			if err != nil {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, len(ids), hostedEntities(c1, ids))
+}
+
+func TestShardingHandsOffShardsOfLeavingMember(t *testing.T) {
+	c1 := makeShardingCluster(t, "A")
+	c2 := makeShardingCluster(t, "B", MemberAddr{ListenAddr: c1.Address(), ID: "A"})
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 2 && len(c2.Members()) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	ids := make([]string, 20)
+	counts := make(map[string]int)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("entity-%d", i)
+	}
+	require.Eventually(t, func() bool {
+		for _, id := range ids {
+			counts[id] = requestEntity(t, c1, id)
+		}
+		return hostedEntities(c2, ids) > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, c2.Leave(context.Background()))
+	// the entities of the member that left kept their count.
+	for _, id := range ids {
+		assert.Equal(t, counts[id]+1, requestEntity(t, c1, id), "entity %s", id)
+	}
+	assert.Equal(t, len(ids), hostedEntities(c1, ids))
+}
+
+func TestShardingForwardsEnvelope(t *testing.T) {
+	results := make(chan envelopeResult, 1)
+	makeMember := func(id string, bootstrap ...MemberAddr) *Cluster {
+		c := makeClusterWithBootstrap(t, getRandomLocalhostAddr(), id, "eu", bootstrap...)
+		c.RegisterShardedKind("probe", func() actor.Receiver {
+			return envelopeProbe{results: results}
+		}, testShardingConfig())
+		c.Start()
+		t.Cleanup(c.Stop)
+		return c
+	}
+	c1 := makeMember("A")
+	c2 := makeMember("B", MemberAddr{ListenAddr: c1.Address(), ID: "A"})
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 2 && len(c2.Members()) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// the entities are spread over both members, some messages go through
+	// the other region.
+	for i := range 10 {
+		msg := &remote.TestMessage{Data: []byte(strconv.Itoa(i))}
+		c1.Engine().SendWithHeaders(c1.ShardRegion("probe"), msg, actor.Header{"id": "1"})
+		assert.Equal(t, envelopeResult{header: "1"}, <-results)
+		_, err := c1.Engine().Request(c1.ShardRegion("probe"), msg, time.Second).Result()
+		require.NoError(t, err)
+		assert.Equal(t, envelopeResult{hasDeadline: true}, <-results)
+	}
+}
+
+// slowHandoffCounter takes a while to hand off its state.
+type slowHandoffCounter struct {
+	handoffCounter
+}
+
+func (c *slowHandoffCounter) HandoffState() ([]byte, error) {
+	time.Sleep(500 * time.Millisecond)
+	return c.handoffCounter.HandoffState()
+}
+
+func TestShardingStopsShardInBackground(t *testing.T) {
+	c := makeClusterWithBootstrap(t, getRandomLocalhostAddr(), "A", "eu")
+	c.RegisterShardedKind("counter", func() actor.Receiver {
+		return &slowHandoffCounter{}
+	}, testShardingConfig())
+	c.Start()
+	t.Cleanup(c.Stop)
+
+	config := testShardingConfig()
+	slow, fast := "1", "2"
+	for config.shardOf(fast) == config.shardOf(slow) {
+		fast += "0"
+	}
+	assert.Equal(t, 1, requestEntity(t, c, slow))
+	assert.Equal(t, 1, requestEntity(t, c, fast))
+
+	c.Engine().Send(c.ShardRegion("counter"), &ShardHandoff{Shard: config.shardOf(slow)})
+	// the region keeps routing the messages of the other shards while the
+	// entities of the shard hand off their state.
+	start := time.Now()
+	assert.Equal(t, 2, requestEntity(t, c, fast))
+	assert.Less(t, time.Since(start), 250*time.Millisecond)
+	// the messages of the shard are buffered until it's allocated again.
+	assert.Equal(t, 2, requestEntity(t, c, slow))
+}
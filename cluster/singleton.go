@@ -0,0 +1,89 @@
+package cluster
+
+import (
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
+
+// singletonID is the ID singletons are activated with.
+const singletonID = "singleton"
+
+// singletonRetryInterval is the time after which the activation of a managed
+// singleton is retried when it failed.
+const singletonRetryInterval = time.Millisecond * 500
+
+// retrySingletons makes the agent retry the activation of the managed
+// singletons.
+type retrySingletons struct{}
+
+func singletonActivationConfig() ActivationConfig {
+	return NewActivationConfig().
+		WithID(singletonID).
+		WithSelectMemberFunc(SelectOldestMember)
+}
+
+// Singleton returns a PID that always routes to the single instance of the
+// given kind in the cluster. The instance is activated on the oldest member
+// that has the kind registered when it's not active yet.
+//
+// Kinds registered with KindConfig.WithManagedSingleton are activated on the
+// oldest member as soon as the cluster is formed, and moved to the next
+// oldest member when the owner leaves. Messages sent while the singleton is
+// moving can be lost.
+//
+//	c.RegisterKind("scheduler", NewScheduler, cluster.NewKindConfig().WithManagedSingleton(true))
+//	c.Start()
+//	c.Engine().Send(c.Singleton("scheduler"), &Schedule{})
+func (c *Cluster) Singleton(kind string) *actor.PID {
+	return c.getGrain(kind, singletonActivationConfig())
+}
+
+// ensureSingletons makes sure the managed singleton kinds we have registered
+// locally are activated on their owner. If we own a singleton that is not
+// active we activate it, if we host a singleton we no longer own we
+// deactivate it, so the owner can take over. A failed activation is retried
+// after singletonRetryInterval.
+func (a *Agent) ensureSingletons() {
+	for name, kind := range a.localKinds {
+		if !kind.config.managedSingleton {
+			continue
+		}
+		owner := SelectOldestMember(ActivationDetails{
+			Members: a.membersForKind(name),
+			Kind:    name,
+			ID:      singletonID,
+		})
+		if owner == nil {
+			continue
+		}
+		active := a.activatedByKind(name, "")
+		if owner.ID == a.cluster.config.id {
+			if len(active) == 0 {
+				if pid, _ := a.activate(name, singletonActivationConfig()); pid == nil {
+					a.retrySingletons()
+				}
+			}
+			continue
+		}
+		for _, pid := range active {
+			if pid.Address == a.cluster.engine.Address() {
+				a.bcast(&Deactivation{PID: pid})
+			}
+		}
+	}
+}
+
+func (a *Agent) retrySingletons() {
+	if a.singletonRetry {
+		return
+	}
+	a.singletonRetry = true
+	var (
+		engine = a.cluster.engine
+		self   = a.cluster.agentPID
+	)
+	time.AfterFunc(singletonRetryInterval, func() {
+		engine.Send(self, retrySingletons{})
+	})
+}
@@ -0,0 +1,59 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/khulnasoft/goactors/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectOldestMember(t *testing.T) {
+	members := []*Member{
+		{ID: "B", Started: 2},
+		{ID: "A", Started: 3},
+		{ID: "C", Started: 2},
+	}
+	assert.Equal(t, "B", SelectOldestMember(ActivationDetails{Members: members}).ID)
+	assert.Nil(t, SelectOldestMember(ActivationDetails{}))
+}
+
+func TestSingletonFailover(t *testing.T) {
+	c1Addr := getRandomLocalhostAddr()
+	config := NewKindConfig().WithManagedSingleton(true)
+
+	r := remote.New(c1Addr, remote.NewConfig())
+	e, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(r))
+	require.NoError(t, err)
+	c1, err := New(NewConfig().WithID("A").WithEngine(e))
+	require.NoError(t, err)
+	c1.RegisterKind("counter", newCounter, config)
+	c1.Start()
+	defer c1.Stop()
+
+	c2 := makeClusterWithBootstrap(t, getRandomLocalhostAddr(), "B", "eu", MemberAddr{ListenAddr: c1Addr, ID: "A"})
+	c2.RegisterKind("counter", newCounter, config)
+	c2.Start()
+	defer c2.Stop()
+
+	// the singleton is activated on the oldest member without sending to it.
+	require.Eventually(t, func() bool {
+		return len(c2.Members()) == 2 && len(c2.GetActiveByKind("counter")) == 1 &&
+			c2.GetActiveByID("counter/singleton") != nil
+	}, time.Second, time.Millisecond*10)
+	assert.Equal(t, c1Addr, c2.GetActiveByID("counter/singleton").Address)
+
+	pid := c2.Singleton("counter")
+	assert.Equal(t, 1, requestCount(t, c2, pid))
+	assert.Equal(t, 2, requestCount(t, c1, c1.Singleton("counter")))
+
+	// the owner leaves, member B takes over.
+	r.Stop().Wait()
+	require.Eventually(t, func() bool {
+		active := c2.GetActiveByID("counter/singleton")
+		return active != nil && active.Address == c2.Address()
+	}, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, 1, requestCount(t, c2, pid))
+}
@@ -0,0 +1,185 @@
+package cluster
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
+
+// SplitBrainStrategy decides which side of a network partition keeps running.
+// Every member decides for its own side, so a strategy must come to the same
+// conclusion on all the members of a side, and to opposite conclusions on
+// the two sides.
+type SplitBrainStrategy interface {
+	// Survives returns true if the side of the partition with the reachable
+	// members, which include self, keeps running. The unreachable members
+	// are downed by the surviving side.
+	Survives(self *Member, reachable, unreachable []*Member) bool
+}
+
+type keepMajority struct{}
+
+// KeepMajority returns a strategy that keeps the side with the most members
+// running. When both sides are equally large, the side with the member with
+// the lowest address survives.
+func KeepMajority() SplitBrainStrategy {
+	return keepMajority{}
+}
+
+func (keepMajority) Survives(_ *Member, reachable, unreachable []*Member) bool {
+	if len(reachable) != len(unreachable) {
+		return len(reachable) > len(unreachable)
+	}
+	return lowestHost(reachable) < lowestHost(unreachable)
+}
+
+type keepOldest struct {
+	downIfAlone bool
+}
+
+// KeepOldest returns a strategy that keeps the side with the oldest member
+// running, which keeps the singletons where they are. If downIfAlone is true
+// and the oldest member is the only member on its side, the other side
+// survives instead.
+func KeepOldest(downIfAlone bool) SplitBrainStrategy {
+	return keepOldest{downIfAlone: downIfAlone}
+}
+
+func (s keepOldest) Survives(_ *Member, reachable, unreachable []*Member) bool {
+	oldest := SelectOldestMember(ActivationDetails{
+		Members: append(slices.Clone(reachable), unreachable...),
+	})
+	if slices.ContainsFunc(reachable, oldest.Equals) {
+		return !s.downIfAlone || len(reachable) > 1
+	}
+	return s.downIfAlone && len(unreachable) == 1
+}
+
+type staticQuorum struct {
+	size int
+}
+
+// StaticQuorum returns a strategy that keeps the side running that has at
+// least the given number of members. The size should be more than half of
+// the members of the cluster, when both sides are too small the whole
+// cluster is down.
+func StaticQuorum(size int) SplitBrainStrategy {
+	return staticQuorum{size: size}
+}
+
+func (s staticQuorum) Survives(_ *Member, reachable, _ []*Member) bool {
+	return len(reachable) >= s.size
+}
+
+type keepReferee struct {
+	address           string
+	downAllIfLessThan int
+}
+
+// KeepReferee returns a strategy that keeps the side with the member with
+// the given address running, as long as that side has at least
+// downAllIfLessThan members.
+func KeepReferee(address string, downAllIfLessThan int) SplitBrainStrategy {
+	return keepReferee{
+		address:           address,
+		downAllIfLessThan: downAllIfLessThan,
+	}
+}
+
+func (s keepReferee) Survives(_ *Member, reachable, _ []*Member) bool {
+	hasReferee := slices.ContainsFunc(reachable, func(m *Member) bool {
+		return m.Host == s.address
+	})
+	return hasReferee && len(reachable) >= s.downAllIfLessThan
+}
+
+func lowestHost(members []*Member) string {
+	lowest := ""
+	for _, member := range members {
+		if len(lowest) == 0 || member.Host < lowest {
+			lowest = member.Host
+		}
+	}
+	return lowest
+}
+
+// SplitBrainConfig holds the configuration of the split brain resolver of the
+// SelfManaged provider.
+type SplitBrainConfig struct {
+	strategy    SplitBrainStrategy
+	stableAfter time.Duration
+}
+
+// NewSplitBrainConfig returns a new SplitBrainConfig that resolves partitions
+// with the given strategy.
+func NewSplitBrainConfig(strategy SplitBrainStrategy) SplitBrainConfig {
+	return SplitBrainConfig{
+		strategy:    strategy,
+		stableAfter: 20 * time.Second,
+	}
+}
+
+// WithStableAfter set's how long the set of unreachable members must stay
+// the same before a decision is made. It should be long enough for the
+// failure detector to notice all the members of the other side, and for
+// short network hiccups to heal.
+//
+// Defaults to 20 seconds.
+func (config SplitBrainConfig) WithStableAfter(d time.Duration) SplitBrainConfig {
+	config.stableAfter = d
+	return config
+}
+
+// splitBrainResolver keeps track of how long the reachability of the members
+// has been stable.
+type splitBrainResolver struct {
+	config SplitBrainConfig
+	// the reachability of the members when it last changed.
+	signature string
+	since     time.Time
+}
+
+func newSplitBrainResolver(config SplitBrainConfig) *splitBrainResolver {
+	return &splitBrainResolver{config: config}
+}
+
+// stable returns true if there are unreachable members and the reachability
+// did not change for the stable after period.
+func (r *splitBrainResolver) stable(reachable, unreachable []*Member, now time.Time) bool {
+	if len(unreachable) == 0 {
+		r.signature = ""
+		return false
+	}
+	signature := memberIDs(reachable) + "|" + memberIDs(unreachable)
+	if signature != r.signature {
+		r.signature = signature
+		r.since = now
+	}
+	return now.Sub(r.since) >= r.config.stableAfter
+}
+
+func memberIDs(members []*Member) string {
+	ids := make([]string, len(members))
+	for i, member := range members {
+		ids[i] = member.ID
+	}
+	slices.Sort(ids)
+	return strings.Join(ids, ",")
+}
+
+type down struct{}
+
+// down stops the local activations and the cluster, without telling the
+// other members. It's used by the side of a partition that lost.
+func (c *Cluster) down() {
+	resp, err := c.engine.Request(c.agentPID, down{}, c.config.requestTimeout).Result()
+	if err == nil {
+		pids, _ := resp.([]*actor.PID)
+		for _, pid := range pids {
+			<-c.engine.Poison(pid).Done()
+		}
+	}
+	c.Stop()
+}
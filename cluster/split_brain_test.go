@@ -0,0 +1,283 @@
+package cluster
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/khulnasoft/goactors/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func splitMembers(n int) []*Member {
+	members := make([]*Member, n)
+	for i := range members {
+		members[i] = &Member{
+			ID:      fmt.Sprintf("%c", 'A'+i),
+			Host:    fmt.Sprintf("127.0.0.1:%d", 3000+i),
+			Started: int64(i + 1),
+		}
+	}
+	return members
+}
+
+func TestSplitBrainStrategies(t *testing.T) {
+	m := splitMembers(5)
+	tests := []struct {
+		name        string
+		strategy    SplitBrainStrategy
+		reachable   []*Member
+		unreachable []*Member
+		survives    bool
+	}{
+		{"majority wins", KeepMajority(), m[:3], m[3:], true},
+		{"minority loses", KeepMajority(), m[3:], m[:3], false},
+		{"majority tie lowest address wins", KeepMajority(), m[:2], m[2:4], true},
+		{"majority tie other side loses", KeepMajority(), m[2:4], m[:2], false},
+		{"oldest side wins", KeepOldest(false), m[:1], m[1:], true},
+		{"side without oldest loses", KeepOldest(false), m[1:], m[:1], false},
+		{"oldest alone loses", KeepOldest(true), m[:1], m[1:], false},
+		{"other side of lone oldest wins", KeepOldest(true), m[1:], m[:1], true},
+		{"oldest with others wins", KeepOldest(true), m[:2], m[2:], true},
+		{"quorum reached", StaticQuorum(3), m[2:], m[:2], true},
+		{"quorum not reached", StaticQuorum(3), m[:2], m[2:], false},
+		{"referee side wins", KeepReferee(m[4].Host, 1), m[3:], m[:3], true},
+		{"side without referee loses", KeepReferee(m[4].Host, 1), m[:3], m[3:], false},
+		{"referee side too small", KeepReferee(m[4].Host, 3), m[3:], m[:3], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.survives, tt.strategy.Survives(tt.reachable[0], tt.reachable, tt.unreachable))
+		})
+	}
+}
+
+func TestSplitBrainResolverStable(t *testing.T) {
+	var (
+		m   = splitMembers(3)
+		r   = newSplitBrainResolver(NewSplitBrainConfig(KeepMajority()).WithStableAfter(time.Second))
+		now = time.Now()
+	)
+	assert.False(t, r.stable(m, nil, now))
+	assert.False(t, r.stable(m[:2], m[2:], now))
+	assert.False(t, r.stable(m[:2], m[2:], now.Add(time.Millisecond*500)))
+	// the reachability changed, the period starts over.
+	assert.False(t, r.stable(m[:1], m[1:], now.Add(time.Millisecond*900)))
+	assert.False(t, r.stable(m[:1], m[1:], now.Add(time.Millisecond*1500)))
+	assert.True(t, r.stable(m[:1], m[1:], now.Add(time.Millisecond*1900)))
+}
+
+// partitionRemote is a remote of which the connection to other members can be
+// cut, the messages to them are dropped.
+type partitionRemote struct {
+	*remote.Remote
+	mu  sync.RWMutex
+	cut map[string]bool
+}
+
+func newPartitionRemote(addr string) *partitionRemote {
+	return &partitionRemote{
+		Remote: remote.New(addr, remote.NewConfig()),
+		cut:    make(map[string]bool),
+	}
+}
+
+func (r *partitionRemote) setCut(addresses ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, address := range addresses {
+		r.cut[address] = true
+	}
+}
+
+func (r *partitionRemote) isCut(address string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cut[address]
+}
+
+func (r *partitionRemote) Send(pid *actor.PID, msg any, sender *actor.PID) {
+	if !r.isCut(pid.Address) {
+		r.Remote.Send(pid, msg, sender)
+	}
+}
+
+func (r *partitionRemote) SendEnvelope(pid *actor.PID, env actor.Envelope) {
+	if !r.isCut(pid.Address) {
+		r.Remote.SendEnvelope(pid, env)
+	}
+}
+
+type partitionMember struct {
+	cluster   *Cluster
+	remote    *partitionRemote
+	decisions chan SplitBrainDecisionEvent
+}
+
+// makePartitionMembers starts n members that resolve partitions with the
+// given strategy and waits until they all see each other. The strategy is
+// created with the address of the first member, without a strategy the
+// members only detect failures.
+func makePartitionMembers(t *testing.T, n int, strategy func(first string) SplitBrainStrategy) []*partitionMember {
+	fd := NewFailureDetectorConfig().
+		WithHeartbeatInterval(time.Millisecond * 20).
+		WithAcceptablePause(time.Millisecond * 20).
+		WithMinStdDeviation(time.Millisecond * 10).
+		WithSuspectThreshold(2)
+	var (
+		first   = getRandomLocalhostAddr()
+		members = make([]*partitionMember, n)
+	)
+	for i := range members {
+		addr := first
+		if i > 0 {
+			addr = getRandomLocalhostAddr()
+		}
+		r := newPartitionRemote(addr)
+		e, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(r))
+		require.NoError(t, err)
+		config := NewSelfManagedConfig().
+			WithDiscovery().
+			WithFailureDetector(fd).
+			WithGossipInterval(time.Millisecond * 20)
+		if strategy != nil {
+			sbr := NewSplitBrainConfig(strategy(first)).WithStableAfter(time.Millisecond * 300)
+			config = config.WithSplitBrainResolver(sbr)
+		}
+		if i > 0 {
+			config = config.WithBootstrapMember(MemberAddr{ListenAddr: first, ID: "A"})
+		}
+		c, err := New(NewConfig().
+			WithID(fmt.Sprintf("%c", 'A'+i)).
+			WithEngine(e).
+			WithProvider(NewSelfManagedProvider(config)))
+		require.NoError(t, err)
+		c.RegisterKind("player", NewPlayer, NewKindConfig())
+
+		pm := &partitionMember{
+			cluster:   c,
+			remote:    r,
+			decisions: make(chan SplitBrainDecisionEvent, 8),
+		}
+		eventPID := e.SpawnFunc(func(ctx *actor.Context) {
+			if ev, ok := ctx.Message().(SplitBrainDecisionEvent); ok {
+				pm.decisions <- ev
+			}
+		}, "event")
+		e.Subscribe(eventPID)
+
+		c.Start()
+		t.Cleanup(c.Stop)
+		members[i] = pm
+	}
+	require.Eventually(t, func() bool {
+		for _, m := range members {
+			if len(m.cluster.Members()) != n {
+				return false
+			}
+		}
+		return true
+	}, time.Second*2, time.Millisecond*10)
+	return members
+}
+
+// partition cuts the connections between the two sides.
+func partition(left, right []*partitionMember) {
+	for _, l := range left {
+		for _, r := range right {
+			l.remote.setCut(r.cluster.Address())
+			r.remote.setCut(l.cluster.Address())
+		}
+	}
+}
+
+func awaitDecision(t *testing.T, m *partitionMember) SplitBrainDecisionEvent {
+	select {
+	case ev := <-m.decisions:
+		return ev
+	case <-time.After(time.Second * 3):
+		t.Fatalf("member %s did not decide", m.cluster.ID())
+	}
+	return SplitBrainDecisionEvent{}
+}
+
+// awaitSurvived waits until the surviving side downed the other side. Not
+// every member of the surviving side has to decide, the first one to decide
+// lets the others know about the downed members.
+func awaitSurvived(t *testing.T, side []*partitionMember) {
+	for _, m := range side {
+		require.Eventually(t, func() bool {
+			return len(m.cluster.Members()) == len(side)
+		}, time.Second*3, time.Millisecond*10)
+		for len(m.decisions) > 0 {
+			assert.True(t, (<-m.decisions).Survived)
+		}
+	}
+}
+
+func TestSplitBrainKeepMajority(t *testing.T) {
+	members := makePartitionMembers(t, 5, func(string) SplitBrainStrategy {
+		return KeepMajority()
+	})
+	majority, minority := members[:3], members[3:]
+
+	// Both sides activate the same identity, if they keep running it is duplicated.
+	pid := majority[0].cluster.Activate("player", NewActivationConfig().WithID("1"))
+	require.NotNil(t, pid)
+	partition(majority, minority)
+
+	for _, m := range minority {
+		ev := awaitDecision(t, m)
+		assert.False(t, ev.Survived)
+		assert.Len(t, ev.Reachable, 2)
+		assert.Len(t, ev.Unreachable, 3)
+	}
+	awaitSurvived(t, majority)
+	// The minority downed itself, its agent and its activations are gone.
+	for _, m := range minority {
+		require.Eventually(t, func() bool {
+			return m.cluster.Engine().Registry.GetPID("cluster", m.cluster.ID()) == nil &&
+				m.cluster.Engine().Registry.GetPID("player", "1") == nil
+		}, time.Second, time.Millisecond*10)
+	}
+}
+
+func TestSplitBrainKeepReferee(t *testing.T) {
+	members := makePartitionMembers(t, 5, func(first string) SplitBrainStrategy {
+		return KeepReferee(first, 1)
+	})
+	// The referee is on the smaller side, which survives.
+	minority, majority := members[:2], members[2:]
+	partition(majority, minority)
+
+	for _, m := range majority {
+		assert.False(t, awaitDecision(t, m).Survived)
+	}
+	awaitSurvived(t, minority)
+}
+
+func TestSplitBrainHealsBeforeStable(t *testing.T) {
+	members := makePartitionMembers(t, 3, func(string) SplitBrainStrategy {
+		return StaticQuorum(2)
+	})
+	// A short hiccup of the connection to C, which heals before the
+	// partition is considered stable.
+	members[2].remote.setCut(members[0].cluster.Address(), members[1].cluster.Address())
+	time.Sleep(time.Millisecond * 150)
+	members[2].remote.mu.Lock()
+	clear(members[2].remote.cut)
+	members[2].remote.mu.Unlock()
+
+	time.Sleep(time.Millisecond * 500)
+	for _, m := range members {
+		select {
+		case ev := <-m.decisions:
+			t.Fatalf("unexpected decision on %s: %+v", m.cluster.ID(), ev)
+		default:
+		}
+		assert.Len(t, m.cluster.Members(), 3)
+	}
+}
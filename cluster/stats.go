@@ -0,0 +1,138 @@
+package cluster
+
+import (
+	"maps"
+	"runtime"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
+
+var defaultStatsInterval = time.Second * 5
+
+type (
+	// statsTick triggers the agent to publish the stats of its member.
+	statsTick      struct{}
+	getMemberStats struct{}
+)
+
+// cpuSampler computes the CPU utilization of the process between samples.
+type cpuSampler struct {
+	at  time.Time
+	cpu time.Duration
+}
+
+// sample returns the fraction of the available CPU time the process used
+// since the previous sample.
+func (s *cpuSampler) sample(now time.Time) float64 {
+	cpu := processCPUTime()
+	defer func() {
+		s.at, s.cpu = now, cpu
+	}()
+	if s.at.IsZero() {
+		return 0
+	}
+	available := float64(now.Sub(s.at)) * float64(runtime.GOMAXPROCS(0))
+	if available <= 0 {
+		return 0
+	}
+	return min(float64(cpu-s.cpu)/available, 1)
+}
+
+// MemberStats returns the latest load stats of the members by their ID.
+// Members that did not publish their stats yet are not included.
+//
+//	for id, stats := range c.MemberStats() {
+//		fmt.Println(id, stats.Actors, stats.Mailbox, stats.Cpu)
+//	}
+func (c *Cluster) MemberStats() map[string]*MemberStats {
+	resp, err := c.engine.Request(c.agentPID, getMemberStats{}, c.config.requestTimeout).Result()
+	if err != nil {
+		return map[string]*MemberStats{}
+	}
+	if stats, ok := resp.(map[string]*MemberStats); ok {
+		return stats
+	}
+	return map[string]*MemberStats{}
+}
+
+// Load returns a single number for the load of the member, which is the
+// fraction of CPU it uses plus the average number of messages waiting in the
+// inbox of its actors.
+func (s *MemberStats) Load() float64 {
+	return s.Cpu + float64(s.Mailbox)/float64(max(s.Actors, 1))
+}
+
+// SelectLeastLoaded selects the member with the lowest load according to the
+// stats it published. Members that did not publish their stats yet are only
+// selected when none of the members did.
+func SelectLeastLoaded(details ActivationDetails) *Member {
+	var (
+		selected *Member
+		min      float64
+	)
+	for _, member := range details.Members {
+		stats, ok := details.Stats[member.ID]
+		if !ok {
+			continue
+		}
+		load := stats.Load()
+		if selected == nil || load < min || (load == min && member.ID < selected.ID) {
+			selected = member
+			min = load
+		}
+	}
+	if selected == nil {
+		return SelectLeastActivationsMember(details)
+	}
+	return selected
+}
+
+// localStats collects the stats of our member.
+func (a *Agent) localStats() *MemberStats {
+	var (
+		now         = time.Now()
+		engine      = a.cluster.engine.Stats()
+		activations = make(map[string]int64)
+	)
+	for _, pid := range a.localActivations() {
+		activations[actor.KindOf(pid)]++
+	}
+	return &MemberStats{
+		MemberID:    a.cluster.config.id,
+		Actors:      int64(engine.Actors),
+		Mailbox:     int64(engine.Mailbox),
+		Cpu:         a.cpu.sample(now),
+		Goroutines:  int64(runtime.NumGoroutine()),
+		Activations: activations,
+		Timestamp:   now.UnixNano(),
+	}
+}
+
+// publishStats sends the stats of our member to all the other members.
+func (a *Agent) publishStats() {
+	stats := a.localStats()
+	a.stats[stats.MemberID] = stats
+	a.members.ForEach(func(member *Member) bool {
+		if member.ID != stats.MemberID {
+			a.cluster.engine.Send(member.PID(), stats)
+		}
+		return true
+	})
+}
+
+// handleMemberStats keeps the stats of the members that are part of our view
+// of the cluster.
+func (a *Agent) handleMemberStats(stats *MemberStats) {
+	if a.members.GetByID(stats.MemberID) == nil {
+		return
+	}
+	if known, ok := a.stats[stats.MemberID]; ok && known.Timestamp > stats.Timestamp {
+		return
+	}
+	a.stats[stats.MemberID] = stats
+}
+
+func (a *Agent) memberStats() map[string]*MemberStats {
+	return maps.Clone(a.stats)
+}
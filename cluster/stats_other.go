@@ -0,0 +1,11 @@
+//go:build !unix
+
+package cluster
+
+import "time"
+
+// processCPUTime is not supported on this platform, the CPU usage of the
+// member is reported as 0.
+func processCPUTime() time.Duration {
+	return 0
+}
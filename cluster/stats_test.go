@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPUSampler(t *testing.T) {
+	var (
+		s   cpuSampler
+		now = time.Now()
+	)
+	assert.Equal(t, 0.0, s.sample(now))
+	// burn some CPU.
+	x := 0
+	for time.Since(now) < time.Millisecond*50 {
+		x++
+	}
+	cpu := s.sample(time.Now())
+	assert.Greater(t, cpu, 0.0)
+	assert.LessOrEqual(t, cpu, 1.0)
+}
+
+func TestSelectLeastLoaded(t *testing.T) {
+	members := []*Member{{ID: "A"}, {ID: "B"}, {ID: "C"}}
+	details := ActivationDetails{
+		Members: members,
+		Stats: map[string]*MemberStats{
+			"A": {Actors: 10, Mailbox: 100, Cpu: 0.1},
+			"B": {Actors: 10, Mailbox: 0, Cpu: 0.5},
+		},
+	}
+	assert.Equal(t, "B", SelectLeastLoaded(details).ID)
+
+	details.Stats["A"].Mailbox = 0
+	assert.Equal(t, "A", SelectLeastLoaded(details).ID)
+
+	// without stats, the member with the least activations is selected.
+	details.Stats = nil
+	details.Activations = map[string]int{"A": 1, "B": 1}
+	assert.Equal(t, "C", SelectLeastLoaded(details).ID)
+}
+
+func TestMemberStats(t *testing.T) {
+	c1, err := New(NewConfig().
+		WithID("A").
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithStatsInterval(time.Millisecond * 20))
+	require.NoError(t, err)
+	c1.RegisterKind("player", NewPlayer, NewKindConfig())
+	c1.Start()
+	defer c1.Stop()
+
+	c2, err := New(NewConfig().
+		WithID("B").
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithStatsInterval(time.Millisecond * 20).
+		WithProvider(NewSelfManagedProvider(NewSelfManagedConfig().
+			WithBootstrapMember(MemberAddr{ListenAddr: c1.Address(), ID: "A"}))))
+	require.NoError(t, err)
+	c2.RegisterKind("player", NewPlayer, NewKindConfig())
+	c2.Start()
+
+	require.Eventually(t, func() bool {
+		return len(c1.Members()) == 2 && len(c2.Members()) == 2
+	}, time.Second*2, time.Millisecond*10)
+
+	// Build up a backlog on A, so new activations are placed on B.
+	block := make(chan struct{})
+	defer close(block)
+	busy := c1.Engine().SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(string); ok {
+			<-block
+		}
+	}, "busy")
+	for range 1000 {
+		c1.Engine().Send(busy, "work")
+	}
+	require.Eventually(t, func() bool {
+		stats := c2.MemberStats()
+		return len(stats) == 2 && stats["A"].Mailbox >= 999
+	}, time.Second, time.Millisecond*10)
+
+	for i := range 3 {
+		pid := c2.Activate("player", NewActivationConfig().
+			WithID(fmt.Sprint(i)).
+			WithSelectMemberFunc(SelectLeastLoaded))
+		require.NotNil(t, pid)
+		assert.Equal(t, c2.Address(), pid.Address)
+	}
+	require.Eventually(t, func() bool {
+		stats, ok := c1.MemberStats()["B"]
+		return ok && stats.Activations["player"] == 3 && stats.Goroutines > 0 && stats.Actors > 3
+	}, time.Second, time.Millisecond*10)
+
+	// The stats of members that left are dropped.
+	require.NoError(t, c2.Leave(context.Background()))
+	require.Eventually(t, func() bool {
+		_, ok := c1.MemberStats()["B"]
+		return !ok
+	}, time.Second, time.Millisecond*10)
+}
@@ -44,3 +44,13 @@ func (m *Member) HasKind(kind string) bool {
 	}
 	return false
 }
+
+// HasLabels returns true whether the Member has all the given labels.
+func (m *Member) HasLabels(labels map[string]string) bool {
+	for key, value := range labels {
+		if v, ok := m.Labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,222 @@
+// Command goactors inspects and administers running engines and clusters. It
+// connects to a node as a remote peer and talks to the admin actor the node
+// serves when it opted in, see cluster.AdminPID.
+//
+//	goactors -addr 127.0.0.1:3000 members
+//	goactors -addr 127.0.0.1:3000 actors player
+//	goactors -addr 127.0.0.1:3000 inspect 127.0.0.1:3000/player/1
+//	goactors -addr 127.0.0.1:3000 send 127.0.0.1:3000/player/1 game.Move '{"x": 1}'
+//	goactors -addr 127.0.0.1:3000 events
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/khulnasoft/goactors/cluster"
+	"github.com/khulnasoft/goactors/remote"
+)
+
+const usage = `usage: goactors [flags] <command> [args]
+
+commands:
+  members                      list the members of the cluster and their kinds
+  actors [kind]                list the activated actors, of all kinds if none is given
+  inspect <pid>                show the mailbox depth and restart count of an actor
+  send <pid> <type> [json]     send a registered proto message to an actor
+  events                       tail the event stream of the node
+
+flags:
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "goactors:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// run runs the command in args and writes its output to stdout. The events
+// command runs until the context is canceled.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("goactors", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	var (
+		addr    = flags.String("addr", "127.0.0.1:3000", "the listen address of the node to connect to")
+		listen  = flags.String("listen", "", "the address to listen on for responses, a free port if not specified")
+		timeout = flags.Duration("timeout", time.Second*5, "the maximum duration to wait for a response")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+	if len(*listen) == 0 {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return err
+		}
+		*listen = l.Addr().String()
+		l.Close()
+	}
+	r := remote.New(*listen, remote.NewConfig())
+	e, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(r))
+	if err != nil {
+		return err
+	}
+	defer func() { r.Stop().Wait() }()
+
+	a := &admin{engine: e, pid: cluster.AdminPID(*addr), timeout: *timeout, out: stdout}
+	cmd, cmdArgs := flags.Arg(0), flags.Args()[1:]
+	switch {
+	case cmd == "members" && len(cmdArgs) == 0:
+		return a.members()
+	case cmd == "actors" && len(cmdArgs) <= 1:
+		return a.actors(strings.Join(cmdArgs, ""))
+	case cmd == "inspect" && len(cmdArgs) == 1:
+		return a.inspect(cmdArgs[0])
+	case cmd == "send" && (len(cmdArgs) == 2 || len(cmdArgs) == 3):
+		return a.send(cmdArgs[0], cmdArgs[1], strings.Join(cmdArgs[2:], ""))
+	case cmd == "events" && len(cmdArgs) == 0:
+		return a.events(ctx)
+	}
+	flags.Usage()
+	return fmt.Errorf("invalid command: %s", strings.Join(flags.Args(), " "))
+}
+
+// admin sends the requests of the admin protocol to the admin actor of a
+// node.
+type admin struct {
+	engine  *actor.Engine
+	pid     *actor.PID
+	timeout time.Duration
+	out     io.Writer
+}
+
+func (a *admin) request(msg any) (any, error) {
+	resp, err := a.engine.Request(a.pid, msg, a.timeout).Result()
+	if err != nil {
+		return nil, fmt.Errorf("no response from %s: %w", a.pid.Address, err)
+	}
+	return resp, nil
+}
+
+func (a *admin) members() error {
+	resp, err := a.request(&cluster.AdminMembersRequest{})
+	if err != nil {
+		return err
+	}
+	members := resp.(*cluster.AdminMembersResponse)
+	if len(members.Error) > 0 {
+		return errors.New(members.Error)
+	}
+	w := tabwriter.NewWriter(a.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tHOST\tREGION\tKINDS")
+	for _, m := range members.Members {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.ID, m.Host, m.Region, strings.Join(m.Kinds, ","))
+	}
+	return w.Flush()
+}
+
+func (a *admin) actors(kind string) error {
+	resp, err := a.request(&cluster.AdminActivationsRequest{Kind: kind})
+	if err != nil {
+		return err
+	}
+	actives := resp.(*cluster.AdminActivationsResponse)
+	if len(actives.Error) > 0 {
+		return errors.New(actives.Error)
+	}
+	for _, pid := range actives.Pids {
+		fmt.Fprintln(a.out, pid)
+	}
+	return nil
+}
+
+func (a *admin) inspect(s string) error {
+	pid, err := parsePID(s)
+	if err != nil {
+		return err
+	}
+	// Only the engine that hosts the actor knows its state.
+	a.pid = cluster.AdminPID(pid.Address)
+	resp, err := a.request(&cluster.AdminActorRequest{Pid: pid})
+	if err != nil {
+		return err
+	}
+	info := resp.(*cluster.AdminActorResponse)
+	if !info.Found {
+		return fmt.Errorf("actor not found: %s", pid)
+	}
+	fmt.Fprintf(a.out, "pid:       %s\nmailbox:   %d\nrestarts:  %d\n", pid, info.Mailbox, info.Restarts)
+	return nil
+}
+
+func (a *admin) send(s, typeName, payload string) error {
+	pid, err := parsePID(s)
+	if err != nil {
+		return err
+	}
+	resp, err := a.request(&cluster.AdminSendRequest{Pid: pid, Type: typeName, Payload: payload})
+	if err != nil {
+		return err
+	}
+	if sent := resp.(*cluster.AdminSendResponse); len(sent.Error) > 0 {
+		return errors.New(sent.Error)
+	}
+	return nil
+}
+
+// events prints the events the admin actor forwards until the context is
+// canceled.
+func (a *admin) events(ctx context.Context) error {
+	events := make(chan *cluster.AdminEvent, 1024)
+	subscriber := a.engine.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*cluster.AdminEvent); ok {
+			events <- msg
+		}
+	}, "events")
+	a.engine.Send(a.pid, &cluster.AdminSubscribe{Subscriber: subscriber})
+	// Wait for the unsubscribe to be processed, the remote is stopped right
+	// after we return.
+	defer func() { _, _ = a.request(&cluster.AdminUnsubscribe{Subscriber: subscriber}) }()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			ts := time.Unix(0, event.Timestamp).Format(time.RFC3339Nano)
+			fmt.Fprintf(a.out, "%s %s %s\n", ts, event.Type, event.Event)
+		}
+	}
+}
+
+// parsePID parses a PID in the format of PID.String, the address and ID
+// separated by the first slash.
+func parsePID(s string) (*actor.PID, error) {
+	addr, id, ok := strings.Cut(s, "/")
+	if !ok || len(addr) == 0 || len(id) == 0 {
+		return nil, fmt.Errorf("invalid pid %q, expected <address>/<id>", s)
+	}
+	return actor.NewPID(addr, id), nil
+}
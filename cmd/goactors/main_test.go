@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/khulnasoft/goactors/cluster"
+	"github.com/khulnasoft/goactors/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getRandomLocalhostAddr() string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// player forwards the data of the messages it receives.
+type player struct {
+	received chan<- string
+}
+
+func (p player) Receive(c *actor.Context) {
+	if msg, ok := c.Message().(*remote.TestMessage); ok {
+		p.received <- string(msg.Data)
+	}
+}
+
+func runCommand(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := run(ctx, args, &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestRun(t *testing.T) {
+	received := make(chan string, 1)
+	addr := getRandomLocalhostAddr()
+	c, err := cluster.New(cluster.NewConfig().
+		WithID("A").
+		WithListenAddr(addr).
+		WithAdmin(cluster.NewAdminConfig().WithSend(true)))
+	require.NoError(t, err)
+	c.RegisterKind("player", func() actor.Receiver {
+		return player{received: received}
+	}, cluster.NewKindConfig())
+	c.Start()
+	defer c.Stop()
+	pid := c.Activate("player", cluster.NewActivationConfig().WithID("1"))
+	require.NotNil(t, pid)
+
+	ctx := context.Background()
+	out, err := runCommand(ctx, "-addr", addr, "members")
+	require.NoError(t, err)
+	assert.Contains(t, out, "A ")
+	assert.Contains(t, out, "player")
+
+	out, err = runCommand(ctx, "-addr", addr, "actors", "player")
+	require.NoError(t, err)
+	assert.Equal(t, pid.String()+"\n", out)
+
+	out, err = runCommand(ctx, "inspect", pid.String())
+	require.NoError(t, err)
+	assert.Contains(t, out, "restarts:  0")
+
+	_, err = runCommand(ctx, "-addr", addr, "send", pid.String(), "remote.TestMessage", `{"data": "aGk="}`)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", <-received)
+
+	_, err = runCommand(ctx, "-addr", addr, "send", pid.String(), "unknown.Type")
+	assert.ErrorContains(t, err, "unknown.Type")
+	_, err = runCommand(ctx, "inspect", "nopid")
+	assert.ErrorContains(t, err, "invalid pid")
+	_, err = runCommand(ctx, "-addr", addr, "unknown")
+	assert.ErrorContains(t, err, "invalid command")
+
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond*300)
+	defer cancel()
+	go func() {
+		// Give the subscription some time, then trigger an event.
+		time.Sleep(time.Millisecond * 100)
+		c.Activate("player", cluster.NewActivationConfig().WithID("2"))
+	}()
+	out, err = runCommand(ctx, "-addr", addr, "events")
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(out, "actor.ActorStartedEvent"), out)
+}
@@ -15,7 +15,9 @@ func main() {
 	config := cluster.NewConfig().
 		WithID("B").
 		WithListenAddr("127.0.0.1:3001").
-		WithRegion("us-west")
+		WithRegion("us-west").
+		WithProvider(cluster.NewSelfManagedProvider(cluster.NewSelfManagedConfig().
+			WithBootstrapMember(cluster.MemberAddr{ListenAddr: "127.0.0.1:3000", ID: "A"})))
 	c, err := cluster.New(config)
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1 @@
+protoc --go_out=. --go-vtproto_out=. --go_opt=paths=source_relative --proto_path=. gateway.proto
\ No newline at end of file
@@ -0,0 +1,254 @@
+// Package gateway lets external services send messages to the actors in a
+// cluster over HTTP with JSON, or over gRPC.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/khulnasoft/goactors/cluster"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const defaultRequestTimeout = time.Second * 5
+
+// maxBodySize is the maximum size of the body of an HTTP request.
+const maxBodySize = 1 << 20
+
+// Config holds the configuration of the gateway.
+type Config struct {
+	requestTimeout time.Duration
+	types          *protoregistry.Types
+}
+
+// NewConfig returns a Config that is initialized with default values.
+func NewConfig() Config {
+	return Config{
+		requestTimeout: defaultRequestTimeout,
+		types:          protoregistry.GlobalTypes,
+	}
+}
+
+// WithRequestTimeout set's the maximum duration the gateway waits for the
+// response of an actor. A shorter deadline of the incoming request takes
+// precedence.
+//
+// Defaults to 5 seconds.
+func (config Config) WithRequestTimeout(d time.Duration) Config {
+	config.requestTimeout = d
+	return config
+}
+
+// WithTypes set's the registry the message types are resolved in.
+//
+// Defaults to protoregistry.GlobalTypes, which holds all the proto types
+// that are linked into the binary.
+func (config Config) WithTypes(types *protoregistry.Types) Config {
+	config.types = types
+	return config
+}
+
+// Gateway delivers the messages of external services to the actors in the
+// cluster. The target actor is addressed by its kind and ID, it's activated
+// when it's not active yet.
+//
+// Over HTTP it accepts a JSON object with the kind and ID of the actor, the
+// full name of the proto type of the message and the message in the proto
+// JSON format:
+//
+//	POST /send     {"kind": "player", "id": "1", "type": "game.Move", "payload": {"x": 1}}
+//	POST /request  {"kind": "player", "id": "1", "type": "game.GetPosition", "payload": {}}
+//
+// A send responds with 202 Accepted, a request with the response of the actor
+// in the same format, without the kind and ID. Over gRPC it implements the
+// Gateway service, see RegisterGatewayServer.
+//
+//	gw := gateway.New(c, gateway.NewConfig())
+//	http.ListenAndServe(":8080", gw)
+type Gateway struct {
+	UnimplementedGatewayServer
+
+	cluster *cluster.Cluster
+	config  Config
+	mux     *http.ServeMux
+}
+
+// New returns a new Gateway into the given cluster.
+func New(c *cluster.Cluster, config Config) *Gateway {
+	g := &Gateway{
+		cluster: c,
+		config:  config,
+		mux:     http.NewServeMux(),
+	}
+	g.mux.HandleFunc("POST /send", g.handleHTTP(false))
+	g.mux.HandleFunc("POST /request", g.handleHTTP(true))
+	return g
+}
+
+// ServeHTTP implements http.Handler.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// Send implements GatewayServer.
+func (g *Gateway) Send(ctx context.Context, msg *Message) (*SendResponse, error) {
+	_, err := g.deliver(ctx, msg.Kind, msg.ID, msg.Type, false, func(m proto.Message) error {
+		return proto.Unmarshal(msg.Payload, m)
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &SendResponse{}, nil
+}
+
+// Request implements GatewayServer.
+func (g *Gateway) Request(ctx context.Context, msg *Message) (*Message, error) {
+	resp, err := g.deliver(ctx, msg.Kind, msg.ID, msg.Type, true, func(m proto.Message) error {
+		return proto.Unmarshal(msg.Payload, m)
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	payload, err := proto.Marshal(resp)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &Message{Type: string(proto.MessageName(resp)), Payload: payload}, nil
+}
+
+// httpMessage is the JSON form of Message.
+type httpMessage struct {
+	Kind    string          `json:"kind,omitempty"`
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type httpError struct {
+	Error string `json:"error"`
+}
+
+func (g *Gateway) handleHTTP(request bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var msg httpMessage
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxBodySize)).Decode(&msg); err != nil {
+			writeJSON(w, http.StatusBadRequest, httpError{Error: fmt.Sprintf("invalid body: %s", err)})
+			return
+		}
+		resp, err := g.deliver(r.Context(), msg.Kind, msg.ID, msg.Type, request, func(m proto.Message) error {
+			if len(msg.Payload) == 0 {
+				return nil
+			}
+			return protojson.UnmarshalOptions{Resolver: g.config.types}.Unmarshal(msg.Payload, m)
+		})
+		if err != nil {
+			writeJSON(w, httpStatus(err), httpError{Error: err.Error()})
+			return
+		}
+		if !request {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		payload, err := protojson.MarshalOptions{Resolver: g.config.types}.Marshal(resp)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, httpError{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, httpMessage{Type: string(proto.MessageName(resp)), Payload: payload})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+var (
+	errInvalidMessage = errors.New("invalid message")
+	errUnknownKind    = errors.New("unknown kind")
+	errUnavailable    = errors.New("actor unavailable")
+	errTimeout        = errors.New("request timed out")
+	errInvalidReply   = errors.New("invalid response")
+)
+
+// deliver decodes the message with the given type name and sends it to the
+// actor of the given kind and ID. If request is true, the response of the
+// actor is returned.
+func (g *Gateway) deliver(ctx context.Context, kind, id, typeName string, request bool, decode func(proto.Message) error) (proto.Message, error) {
+	if len(kind) == 0 || len(id) == 0 {
+		return nil, fmt.Errorf("%w: kind and id are required", errInvalidMessage)
+	}
+	mt, err := g.config.types.FindMessageByName(protoreflect.FullName(typeName))
+	if err != nil {
+		return nil, fmt.Errorf("%w: type %q: %s", errInvalidMessage, typeName, err)
+	}
+	msg := mt.New().Interface()
+	if err := decode(msg); err != nil {
+		return nil, fmt.Errorf("%w: payload: %s", errInvalidMessage, err)
+	}
+	if !g.cluster.HasKind(kind) {
+		return nil, fmt.Errorf("%w: %s", errUnknownKind, kind)
+	}
+	pid := g.cluster.Get(kind, id)
+	if pid == nil {
+		return nil, fmt.Errorf("%w: %s/%s", errUnavailable, kind, id)
+	}
+	if !request {
+		g.cluster.Engine().Send(pid, msg)
+		return nil, nil
+	}
+
+	timeout := g.config.requestTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline))
+	}
+	resp, err := g.cluster.Engine().Request(pid, msg, timeout).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s/%s: %s", errTimeout, kind, id, err)
+	}
+	reply, ok := resp.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a proto message", errInvalidReply, resp)
+	}
+	return reply, nil
+}
+
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, errInvalidMessage):
+		return http.StatusBadRequest
+	case errors.Is(err, errUnknownKind):
+		return http.StatusNotFound
+	case errors.Is(err, errUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, errTimeout):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+func grpcError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, errInvalidMessage):
+		code = codes.InvalidArgument
+	case errors.Is(err, errUnknownKind):
+		code = codes.NotFound
+	case errors.Is(err, errUnavailable):
+		code = codes.Unavailable
+	case errors.Is(err, errTimeout):
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Error())
+}
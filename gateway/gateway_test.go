@@ -0,0 +1,163 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/khulnasoft/goactors/cluster"
+	"github.com/khulnasoft/goactors/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// echo responds with the data of the messages it receives, prefixed with its
+// ID, and forwards them to the received channel.
+type echo struct {
+	received chan<- string
+}
+
+func (e echo) Receive(c *actor.Context) {
+	if msg, ok := c.Message().(*remote.TestMessage); ok {
+		data := fmt.Sprintf("%s:%s", c.PID().ID, msg.Data)
+		if e.received != nil {
+			e.received <- data
+		}
+		if c.Sender() != nil {
+			c.Respond(&remote.TestMessage{Data: []byte(data)})
+		}
+	}
+}
+
+func makeGateway(t *testing.T) (*Gateway, <-chan string) {
+	received := make(chan string, 10)
+	c, err := cluster.New(cluster.NewConfig().
+		WithID("A").
+		WithListenAddr(getRandomLocalhostAddr()))
+	require.NoError(t, err)
+	c.RegisterKind("echo", func() actor.Receiver { return echo{received: received} }, cluster.NewKindConfig())
+	c.Start()
+	t.Cleanup(c.Stop)
+	require.Eventually(t, func() bool {
+		return c.HasKind("echo")
+	}, time.Second, time.Millisecond*10)
+	return New(c, NewConfig().WithRequestTimeout(time.Millisecond*500)), received
+}
+
+func getRandomLocalhostAddr() string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func post(t *testing.T, url string, body any) (int, map[string]any) {
+	b, err := json.Marshal(body)
+	require.NoError(t, err)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(b))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var result map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+func TestHTTPGateway(t *testing.T) {
+	gw, received := makeGateway(t)
+	srv := httptest.NewServer(gw)
+	defer srv.Close()
+
+	// bytes are base64 encoded in the proto JSON format.
+	msg := map[string]any{
+		"kind":    "echo",
+		"id":      "1",
+		"type":    "remote.TestMessage",
+		"payload": map[string]any{"data": "aGk="},
+	}
+	code, _ := post(t, srv.URL+"/send", msg)
+	assert.Equal(t, http.StatusAccepted, code)
+	assert.Equal(t, "echo/1:hi", <-received)
+
+	code, resp := post(t, srv.URL+"/request", msg)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "echo/1:hi", <-received)
+	assert.Equal(t, "remote.TestMessage", resp["type"])
+	assert.Equal(t, map[string]any{"data": "ZWNoby8xOmhp"}, resp["payload"])
+
+	for _, tc := range []struct {
+		body any
+		code int
+	}{
+		{"not an object", http.StatusBadRequest},
+		{map[string]any{"kind": "echo", "id": "1", "type": "unknown.Type"}, http.StatusBadRequest},
+		{map[string]any{"kind": "echo", "id": "1", "type": "remote.TestMessage", "payload": map[string]any{"x": 1}}, http.StatusBadRequest},
+		{map[string]any{"kind": "echo", "type": "remote.TestMessage"}, http.StatusBadRequest},
+		{map[string]any{"kind": "unknown", "id": "1", "type": "remote.TestMessage"}, http.StatusNotFound},
+	} {
+		code, resp := post(t, srv.URL+"/request", tc.body)
+		assert.Equal(t, tc.code, code, tc.body)
+		assert.NotEmpty(t, resp["error"])
+	}
+
+	resp2, err := http.Get(srv.URL + "/send")
+	require.NoError(t, err)
+	resp2.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp2.StatusCode)
+}
+
+func TestGRPCGateway(t *testing.T) {
+	gw, received := makeGateway(t)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	RegisterGatewayServer(srv, gw)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := NewGatewayClient(conn)
+
+	payload, err := proto.Marshal(&remote.TestMessage{Data: []byte("hi")})
+	require.NoError(t, err)
+	msg := &Message{Kind: "echo", ID: "2", Type: "remote.TestMessage", Payload: payload}
+	_, err = client.Send(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Equal(t, "echo/2:hi", <-received)
+
+	resp, err := client.Request(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Equal(t, "echo/2:hi", <-received)
+	assert.Equal(t, "remote.TestMessage", resp.Type)
+	var reply remote.TestMessage
+	require.NoError(t, proto.Unmarshal(resp.Payload, &reply))
+	assert.Equal(t, "echo/2:hi", string(reply.Data))
+
+	_, err = client.Request(context.Background(), &Message{Kind: "unknown", ID: "1", Type: "remote.TestMessage"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.Request(context.Background(), &Message{Kind: "echo", ID: "1", Type: "unknown.Type"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGatewayRequestTimeout(t *testing.T) {
+	gw, _ := makeGateway(t)
+	// The actor ignores messages of other types, it never responds.
+	_, err := gw.deliver(context.Background(), "echo", "3", "remote.Envelope", true, func(proto.Message) error { return nil })
+	assert.ErrorIs(t, err, errTimeout)
+	assert.Equal(t, http.StatusGatewayTimeout, httpStatus(err))
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "goactors"
+
+// Prometheus is an actor.Metrics implementation that exposes the engine
+// instrumentation as Prometheus collectors.
+//
+//	m, err := metrics.NewPrometheus(prometheus.DefaultRegisterer)
+//	e, err := actor.NewEngine(actor.NewEngineConfig().WithMetrics(m))
+type Prometheus struct {
+	messagesSent      *prometheus.CounterVec
+	messagesProcessed *prometheus.CounterVec
+	processingLatency *prometheus.HistogramVec
+	mailboxDepth      *prometheus.HistogramVec
+	activeActors      *prometheus.GaugeVec
+	restarts          *prometheus.CounterVec
+	deadLetters       *prometheus.CounterVec
+	remoteBatchesSent *prometheus.CounterVec
+	remoteMsgsSent    *prometheus.CounterVec
+	remoteBytesSent   *prometheus.CounterVec
+	remoteBatchesRecv prometheus.Counter
+	remoteMsgsRecv    prometheus.Counter
+	remoteBytesRecv   prometheus.Counter
+}
+
+// NewPrometheus creates the collectors and registers them with the given
+// Registerer.
+func NewPrometheus(reg prometheus.Registerer) (*Prometheus, error) {
+	p := &Prometheus{
+		messagesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_sent_total",
+			Help:      "Number of messages sent, by the kind of the receiver.",
+		}, []string{"kind"}),
+		messagesProcessed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_processed_total",
+			Help:      "Number of messages processed, by kind.",
+		}, []string{"kind"}),
+		processingLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "message_processing_seconds",
+			Help:      "Time it took to process a message, by kind.",
+			Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}, []string{"kind"}),
+		mailboxDepth: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "mailbox_depth",
+			Help:      "Number of messages waiting in the mailbox when a batch is picked up, by kind.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+		}, []string{"kind"}),
+		activeActors: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "actors_active",
+			Help:      "Number of registered actors, by kind.",
+		}, []string{"kind"}),
+		restarts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "actor_restarts_total",
+			Help:      "Number of actor restarts, by kind.",
+		}, []string{"kind"}),
+		deadLetters: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dead_letters_total",
+			Help:      "Number of messages that could not be delivered, by the kind of the receiver.",
+		}, []string{"kind"}),
+		remoteBatchesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "remote",
+			Name:      "batches_sent_total",
+			Help:      "Number of batches written to a peer.",
+		}, []string{"peer"}),
+		remoteMsgsSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "remote",
+			Name:      "messages_sent_total",
+			Help:      "Number of messages written to a peer.",
+		}, []string{"peer"}),
+		remoteBytesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "remote",
+			Name:      "bytes_sent_total",
+			Help:      "Number of bytes written to a peer.",
+		}, []string{"peer"}),
+		remoteBatchesRecv: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "remote",
+			Name:      "batches_received_total",
+			Help:      "Number of batches read from the network.",
+		}),
+		remoteMsgsRecv: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "remote",
+			Name:      "messages_received_total",
+			Help:      "Number of messages read from the network.",
+		}),
+		remoteBytesRecv: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "remote",
+			Name:      "bytes_received_total",
+			Help:      "Number of bytes read from the network.",
+		}),
+	}
+	collectors := []prometheus.Collector{
+		p.messagesSent,
+		p.messagesProcessed,
+		p.processingLatency,
+		p.mailboxDepth,
+		p.activeActors,
+		p.restarts,
+		p.deadLetters,
+		p.remoteBatchesSent,
+		p.remoteMsgsSent,
+		p.remoteBytesSent,
+		p.remoteBatchesRecv,
+		p.remoteMsgsRecv,
+		p.remoteBytesRecv,
+	}
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+var _ actor.Metrics = &Prometheus{}
+
+func (p *Prometheus) MessageSent(kind string) {
+	p.messagesSent.WithLabelValues(kind).Inc()
+}
+
+func (p *Prometheus) MessageProcessed(kind string, latency time.Duration) {
+	p.messagesProcessed.WithLabelValues(kind).Inc()
+	p.processingLatency.WithLabelValues(kind).Observe(latency.Seconds())
+}
+
+func (p *Prometheus) MailboxDepth(kind string, depth int) {
+	p.mailboxDepth.WithLabelValues(kind).Observe(float64(depth))
+}
+
+func (p *Prometheus) ActorSpawned(kind string) {
+	p.activeActors.WithLabelValues(kind).Inc()
+}
+
+func (p *Prometheus) ActorStopped(kind string) {
+	p.activeActors.WithLabelValues(kind).Dec()
+}
+
+func (p *Prometheus) ActorRestarted(kind string) {
+	p.restarts.WithLabelValues(kind).Inc()
+}
+
+func (p *Prometheus) DeadLetter(kind string) {
+	p.deadLetters.WithLabelValues(kind).Inc()
+}
+
+func (p *Prometheus) RemoteBatchSent(peer string, messages, bytes int) {
+	p.remoteBatchesSent.WithLabelValues(peer).Inc()
+	p.remoteMsgsSent.WithLabelValues(peer).Add(float64(messages))
+	p.remoteBytesSent.WithLabelValues(peer).Add(float64(bytes))
+}
+
+func (p *Prometheus) RemoteBatchReceived(messages, bytes int) {
+	p.remoteBatchesRecv.Inc()
+	p.remoteMsgsRecv.Add(float64(messages))
+	p.remoteBytesRecv.Add(float64(bytes))
+}
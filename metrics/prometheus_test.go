@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusEngineMetrics(t *testing.T) {
+	m, err := NewPrometheus(prometheus.NewRegistry())
+	require.NoError(t, err)
+	e, err := actor.NewEngine(actor.NewEngineConfig().WithMetrics(m))
+	require.NoError(t, err)
+
+	pid := e.SpawnFunc(func(c *actor.Context) {
+		switch c.Message().(type) {
+		case string:
+			c.Respond("pong")
+		case int:
+			panic("boom")
+		}
+	}, "ping", actor.WithRestartDelay(time.Millisecond))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.activeActors.WithLabelValues("ping")))
+
+	for i := 0; i < 3; i++ {
+		_, err := e.Request(pid, "ping", time.Second).Result()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.messagesSent.WithLabelValues("ping")))
+	// Initialized and Started are not counted, they don't go through the inbox.
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(m.messagesProcessed.WithLabelValues("ping")) == 3
+	}, time.Second, time.Millisecond*10)
+
+	e.Send(pid, 1)
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(m.restarts.WithLabelValues("ping")) == 1
+	}, time.Second, time.Millisecond*10)
+
+	e.Send(actor.NewPID(e.Address(), "missing/1"), "foo")
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.deadLetters.WithLabelValues("missing")))
+
+	<-e.Poison(pid).Done()
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.activeActors.WithLabelValues("ping")))
+}
+
+func TestPrometheusRegisterTwice(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, err := NewPrometheus(reg)
+	require.NoError(t, err)
+	_, err = NewPrometheus(reg)
+	assert.Error(t, err)
+}
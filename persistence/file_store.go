@@ -0,0 +1,116 @@
+package persistence
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const revisionHeaderSize = 8
+
+// FileStore is an embedded StateStore that keeps every key in its own file
+// inside a single directory. A record is the big endian revision followed by
+// the raw state. Writes go to a temporary file that is synced and renamed over
+// the previous record, so a crash never leaves a half written state behind.
+//
+// Compare-and-swap is guarded by a mutex, hence a directory should only be
+// owned by one FileStore at a time.
+type FileStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileStore returns a FileStore that persists its records in dir. The
+// directory is created if it does not exist yet.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return &FileStore{
+		dir: dir,
+	}, nil
+}
+
+func (s *FileStore) Load(key string) ([]byte, uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read(key)
+}
+
+func (s *FileStore) Save(key string, data []byte, expected uint64) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, rev, err := s.read(key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return 0, err
+	}
+	if rev != expected {
+		return rev, ErrRevisionConflict
+	}
+	rev = expected + 1
+	buf := make([]byte, revisionHeaderSize+len(data))
+	binary.BigEndian.PutUint64(buf, rev)
+	copy(buf[revisionHeaderSize:], data)
+	if err := s.write(key, buf); err != nil {
+		return expected, err
+	}
+	return rev, nil
+}
+
+func (s *FileStore) Delete(key string, expected uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, rev, err := s.read(key)
+	if err != nil {
+		return err
+	}
+	if rev != expected {
+		return ErrRevisionConflict
+	}
+	return os.Remove(s.path(key))
+}
+
+func (s *FileStore) read(key string) ([]byte, uint64, error) {
+	b, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(b) < revisionHeaderSize {
+		return nil, 0, fmt.Errorf("corrupted state record for key %q", key)
+	}
+	return b[revisionHeaderSize:], binary.BigEndian.Uint64(b), nil
+}
+
+func (s *FileStore) write(key string, b []byte) error {
+	f, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path(key))
+}
+
+// path returns the file of the given key. Keys are hex encoded, because PIDs
+// contain separators that are not allowed in file names.
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.dir, hex.EncodeToString([]byte(key)))
+}
@@ -0,0 +1,204 @@
+package persistence
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+)
+
+// Stateful is implemented by receivers whose state should be made durable
+// by the WithDurableState middleware.
+type Stateful interface {
+	// State returns the serialized state of the receiver.
+	State() ([]byte, error)
+	// LoadState restores the receiver from a previously saved state.
+	LoadState([]byte) error
+}
+
+// KeyFunc returns the key under which the state of the given process is stored.
+type KeyFunc func(pid *actor.PID) string
+
+// KeyByID stores the state under the ID of the PID (kind/id), leaving the
+// address out. This way a cluster actor that gets activated on another
+// member finds the state of its previous activation.
+func KeyByID(pid *actor.PID) string {
+	return pid.ID
+}
+
+// Config holds the configuration of the durable state middleware.
+type Config struct {
+	keyFunc      KeyFunc
+	saveEvery    int
+	saveInterval time.Duration
+}
+
+// NewConfig returns a Config that only saves the state when the actor
+// is stopped.
+func NewConfig() Config {
+	return Config{
+		keyFunc: KeyByID,
+	}
+}
+
+// WithKeyFunc set's the function that determines the storage key.
+//
+// Defaults to KeyByID.
+func (config Config) WithKeyFunc(fn KeyFunc) Config {
+	config.keyFunc = fn
+	return config
+}
+
+// WithSaveEvery will save the state after every n processed messages.
+//
+// Defaults to 0, which disables saving by message count.
+func (config Config) WithSaveEvery(n int) Config {
+	config.saveEvery = n
+	return config
+}
+
+// WithSaveInterval will save the state each interval if it has processed
+// any messages since the last save.
+//
+// Defaults to 0, which disables saving by interval.
+func (config Config) WithSaveInterval(d time.Duration) Config {
+	config.saveInterval = d
+	return config
+}
+
+// StateConflictEvent is broadcasted when the state of an actor could not be
+// saved because the stored revision changed since it was loaded. Most likely
+// another activation of the same actor is writing to the same key. The actor
+// reloads the stored state, the changes since its last save are lost.
+type StateConflictEvent struct {
+	PID      *actor.PID
+	Key      string
+	Revision uint64
+}
+
+func (e StateConflictEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelWarn, "State revision conflict", []any{"pid", e.PID, "key", e.Key, "revision", e.Revision}
+}
+
+// StateStoreErrorEvent is broadcasted when loading or saving the state of an
+// actor failed.
+type StateStoreErrorEvent struct {
+	PID *actor.PID
+	Key string
+	Op  string
+	Err error
+}
+
+func (e StateStoreErrorEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelError, "State store failed", []any{"pid", e.PID, "key", e.Key, "op", e.Op, "err", e.Err}
+}
+
+// flushState is sent to the actor itself when a save interval is configured.
+type flushState struct{}
+
+// durableState tracks the revision and pending changes of a single process.
+type durableState struct {
+	store    StateStore
+	config   Config
+	key      string
+	revision uint64
+	dirty    int
+	repeater *actor.SendRepeater
+}
+
+// WithDurableState returns a middleware that loads the state of receivers
+// implementing Stateful on actor.Initialized and saves it after the configured
+// number of messages, on the configured interval, and on actor.Stopped.
+//
+//	pid := e.Spawn(newPlayer, "player",
+//		actor.WithMiddleware(persistence.WithDurableState(store, persistence.NewConfig().WithSaveEvery(10))))
+func WithDurableState(store StateStore, config Config) actor.MiddlewareFunc {
+	if config.keyFunc == nil {
+		config.keyFunc = KeyByID
+	}
+	return func(next actor.ReceiveFunc) actor.ReceiveFunc {
+		s := &durableState{
+			store:  store,
+			config: config,
+		}
+		return func(c *actor.Context) {
+			stateful, ok := c.Receiver().(Stateful)
+			if !ok {
+				next(c)
+				return
+			}
+			switch c.Message().(type) {
+			case actor.Initialized:
+				s.load(c, stateful)
+				next(c)
+			case actor.Started:
+				if s.config.saveInterval > 0 && s.repeater == nil {
+					sr := c.SendRepeat(c.PID(), flushState{}, s.config.saveInterval)
+					s.repeater = &sr
+				}
+				next(c)
+			case flushState:
+				if s.dirty > 0 {
+					s.save(c, stateful)
+				}
+			case actor.Stopped:
+				next(c)
+				if s.repeater != nil {
+					s.repeater.Stop()
+					s.repeater = nil
+				}
+				if s.dirty > 0 {
+					s.save(c, stateful)
+				}
+			default:
+				next(c)
+				s.dirty++
+				if s.config.saveEvery > 0 && s.dirty >= s.config.saveEvery {
+					s.save(c, stateful)
+				}
+			}
+		}
+	}
+}
+
+func (s *durableState) load(c *actor.Context, stateful Stateful) {
+	s.key = s.config.keyFunc(c.PID())
+	data, rev, err := s.store.Load(s.key)
+	if errors.Is(err, ErrNotFound) {
+		s.revision = 0
+		return
+	}
+	if err != nil {
+		c.Engine().BroadcastEvent(StateStoreErrorEvent{PID: c.PID(), Key: s.key, Op: "load", Err: err})
+		return
+	}
+	if err := stateful.LoadState(data); err != nil {
+		c.Engine().BroadcastEvent(StateStoreErrorEvent{PID: c.PID(), Key: s.key, Op: "load", Err: err})
+		return
+	}
+	s.revision = rev
+}
+
+func (s *durableState) save(c *actor.Context, stateful Stateful) {
+	data, err := stateful.State()
+	if err != nil {
+		c.Engine().BroadcastEvent(StateStoreErrorEvent{PID: c.PID(), Key: s.key, Op: "save", Err: err})
+		return
+	}
+	rev, err := s.store.Save(s.key, data, s.revision)
+	if errors.Is(err, ErrRevisionConflict) {
+		c.Engine().BroadcastEvent(StateConflictEvent{PID: c.PID(), Key: s.key, Revision: rev})
+		// Continue from the stored state, otherwise every later save
+		// conflicts with the same revision.
+		s.load(c, stateful)
+		s.dirty = 0
+		return
+	}
+	if err != nil {
+		c.Engine().BroadcastEvent(StateStoreErrorEvent{PID: c.PID(), Key: s.key, Op: "save", Err: err})
+		return
+	}
+	s.revision = rev
+	s.dirty = 0
+}
@@ -0,0 +1,177 @@
+package persistence
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type counter struct {
+	count int
+}
+
+func newCounter() actor.Receiver {
+	return &counter{}
+}
+
+func (c *counter) Receive(ctx *actor.Context) {
+	switch ctx.Message().(type) {
+	case int:
+		c.count++
+	case string:
+		ctx.Respond(c.count)
+	}
+}
+
+func (c *counter) State() ([]byte, error) {
+	return []byte(strconv.Itoa(c.count)), nil
+}
+
+func (c *counter) LoadState(b []byte) error {
+	n, err := strconv.Atoi(string(b))
+	c.count = n
+	return err
+}
+
+func testStoreCompareAndSwap(t *testing.T, store StateStore) {
+	_, _, err := store.Load("foo")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	rev, err := store.Save("foo", []byte("a"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), rev)
+
+	rev, err = store.Save("foo", []byte("b"), 0)
+	assert.ErrorIs(t, err, ErrRevisionConflict)
+	assert.Equal(t, uint64(1), rev)
+
+	rev, err = store.Save("foo", []byte("c"), 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), rev)
+
+	data, rev, err := store.Load("foo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("c"), data)
+	assert.Equal(t, uint64(2), rev)
+
+	assert.ErrorIs(t, store.Delete("foo", 1), ErrRevisionConflict)
+	require.NoError(t, store.Delete("foo", 2))
+	_, _, err = store.Load("foo")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStoreCompareAndSwap(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	testStoreCompareAndSwap(t, store)
+}
+
+func TestDurableStateLoadAndSave(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	store := NewMemoryStore()
+	_, err = store.Save("counter/1", []byte("10"), 0)
+	require.NoError(t, err)
+
+	mw := WithDurableState(store, NewConfig().WithSaveEvery(2))
+	pid := e.Spawn(newCounter, "counter", actor.WithID("1"), actor.WithMiddleware(mw))
+	for i := 0; i < 4; i++ {
+		e.Send(pid, i)
+	}
+	resp, err := e.Request(pid, "count", time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, 14, resp)
+
+	// 4 messages with a batch of 2 => 2 saves.
+	data, rev, err := store.Load("counter/1")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), rev)
+	assert.Equal(t, []byte("14"), data)
+
+	<-e.Poison(pid).Done()
+	// the request is counted as a processed message and flushed on stop.
+	_, rev, err = store.Load("counter/1")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), rev)
+}
+
+func TestDurableStateConflict(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	store := NewMemoryStore()
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	eventPID := e.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(StateConflictEvent); ok {
+			assert.Equal(t, "counter/1", msg.Key)
+			wg.Done()
+		}
+	}, "event")
+	e.Subscribe(eventPID)
+
+	mw := WithDurableState(store, NewConfig())
+	pid := e.Spawn(newCounter, "counter", actor.WithID("1"), actor.WithMiddleware(mw))
+	e.Send(pid, 1)
+	// another writer saves the state while the actor is alive.
+	_, err = store.Save("counter/1", []byte("100"), 0)
+	require.NoError(t, err)
+	<-e.Poison(pid).Done()
+	wg.Wait()
+
+	data, _, err := store.Load("counter/1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("100"), data)
+}
+
+func TestDurableStateSaveAfterConflict(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	store := NewMemoryStore()
+	mw := WithDurableState(store, NewConfig().WithSaveEvery(1))
+	pid := e.Spawn(newCounter, "counter", actor.WithID("1"), actor.WithMiddleware(mw))
+	count := func() int {
+		resp, err := e.Request(pid, "count", time.Second).Result()
+		require.NoError(t, err)
+		return resp.(int)
+	}
+	e.Send(pid, 1)
+	assert.Equal(t, 1, count())
+
+	// another writer saves the state, the next save of the actor conflicts
+	// and it continues from the stored state.
+	_, rev, err := store.Load("counter/1")
+	require.NoError(t, err)
+	_, err = store.Save("counter/1", []byte("100"), rev)
+	require.NoError(t, err)
+	e.Send(pid, 1)
+	assert.Equal(t, 100, count())
+
+	e.Send(pid, 1)
+	assert.Equal(t, 101, count())
+	data, _, err := store.Load("counter/1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("101"), data)
+}
+
+func TestDurableStateSaveInterval(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	store := NewMemoryStore()
+	mw := WithDurableState(store, NewConfig().WithSaveInterval(time.Millisecond*10))
+	pid := e.Spawn(newCounter, "counter", actor.WithID("1"), actor.WithMiddleware(mw))
+	e.Send(pid, 1)
+	require.Eventually(t, func() bool {
+		data, _, err := store.Load("counter/1")
+		return err == nil && string(data) == "1"
+	}, time.Second, time.Millisecond*10)
+	<-e.Poison(pid).Done()
+}
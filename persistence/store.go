@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrNotFound is returned by a StateStore when there is no state stored
+	// under the given key.
+	ErrNotFound = errors.New("state not found")
+	// ErrRevisionConflict is returned by a StateStore when the revision of a
+	// write does not match the revision that is currently stored. This means
+	// another writer (for example a second activation of the same actor) has
+	// saved the state in the meantime.
+	ErrRevisionConflict = errors.New("state revision conflict")
+)
+
+// StateStore is a durable key/value store with versioned writes.
+//
+// Each key holds a revision that starts at 0 (not stored) and is incremented
+// by one on every successful Save. Save only succeeds if the expected revision
+// equals the revision that is currently stored, which makes it a
+// compare-and-swap on the revision.
+type StateStore interface {
+	// Load returns the state and its revision stored under key. If nothing
+	// is stored, ErrNotFound is returned.
+	Load(key string) ([]byte, uint64, error)
+	// Save stores data under key if the stored revision equals expected and
+	// returns the new revision. ErrRevisionConflict is returned otherwise.
+	Save(key string, data []byte, expected uint64) (uint64, error)
+	// Delete removes the state stored under key if the stored revision
+	// equals expected.
+	Delete(key string, expected uint64) error
+}
+
+type memoryRecord struct {
+	data     []byte
+	revision uint64
+}
+
+// MemoryStore is an in-memory StateStore. It is safe for concurrent use and
+// mostly useful for tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]memoryRecord
+}
+
+// NewMemoryStore returns a new empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]memoryRecord),
+	}
+}
+
+func (s *MemoryStore) Load(key string) ([]byte, uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[key]
+	if !ok {
+		return nil, 0, ErrNotFound
+	}
+	return append([]byte(nil), rec.data...), rec.revision, nil
+}
+
+func (s *MemoryStore) Save(key string, data []byte, expected uint64) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := s.records[key]
+	if rec.revision != expected {
+		return rec.revision, ErrRevisionConflict
+	}
+	rec = memoryRecord{
+		data:     append([]byte(nil), data...),
+		revision: expected + 1,
+	}
+	s.records[key] = rec
+	return rec.revision, nil
+}
+
+func (s *MemoryStore) Delete(key string, expected uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[key]
+	if !ok {
+		return ErrNotFound
+	}
+	if rec.revision != expected {
+		return ErrRevisionConflict
+	}
+	delete(s.records, key)
+	return nil
+}
@@ -143,10 +143,18 @@ func (r *Remote) Stop() *sync.WaitGroup {
 // message.
 // Sending will work even if the remote is stopped. Receiving however, will not work.
 func (r *Remote) Send(pid *actor.PID, msg any, sender *actor.PID) {
+	r.SendEnvelope(pid, actor.Envelope{Msg: msg, Sender: sender})
+}
+
+// SendEnvelope behaves like Send, but also carries the header and the
+// deadline of the given envelope to the receiving process.
+func (r *Remote) SendEnvelope(pid *actor.PID, env actor.Envelope) {
 	r.engine.Send(r.streamRouterPID, &streamDeliver{
-		target: pid,
-		sender: sender,
-		msg:    msg,
+		target:   pid,
+		sender:   env.Sender,
+		msg:      env.Msg,
+		header:   env.Header,
+		deadline: env.Deadline,
 	})
 }
 
@@ -246,6 +246,83 @@ func TestStreamWriterRemoteUnreachableEvent(t *testing.T) {
 	wg.Wait()
 }
 
+func TestSendHeader(t *testing.T) {
+	a, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	pidB := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			assert.Equal(t, "bar", c.Header("foo"))
+			wg.Done()
+		}
+	}, "b")
+	withHeader := func(next actor.SenderFunc) actor.SenderFunc {
+		return func(c *actor.Context, pid *actor.PID, env actor.Envelope) {
+			env.Header = actor.Header{"foo": "bar"}
+			next(c, pid, env)
+		}
+	}
+	a.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(actor.Started); ok {
+			c.Send(pidB, &TestMessage{Data: []byte("foo")})
+		}
+	}, "a", actor.WithSenderMiddleware(withHeader))
+	wg.Wait()
+}
+
+func TestSendWithHeaders(t *testing.T) {
+	a, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+
+	pid := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			assert.Equal(t, "abc", c.Header("correlation-id"))
+			assert.Equal(t, "eu", c.Header("tenant"))
+			wg.Done()
+		}
+	}, "b")
+	a.SendWithHeaders(pid, &TestMessage{Data: []byte("foo")}, actor.Header{
+		"correlation-id": "abc",
+		"tenant":         "eu",
+	})
+	wg.Wait()
+}
+
+func TestRequestDeadline(t *testing.T) {
+	a, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+
+	// The actor reports whether it got a deadline, the test checks it.
+	hasDeadline := make(chan bool, 1)
+	pid := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			deadline, ok := c.Deadline()
+			hasDeadline <- ok
+			c.Respond(&TestMessage{Data: []byte(deadline.Format(time.RFC3339Nano))})
+		}
+	}, "b")
+	before := time.Now()
+	resp, err := a.Request(pid, &TestMessage{Data: []byte("foo")}, time.Second).Result()
+	require.NoError(t, err)
+	require.True(t, <-hasDeadline)
+	msg, ok := resp.(*TestMessage)
+	require.True(t, ok)
+	deadline, err := time.Parse(time.RFC3339Nano, string(msg.Data))
+	require.NoError(t, err)
+	assert.True(t, deadline.After(before.Add(time.Millisecond*900)))
+	assert.True(t, deadline.Before(time.Now().Add(time.Second)))
+}
+
 func makeRemoteEngine(listenAddr string) (*actor.Engine, *Remote, error) {
 	var e *actor.Engine
 	r := New(listenAddr, NewConfig())
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/khulnasoft/goactors/actor"
 )
@@ -35,6 +36,9 @@ func (r *streamReader) Receive(stream DRPCRemote_ReceiveStream) error {
 			return err
 		}
 
+		if m := r.remote.engine.Metrics(); !isNoopMetrics(m) {
+			m.RemoteBatchReceived(len(envelope.Messages), envelope.SizeVT())
+		}
 		for _, msg := range envelope.Messages {
 			tname := envelope.TypeNames[msg.TypeNameIndex]
 			payload, err := r.deserializer.Deserialize(msg.Data, tname)
@@ -48,9 +52,25 @@ func (r *streamReader) Receive(stream DRPCRemote_ReceiveStream) error {
 			if len(envelope.Senders) > 0 {
 				sender = envelope.Senders[msg.SenderIndex]
 			}
-			r.remote.engine.SendLocal(target, payload, sender)
+			var deadline time.Time
+			if msg.Deadline != 0 {
+				deadline = time.Unix(0, msg.Deadline)
+			}
+			r.remote.engine.SendLocalEnvelope(target, actor.Envelope{
+				Msg:      payload,
+				Sender:   sender,
+				Header:   msg.Header,
+				Deadline: deadline,
+			})
 		}
 	}
 
 	return nil
 }
+
+// isNoopMetrics reports whether the engine runs without metrics, so we can
+// skip computing the size of the envelopes.
+func isNoopMetrics(m actor.Metrics) bool {
+	_, ok := m.(actor.NoopMetrics)
+	return ok
+}
@@ -3,14 +3,17 @@ package remote
 import (
 	"crypto/tls"
 	"log/slog"
+	"time"
 
 	"github.com/khulnasoft/goactors/actor"
 )
 
 type streamDeliver struct {
-	sender *actor.PID
-	target *actor.PID
-	msg    any
+	sender   *actor.PID
+	target   *actor.PID
+	msg      any
+	header   actor.Header
+	deadline time.Time
 }
 
 type streamRouter struct {
@@ -85,6 +85,10 @@ func (s *streamWriter) Invoke(msgs []actor.Envelope) {
 			TypeNameIndex: typeID,
 			SenderIndex:   senderID,
 			TargetIndex:   targetID,
+			Header:        stream.header,
+		}
+		if !stream.deadline.IsZero() {
+			messages[i].Deadline = stream.deadline.UnixNano()
 		}
 	}
 
@@ -103,6 +107,8 @@ func (s *streamWriter) Invoke(msgs []actor.Envelope) {
 		slog.Error("stream writer failed sending message",
 			"err", err,
 		)
+	} else if m := s.engine.Metrics(); !isNoopMetrics(m) {
+		m.RemoteBatchSent(s.writeToAddr, len(messages), env.SizeVT())
 	}
 	// refresh the connection deadline.
 	err := s.rawconn.SetDeadline(time.Now().Add(connIdleTimeout))
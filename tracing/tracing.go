@@ -0,0 +1,153 @@
+package tracing
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/khulnasoft/goactors/actor"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/khulnasoft/goactors/tracing"
+
+// Config holds the configuration of the tracing middleware.
+type Config struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+	traceLifecycle bool
+}
+
+// NewConfig returns a Config that uses the global TracerProvider and
+// propagates the trace context in the W3C Trace Context format.
+func NewConfig() Config {
+	return Config{
+		tracerProvider: otel.GetTracerProvider(),
+		propagator:     propagation.TraceContext{},
+	}
+}
+
+// WithTracerProvider set's the TracerProvider the spans are created with.
+//
+// Defaults to the global TracerProvider.
+func (config Config) WithTracerProvider(tp trace.TracerProvider) Config {
+	config.tracerProvider = tp
+	return config
+}
+
+// WithPropagator set's the propagator that injects and extracts the trace
+// context from the message headers.
+//
+// Defaults to propagation.TraceContext.
+func (config Config) WithPropagator(p propagation.TextMapPropagator) Config {
+	config.propagator = p
+	return config
+}
+
+// WithLifecycle will also create spans for the actor.Initialized,
+// actor.Started and actor.Stopped messages.
+//
+// Defaults to false.
+func (config Config) WithLifecycle(b bool) Config {
+	config.traceLifecycle = b
+	return config
+}
+
+// WithTracing returns an option that installs both the receive and the sender
+// middleware on the spawned actor.
+//
+//	e.Spawn(newPlayer, "player", tracing.WithTracing(tracing.NewConfig()))
+func WithTracing(config Config) actor.OptFunc {
+	return func(opts *actor.Opts) {
+		opts.Middleware = append(opts.Middleware, ReceiveMiddleware(config))
+		opts.SenderMiddleware = append(opts.SenderMiddleware, SenderMiddleware(config))
+	}
+}
+
+// ReceiveMiddleware starts a span for each received message. The trace
+// context of the sender is extracted from the message header and becomes the
+// parent of the span. The span is available to the receiver and to the sender
+// middleware through actor.Context.Context().
+func ReceiveMiddleware(config Config) actor.MiddlewareFunc {
+	tracer := config.tracerProvider.Tracer(instrumentationName)
+	return func(next actor.ReceiveFunc) actor.ReceiveFunc {
+		return func(c *actor.Context) {
+			if !config.traceLifecycle && isLifecycle(c.Message()) {
+				next(c)
+				return
+			}
+			msgType := typeName(c.Message())
+			parent := config.propagator.Extract(c.Context(), headerCarrier(c, config.propagator))
+			attrs := []attribute.KeyValue{
+				attribute.String("actor.pid", c.PID().String()),
+				attribute.String("actor.message.type", msgType),
+			}
+			if c.Sender() != nil {
+				attrs = append(attrs, attribute.String("actor.sender", c.Sender().String()))
+			}
+			ctx, span := tracer.Start(parent, "receive "+msgType,
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(attrs...))
+			prev := c.Context()
+			c.SetContext(ctx)
+			defer func() {
+				c.SetContext(prev)
+				if v := recover(); v != nil {
+					span.RecordError(fmt.Errorf("%v", v))
+					span.End()
+					panic(v)
+				}
+				span.End()
+			}()
+			next(c)
+		}
+	}
+}
+
+// SenderMiddleware injects the trace context of the message that is currently
+// being received into the header of each outgoing message, so the receiving
+// actor, local or remote, continues the trace.
+func SenderMiddleware(config Config) actor.SenderMiddlewareFunc {
+	return func(next actor.SenderFunc) actor.SenderFunc {
+		return func(c *actor.Context, pid *actor.PID, env actor.Envelope) {
+			ctx := c.Context()
+			if trace.SpanContextFromContext(ctx).IsValid() {
+				// copy the header, it could be shared with the message we forward.
+				header := env.Header.Clone()
+				config.propagator.Inject(ctx, propagation.MapCarrier(header))
+				env.Header = header
+			}
+			next(c, pid, env)
+		}
+	}
+}
+
+// headerCarrier returns a carrier with the fields of the propagator that are
+// set in the header of the message that is currently being received.
+func headerCarrier(c *actor.Context, propagator propagation.TextMapPropagator) propagation.MapCarrier {
+	carrier := propagation.MapCarrier{}
+	for _, key := range propagator.Fields() {
+		if value := c.Header(key); len(value) > 0 {
+			carrier.Set(key, value)
+		}
+	}
+	return carrier
+}
+
+func isLifecycle(msg any) bool {
+	switch msg.(type) {
+	case actor.Initialized, actor.Started, actor.Stopped:
+		return true
+	}
+	return false
+}
+
+func typeName(msg any) string {
+	t := reflect.TypeOf(msg)
+	if t == nil {
+		return "nil"
+	}
+	return t.String()
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/khulnasoft/goactors/actor"
+	"github.com/khulnasoft/goactors/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newCollector returns a TracerProvider that exports into an in memory
+// exporter, which stands in for an OTLP collector.
+func newCollector() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return tp, exporter
+}
+
+func TestTracePropagatesLocal(t *testing.T) {
+	tp, exporter := newCollector()
+	config := NewConfig().WithTracerProvider(tp)
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+
+	pong := e.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(string); ok {
+			c.Respond("pong")
+		}
+	}, "pong", WithTracing(config))
+	// The result of the inner request is checked on the test goroutine.
+	errs := make(chan error, 1)
+	ping := e.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(string); ok {
+			resp, err := c.Request(pong, "ping", time.Second).Result()
+			errs <- err
+			c.Respond(resp)
+		}
+	}, "ping", WithTracing(config))
+
+	resp, err := e.Request(ping, "start", time.Second).Result()
+	require.NoError(t, err)
+	require.NoError(t, <-errs)
+	assert.Equal(t, "pong", resp)
+
+	require.NoError(t, tp.ForceFlush(context.Background()))
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	pingSpan, pongSpan := spanOf(spans, ping), spanOf(spans, pong)
+	assert.Equal(t, pingSpan.SpanContext.TraceID(), pongSpan.SpanContext.TraceID())
+	assert.Equal(t, pingSpan.SpanContext.SpanID(), pongSpan.Parent.SpanID())
+	assert.False(t, pingSpan.Parent.IsValid())
+}
+
+func TestTracePropagatesRemote(t *testing.T) {
+	tp, exporter := newCollector()
+	config := NewConfig().WithTracerProvider(tp)
+	a := makeRemoteEngine(t)
+	b := makeRemoteEngine(t)
+
+	done := make(chan struct{})
+	pidB := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*remote.TestMessage); ok {
+			close(done)
+		}
+	}, "b", WithTracing(config))
+	pidA := a.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*remote.TestMessage); ok {
+			c.Send(pidB, msg)
+		}
+	}, "a", WithTracing(config))
+
+	a.Send(pidA, &remote.TestMessage{Data: []byte("foo")})
+	<-done
+	require.Eventually(t, func() bool {
+		return len(exporter.GetSpans()) == 2
+	}, time.Second, time.Millisecond*10)
+
+	spans := exporter.GetSpans()
+	spanA, spanB := spanOf(spans, pidA), spanOf(spans, pidB)
+	assert.Equal(t, spanA.SpanContext.TraceID(), spanB.SpanContext.TraceID())
+	assert.Equal(t, spanA.SpanContext.SpanID(), spanB.Parent.SpanID())
+	assert.True(t, spanB.Parent.IsRemote())
+}
+
+func spanOf(spans tracetest.SpanStubs, pid *actor.PID) tracetest.SpanStub {
+	for _, span := range spans {
+		for _, attr := range span.Attributes {
+			if attr.Key == "actor.pid" && attr.Value.AsString() == pid.String() {
+				return span
+			}
+		}
+	}
+	return tracetest.SpanStub{}
+}
+
+func makeRemoteEngine(t *testing.T) *actor.Engine {
+	addr := fmt.Sprintf("127.0.0.1:%d", rand.Intn(50000)+10000)
+	r := remote.New(addr, remote.NewConfig())
+	e, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(r))
+	require.NoError(t, err)
+	return e
+}